@@ -1,298 +1,132 @@
+// Command benchmark - генератор нагрузки на сервис сокращения ссылок.
+// Нагрузка задается адресом сервиса, транспортом, количеством параллельных пользователей, временем
+// или количеством запросов и соотношением операций. Результат выводится таблицей с перцентилями задержек,
+// гистограммой и распределением ошибок, сохраняется в JSON и может сравниваться с отчетом предыдущего запуска
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
-	"net/http/cookiejar"
-	"net/url"
-	"path"
-	"time"
+	"os"
+	"os/signal"
 
-	"github.com/denmor86/go-url-shortener/internal/usecase"
-	"github.com/denmor86/go-url-shortener/pkg/random"
-)
-
-// GenerateURLItems создает срез RequestItem указанного размера
-func GenerateURLItems(count int) []usecase.RequestItem {
-	if count < 1 {
-		return []usecase.RequestItem{}
-	}
+	"github.com/spf13/pflag"
 
-	items := make([]usecase.RequestItem, 0, count)
-	for i := 1; i <= count; i++ {
-		items = append(items, usecase.RequestItem{
-			ID:  fmt.Sprintf("%d", i),
-			URL: random.URL().String(),
-		})
-	}
-	return items
-}
+	"github.com/denmor86/go-url-shortener/internal/loadgen"
+	"github.com/denmor86/go-url-shortener/pkg/client"
+)
 
+// Адреса сервиса по-умолчанию
 const (
-	address   = "localhost"
-	port      = 8080
-	timeout   = 10 * time.Second
-	batchSize = 10000
-	runCount  = 10
+	DefaultHTTPTarget = "http://localhost:8080"
+	DefaultGRPCTarget = "localhost:8081"
 )
 
-func main() {
-	for i := 1; i <= runCount; i++ {
-
-		// создаем клиента
-		client := makeClient()
-
-		// базовый адрес
-		baseURL := makeURL(address, port)
-
-		// проверяем коннект
-		checkPing(client, baseURL)
-
-		// проверяем создание сокращенных URL по полному
-		checkPostURL(client, baseURL)
-
-		// генерируем массив URL и отправляем его для создания
-		urls := checkSendBatch(client, baseURL)
-
-		// проверяем запросы ранее созданных записей
-		deleteItems := checkGetURL(client, urls)
-
-		// проверяем запрос на получение всех записей пользователя
-		checkGetURLS(client, baseURL)
-
-		// проверяем запрос на удаление записей
-		checkDeleteURLS(client, baseURL, deleteItems)
-
-		// ожидание
-		fmt.Printf("waiting... \n")
-		time.Sleep(time.Second)
-	}
-}
-
-// makeClient - формирование http клиента с пустым cookie
-func makeClient() *http.Client {
-	// создаем пустую cookie
-	jar, err := cookiejar.New(nil)
-	if err != nil {
-		log.Fatalf("Error creating cookie jar: %v", err)
-	}
-	return &http.Client{
-		Timeout: timeout,
-		Jar:     jar,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			// Возвращаем эту ошибку, чтобы остановить редирект
-			return http.ErrUseLastResponse
-		},
-	}
-}
-
-// makeURL - формирование базового адреса сервиса
-func makeURL(address string, port uint16) string {
-	return fmt.Sprintf("http://%s:%d", address, port)
-}
-
-// checkPing - метод для проверки GET метода ping
-func checkPing(client *http.Client, baseURL string) {
-	_, err := sendPing(client, baseURL)
-	if err != nil {
-		log.Fatalf("Error ping: %v", err)
-	}
-}
-
-// checkPostURL - метод для проверки POST метода /
-func checkPostURL(client *http.Client, baseURL string) {
-	for i := 1; i <= batchSize; i++ {
-		URL, sendError := sendPostURL(client, baseURL, random.URL().String())
-		if sendError != nil {
-			log.Fatalf("Send post URL failed: %v\n", sendError)
-		}
-		fmt.Printf("Base URL: %s\n", URL)
-	}
+// options - параметры запуска
+type options struct {
+	cfg      loadgen.Config
+	mix      string // соотношение операций
+	insecure bool   // признак отключения проверки сертификата сервиса
+	report   string // файл для сохранения отчета в JSON
+	baseline string // файл отчета предыдущего запуска для сравнения
+	quiet    bool   // признак отключения вывода хода нагрузки
 }
 
-// checkSendBatch - метод проверки метода api/shorten/batch (возвращает массис созданных записей)
-func checkSendBatch(client *http.Client, baseURL string) []string {
-	batch := GenerateURLItems(batchSize)
-	batchResponse, sendError := sendBatch(client, baseURL, batch)
-	if sendError != nil {
-		log.Fatalf("Send batch failed: %v", sendError)
-	}
-	// разбираем ответ
-	urls, err := parseBatchResponse(batchResponse)
-	if err != nil {
-		log.Fatalf("parse batch responce failed: %v", err)
-	}
-	return urls
-}
-
-// checkGetURL - метод проверки GET запроса коротких URL (возвращает массив записей для удаления)
-func checkGetURL(client *http.Client, urls []string) []string {
-	deleteItems := make([]string, 0, len(urls))
-	for _, url := range urls {
-		// подготовка записей для удаления
-		deleteItems = append(deleteItems, getShortSegment(url))
-
-		// запрашиваем URL по каждому сокращенному
-		URL, err := sendGetURL(client, url)
-		if err != nil {
-			log.Fatalf("Send get URL failed: %v\n", err)
+// функция main вызывается автоматически при запуске приложения
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("benchmark: ")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run - метод разбора параметров, выполнения нагрузки и вывода отчета
+func run(ctx context.Context, args []string) error {
+	var opts options
+	fs := newFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return nil
 		}
-		fmt.Printf("Short URL: %s\n", URL)
+		return err
 	}
-	return deleteItems
-}
-
-// checkGetURLS - метод проверки GET запроса всех URL пользователя (по токену)
-func checkGetURLS(client *http.Client, baseURL string) {
-	resp, err := sendGetURLS(client, baseURL)
-	if err != nil {
-		log.Fatalf("Send get URLs failed: %v", err)
-	}
-	fmt.Printf("URLs: %s\n", resp)
-}
-
-// checkDeleteURLS - метод проверки запроса удаления записей пользователя (по токену)
-func checkDeleteURLS(client *http.Client, baseURL string, deleteItems []string) {
-	_, err := sendBachDelete(client, baseURL, deleteItems)
-	if err != nil {
-		log.Fatalf("Send batch delete failed: %v", err)
+	if !fs.Changed("target") && opts.cfg.Transport == client.TransportGRPC {
+		opts.cfg.Target = DefaultGRPCTarget
 	}
-}
-
-// sendPing отправляет PING запрос
-func sendPing(client *http.Client, baseURL string) (string, error) {
-	url := fmt.Sprintf("%s/ping", baseURL)
-	resp, err := client.Get(url)
+	mix, err := loadgen.ParseMix(opts.mix)
 	if err != nil {
-		return "", fmt.Errorf("GET request to %s error: %v", url, err)
+		return err
 	}
-	defer resp.Body.Close()
-
-	return handleResponse(resp)
-}
-
-// sendPostURL отправляет Post запрос c коротким адресом
-func sendPostURL(client *http.Client, baseURL string, URL string) (string, error) {
-	resp, err := client.Post(baseURL, "text/plain", bytes.NewBufferString(URL))
-	if err != nil {
-		return "", fmt.Errorf("GET request to %s error: %v", URL, err)
+	opts.cfg.Mix = mix
+	if opts.insecure {
+		opts.cfg.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: true}
 	}
-	defer resp.Body.Close()
-
-	return handleResponse(resp)
-}
-
-// sendBach отправляет POST запрос с JSON (Batch) телом
-func sendBatch(client *http.Client, baseURL string, data []usecase.RequestItem) (string, error) {
-	url := fmt.Sprintf("%s/api/shorten/batch", baseURL)
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return "", fmt.Errorf("error marshaling JSON: %v", err)
+	if !opts.quiet {
+		opts.cfg.Progress = os.Stderr
 	}
-
-	resp, err := client.Post(url, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("POST request to %s error: %v", url, err)
+	// отчет предыдущего запуска читается заранее, чтобы не потерять результаты нагрузки из-за ошибки в файле
+	var baseline *loadgen.Report
+	if len(opts.baseline) != 0 {
+		if baseline, err = loadgen.LoadReport(opts.baseline); err != nil {
+			return err
+		}
 	}
-	defer resp.Body.Close()
-
-	return handleResponse(resp)
-}
 
-// sendBachDelete отправляет DELETE запрос с JSON (Batch) телом
-func sendBachDelete(client *http.Client, baseURL string, data []string) (string, error) {
-
-	url := fmt.Sprintf("%s/api/user/urls", baseURL)
-	jsonData, err := json.Marshal(data)
+	report, err := loadgen.Run(ctx, opts.cfg)
 	if err != nil {
-		return "", fmt.Errorf("error marshaling JSON: %v", err)
+		return err
 	}
-
-	req, err := http.NewRequest("DELETE", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("error creating DELETE request: %v", err)
+	if err = report.WriteText(os.Stdout); err != nil {
+		return err
 	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("DELETE urls to %s error: %v", url, err)
+	if len(opts.report) != 0 {
+		if err = saveReport(opts.report, report); err != nil {
+			return err
+		}
 	}
-	defer resp.Body.Close()
-
-	return handleResponse(resp)
-}
-
-// sendGetURL отправляет GET запрос c коротким адресом
-func sendGetURL(client *http.Client, URL string) (string, error) {
-	resp, err := client.Get(URL)
-	if err != nil {
-		return "", fmt.Errorf("GET request to %s error: %v", URL, err)
+	if baseline != nil {
+		fmt.Printf("\nComparison with %s:\n", opts.baseline)
+		if baseline.Transport != report.Transport || baseline.Mix != report.Mix || baseline.Concurrency != report.Concurrency {
+			fmt.Printf("warning: baseline was run with %s, mix %s, concurrency %d\n", baseline.Transport, baseline.Mix, baseline.Concurrency)
+		}
+		return loadgen.WriteComparison(os.Stdout, report.Compare(baseline))
 	}
-	defer resp.Body.Close()
-
-	return handleResponse(resp)
+	return nil
 }
 
-// sendGetURLS отправляет GET запроc c получением всех записей пользователя
-func sendGetURLS(client *http.Client, baseURL string) (string, error) {
-	url := fmt.Sprintf("%s/api/user/urls", baseURL)
-
-	resp, err := client.Get(url)
-	if err != nil {
-		return "", fmt.Errorf("GET request to %s error: %v", url, err)
-	}
-	defer resp.Body.Close()
-
-	return handleResponse(resp)
+// newFlagSet - метод формирования параметров запуска
+func newFlagSet(opts *options) *pflag.FlagSet {
+	fs := pflag.NewFlagSet("benchmark", pflag.ContinueOnError)
+	fs.StringVarP(&opts.cfg.Transport, "transport", "t", client.TransportHTTP, "Transport: http or grpc")
+	fs.StringVarP(&opts.cfg.Target, "target", "s", DefaultHTTPTarget, "Service address: base URL for http, host:port for grpc (default "+DefaultGRPCTarget+" for grpc)")
+	fs.IntVarP(&opts.cfg.Concurrency, "concurrency", "c", loadgen.DefaultConcurrency, "Number of concurrent workers, each acts as a separate user")
+	fs.DurationVarP(&opts.cfg.Duration, "duration", "d", loadgen.DefaultDuration, "Load duration (ignored if --requests is set)")
+	fs.IntVarP(&opts.cfg.Requests, "requests", "n", 0, "Total number of requests, 0 - limited by --duration")
+	fs.StringVarP(&opts.mix, "mix", "m", loadgen.DefaultMix, "Workload mix as operation=weight (shorten, batch, redirect, list, delete)")
+	fs.IntVar(&opts.cfg.BatchSize, "batch-size", loadgen.DefaultBatchSize, "Number of URLs in batch request")
+	fs.DurationVar(&opts.cfg.Timeout, "timeout", loadgen.DefaultTimeout, "Single request timeout")
+	fs.BoolVar(&opts.insecure, "insecure", false, "Skip server certificate verification")
+	fs.StringVarP(&opts.report, "report", "o", "", "Save JSON report to file")
+	fs.StringVar(&opts.baseline, "baseline", "", "Compare results with JSON report of a previous run")
+	fs.BoolVarP(&opts.quiet, "quiet", "q", false, "Don't print progress every second")
+	return fs
 }
 
-// handleResponse обрабатывает HTTP ответ
-func handleResponse(resp *http.Response) (string, error) {
-	body, err := io.ReadAll(resp.Body)
+// saveReport - метод сохранения отчета в файл в формате JSON
+func saveReport(path string, report *loadgen.Report) error {
+	file, err := os.Create(path)
 	if err != nil {
-		return "", fmt.Errorf("error reading response body: %v", err)
-	}
-
-	switch resp.StatusCode {
-	case http.StatusCreated:
-	case http.StatusOK:
-	case http.StatusTemporaryRedirect:
-	case http.StatusAccepted:
-	default:
-		return "", fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, body)
+		return fmt.Errorf("can't save report: %w", err)
 	}
-
-	return string(body), nil
-}
-
-// parseBatchResponse обрабатывает batch
-func parseBatchResponse(resp string) ([]string, error) {
-
-	var respItems []usecase.ResponseItem
-	if err := json.Unmarshal([]byte(resp), &respItems); err != nil {
-		return nil, fmt.Errorf("error unmarshal body: %w", err)
-	}
-
-	if len(respItems) == 0 {
-		return nil, fmt.Errorf("empty batch response")
-	}
-
-	items := make([]string, 0, len(respItems))
-	for _, item := range respItems {
-		items = append(items, item.URL)
-	}
-
-	return items, nil
-}
-
-func getShortSegment(rawURL string) string {
-	u, err := url.Parse(rawURL)
-	if err != nil {
-		return ""
+	if err = report.WriteJSON(file); err != nil {
+		file.Close()
+		return fmt.Errorf("can't save report: %w", err)
 	}
-	return path.Base(u.Path)
+	return file.Close()
 }
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/denmor86/go-url-shortener/internal/app"
+	"github.com/denmor86/go-url-shortener/internal/config"
+	"github.com/denmor86/go-url-shortener/internal/loadgen"
+	"github.com/denmor86/go-url-shortener/internal/profiling"
+	"github.com/denmor86/go-url-shortener/internal/storage"
+	"github.com/denmor86/go-url-shortener/pkg/client"
+	"github.com/denmor86/go-url-shortener/pkg/random"
+)
+
+// Хранилища сервиса
+const (
+	backendMemory   = "memory"
+	backendFile     = "file"
+	backendPostgres = "postgres"
+)
+
+// Внутренние константы снятия профилей
+const (
+	// startTimeout - время ожидания запуска сервиса
+	startTimeout = 10 * time.Second
+	// writeTimeoutReserve - запас времени записи HTTP ответа сверх длительности снятия профиля
+	writeTimeoutReserve = 10 * time.Second
+)
+
+// service - запущенный внутри программы сервис
+type service struct {
+	httpURL  string             // базовый URL HTTP сервера
+	grpcAddr string             // адрес GRPC сервера
+	cancel   context.CancelFunc // остановка сервиса
+	done     chan struct{}      // признак остановки сервиса
+	cleanup  func()             // удаление временных данных хранилища
+}
+
+// capture - метод запуска сервиса, нагрузки и снятия профилей CPU, heap и allocs
+func capture(ctx context.Context, opts *options) error {
+	seconds := int((opts.load.Duration + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return fmt.Errorf("duration must be at least 1s")
+	}
+	opts.load.Duration = time.Duration(seconds) * time.Second
+	if err := os.MkdirAll(opts.dir, 0o755); err != nil {
+		return fmt.Errorf("can't create profiles directory: %w", err)
+	}
+
+	svc, err := startService(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer svc.stop()
+
+	opts.load.Target = svc.httpURL
+	if opts.load.Transport == client.TransportGRPC {
+		opts.load.Target = svc.grpcAddr
+	}
+	opts.load.Progress = os.Stderr
+	fmt.Fprintf(os.Stderr, "Profiling %s backend over %s for %s...\n", opts.backend, opts.load.Transport, opts.load.Duration)
+
+	// профили CPU и allocs снимаются одновременно с нагрузкой, allocs - как разница за время нагрузки
+	var (
+		wg         sync.WaitGroup
+		cpuErr     error
+		allocsErr  error
+		report     *loadgen.Report
+		loadErr    error
+		pprofQuery = "?seconds=" + strconv.Itoa(seconds)
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		cpuErr = fetchProfile(ctx, svc.httpURL+"/debug/pprof/profile"+pprofQuery, profilePath(opts.dir, opts.name, profiling.KindCPU))
+	}()
+	go func() {
+		defer wg.Done()
+		allocsErr = fetchProfile(ctx, svc.httpURL+"/debug/pprof/allocs"+pprofQuery, profilePath(opts.dir, opts.name, profiling.KindAllocs))
+	}()
+	report, loadErr = loadgen.Run(ctx, opts.load)
+	wg.Wait()
+	if err = errors.Join(loadErr, cpuErr, allocsErr); err != nil {
+		return err
+	}
+	// профиль heap снимается после нагрузки и сборки мусора: в нем остаются только данные, удерживаемые сервисом
+	if err = fetchProfile(ctx, svc.httpURL+"/debug/pprof/heap?gc=1", profilePath(opts.dir, opts.name, profiling.KindHeap)); err != nil {
+		return err
+	}
+
+	if err = writeReport(loadReportPath(opts.dir, opts.name), report); err != nil {
+		return err
+	}
+	fmt.Println()
+	if err = report.WriteText(os.Stdout); err != nil {
+		return err
+	}
+	fmt.Printf("\nProfiles saved to %s:", opts.dir)
+	for _, kind := range profileKinds {
+		fmt.Printf(" %s", filepath.Base(profilePath(opts.dir, opts.name, kind)))
+	}
+	fmt.Println()
+	return nil
+}
+
+// startService - метод запуска сервиса с выбранным хранилищем на свободных портах и ожидания его готовности
+func startService(ctx context.Context, opts *options) (*service, error) {
+	httpPort, err := random.UnusedPort()
+	if err != nil {
+		return nil, fmt.Errorf("can't find free port: %w", err)
+	}
+	grpcPort, err := random.UnusedPort()
+	if err != nil {
+		return nil, fmt.Errorf("can't find free port: %w", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.ListenAddr = "127.0.0.1:" + strconv.Itoa(httpPort)
+	cfg.GRPCAddr = "127.0.0.1:" + strconv.Itoa(grpcPort)
+	cfg.BaseURL = "http://" + cfg.ListenAddr
+	cfg.LogLevel = "error"
+	cfg.DebugEnable = true
+	// ограничения частоты запросов и квоты исказили бы профиль ответами 429
+	cfg.RateLimits = ""
+	cfg.QuotaDaily = 0
+	cfg.QuotaTotal = 0
+	cfg.WriteTimeout = opts.load.Duration + writeTimeoutReserve
+	cfg.FileStoragePath = ""
+	cfg.DatabaseDSN = ""
+
+	svc := &service{
+		httpURL:  cfg.BaseURL,
+		grpcAddr: cfg.GRPCAddr,
+		done:     make(chan struct{}),
+		cleanup:  func() {},
+	}
+	base, err := newStorage(opts, cfg, svc)
+	if err != nil {
+		return nil, err
+	}
+	if err = app.ValidateConfig(cfg); err != nil {
+		base.Close()
+		svc.cleanup()
+		return nil, err
+	}
+	store := storage.NewTracingStorage(storage.NewMetricsStorage(base))
+
+	var runCtx context.Context
+	runCtx, svc.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(svc.done)
+		defer store.Close()
+		a := app.App{
+			Config:  cfg,
+			Storage: store,
+		}
+		a.RunContext(runCtx)
+	}()
+
+	if err = svc.wait(ctx); err != nil {
+		svc.stop()
+		return nil, err
+	}
+	return svc, nil
+}
+
+// newStorage - метод создания хранилища сервиса. Для файлового хранилища используется временный файл
+func newStorage(opts *options, cfg *config.Config, svc *service) (storage.IStorage, error) {
+	switch opts.backend {
+	case backendMemory:
+		return storage.NewMemStorage(), nil
+	case backendFile:
+		dir, err := os.MkdirTemp("", "profiler")
+		if err != nil {
+			return nil, fmt.Errorf("can't create storage directory: %w", err)
+		}
+		svc.cleanup = func() { os.RemoveAll(dir) }
+		cfg.FileStoragePath = filepath.Join(dir, config.DefaultCacheFileName)
+		s := storage.NewFileStorage()
+		if err = s.Initialize(cfg.FileStoragePath); err != nil {
+			svc.cleanup()
+			return nil, fmt.Errorf("can't initialize file storage: %w", err)
+		}
+		return s, nil
+	case backendPostgres:
+		if len(opts.dsn) == 0 {
+			return nil, fmt.Errorf("--dsn is required for postgres backend")
+		}
+		cfg.DatabaseDSN = opts.dsn
+		s, err := storage.NewDatabaseStorage(cfg.DatabaseDSN)
+		if err != nil {
+			return nil, fmt.Errorf("can't create database storage: %w", err)
+		}
+		if err = s.Initialize(); err != nil {
+			s.Close()
+			return nil, fmt.Errorf("can't initialize database storage: %w", err)
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unknown backend %q (memory, file, postgres)", opts.backend)
+	}
+}
+
+// wait - метод ожидания готовности сервиса по запросу GET /ping
+func (s *service) wait(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, startTimeout)
+	defer cancel()
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.httpURL+"/ping", nil)
+		if err != nil {
+			return err
+		}
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		select {
+		case <-s.done:
+			return fmt.Errorf("service stopped before it was ready")
+		case <-ctx.Done():
+			return fmt.Errorf("service is not ready after %s: %w", startTimeout, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// stop - метод остановки сервиса и удаления временных данных
+func (s *service) stop() {
+	s.cancel()
+	<-s.done
+	s.cleanup()
+}
+
+// fetchProfile - метод получения профиля по адресу url и сохранения в файл path
+func fetchProfile(ctx context.Context, url, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("can't get profile: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("can't get profile %s: %s %s", url, resp.Status, body)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("can't save profile: %w", err)
+	}
+	if _, err = io.Copy(file, resp.Body); err != nil {
+		file.Close()
+		return fmt.Errorf("can't save profile: %w", err)
+	}
+	return file.Close()
+}
+
+// writeReport - метод сохранения отчета о нагрузке, по нему профили приводятся к одному запросу при сравнении
+func writeReport(path string, report *loadgen.Report) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("can't save load report: %w", err)
+	}
+	if err = report.WriteJSON(file); err != nil {
+		file.Close()
+		return fmt.Errorf("can't save load report: %w", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,193 @@
+// Command profiler - снятие профилей сервиса под нагрузкой и сравнение с профилями предыдущего запуска.
+// Сервис запускается внутри программы с выбранным хранилищем, нагружается генератором нагрузки (cmd/benchmark),
+// профили CPU, heap и allocs снимаются через /debug/pprof и сохраняются в каталог профилей.
+// Если задан предыдущий запуск, выводится сравнение затрат функций, и программа завершается с ошибкой
+// при превышении допустимого ухудшения.
+// Генератор нагрузки работает в том же процессе, поэтому его затраты входят в профили: сравнивать имеет смысл
+// запуски с одинаковыми параметрами нагрузки
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/denmor86/go-url-shortener/internal/loadgen"
+	"github.com/denmor86/go-url-shortener/internal/profiling"
+	"github.com/denmor86/go-url-shortener/pkg/client"
+)
+
+// Параметры по-умолчанию
+const (
+	DefaultDir      = "profiles"
+	DefaultName     = "result"
+	DefaultDuration = 30 * time.Second
+	DefaultTop      = 15
+)
+
+// profileKinds - виды снимаемых профилей
+var profileKinds = []string{profiling.KindCPU, profiling.KindHeap, profiling.KindAllocs}
+
+// options - параметры запуска
+type options struct {
+	backend     string               // хранилище сервиса: memory, file или postgres
+	dsn         string               // строка подключения к PostgreSQL
+	load        loadgen.Config       // параметры нагрузки
+	mix         string               // соотношение операций
+	dir         string               // каталог профилей
+	name        string               // имя набора профилей текущего запуска
+	baseline    string               // имя набора профилей предыдущего запуска
+	top         int                  // количество выводимых функций
+	thresholds  profiling.Thresholds // допустимое ухудшение
+	compareOnly bool                 // признак сравнения сохраненных профилей без запуска сервиса
+}
+
+// функция main вызывается автоматически при запуске приложения
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("profiler: ")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run - метод разбора параметров, снятия профилей и сравнения с предыдущим запуском
+func run(ctx context.Context, args []string) error {
+	var opts options
+	fs := newFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if len(opts.baseline) != 0 && opts.baseline == opts.name {
+		return fmt.Errorf("baseline and current profiles have the same name %q", opts.name)
+	}
+	if opts.compareOnly {
+		if len(opts.baseline) == 0 {
+			return fmt.Errorf("--baseline is required with --compare-only")
+		}
+	} else {
+		mix, err := loadgen.ParseMix(opts.mix)
+		if err != nil {
+			return err
+		}
+		opts.load.Mix = mix
+		if err := capture(ctx, &opts); err != nil {
+			return err
+		}
+	}
+	if len(opts.baseline) == 0 {
+		return nil
+	}
+	return compare(&opts)
+}
+
+// newFlagSet - метод формирования параметров запуска
+func newFlagSet(opts *options) *pflag.FlagSet {
+	fs := pflag.NewFlagSet("profiler", pflag.ContinueOnError)
+	fs.StringVarP(&opts.backend, "backend", "b", backendMemory, "Service storage: memory, file or postgres")
+	fs.StringVar(&opts.dsn, "dsn", "", "PostgreSQL connection string for postgres backend")
+	fs.StringVarP(&opts.load.Transport, "transport", "t", client.TransportHTTP, "Load transport: http or grpc")
+	fs.IntVarP(&opts.load.Concurrency, "concurrency", "c", loadgen.DefaultConcurrency, "Number of concurrent load workers")
+	fs.DurationVarP(&opts.load.Duration, "duration", "d", DefaultDuration, "Load and profiling duration (whole seconds)")
+	fs.StringVarP(&opts.mix, "mix", "m", loadgen.DefaultMix, "Workload mix as operation=weight (shorten, batch, redirect, list, delete)")
+	fs.IntVar(&opts.load.BatchSize, "batch-size", loadgen.DefaultBatchSize, "Number of URLs in batch request")
+	fs.StringVar(&opts.dir, "dir", DefaultDir, "Profiles directory")
+	fs.StringVarP(&opts.name, "name", "n", DefaultName, "Name of the captured profile set: <name>.pprof (heap), <name>.cpu.pprof, <name>.allocs.pprof, <name>.load.json")
+	fs.StringVar(&opts.baseline, "baseline", "", "Name of the baseline profile set in profiles directory to compare with")
+	fs.IntVar(&opts.top, "top", DefaultTop, "Number of top functions to print")
+	fs.Float64Var(&opts.thresholds.MaxTotalIncrease, "max-total-increase", 10, "Fail if total cost per request grows by more than this percent, 0 - disabled")
+	fs.Float64Var(&opts.thresholds.MaxFunctionIncrease, "max-function-increase", 5, "Fail if a function share grows by more than this many percentage points, 0 - disabled")
+	fs.BoolVar(&opts.compareOnly, "compare-only", false, "Compare saved profiles without starting the service")
+	return fs
+}
+
+// profilePath - метод получения пути к профилю вида kind набора name. Профиль heap хранится в <name>.pprof
+func profilePath(dir, name, kind string) string {
+	if kind == profiling.KindHeap {
+		return filepath.Join(dir, name+".pprof")
+	}
+	return filepath.Join(dir, name+"."+kind+".pprof")
+}
+
+// loadReportPath - метод получения пути к отчету о нагрузке набора name
+func loadReportPath(dir, name string) string {
+	return filepath.Join(dir, name+".load.json")
+}
+
+// compare - метод сравнения профилей текущего запуска с профилями предыдущего запуска
+func compare(opts *options) error {
+	baseRequests := requests(loadReportPath(opts.dir, opts.baseline))
+	currentRequests := requests(loadReportPath(opts.dir, opts.name))
+	if baseRequests == 0 || currentRequests == 0 {
+		fmt.Println("Load reports are missing: total costs are compared without normalization per request")
+	}
+
+	var violations []string
+	compared := 0
+	for _, kind := range profileKinds {
+		basePath := profilePath(opts.dir, opts.baseline, kind)
+		currentPath := profilePath(opts.dir, opts.name, kind)
+		if missing := missingFile(basePath, currentPath); len(missing) != 0 {
+			fmt.Printf("\n%s: %s not found, skipped\n", kind, missing)
+			continue
+		}
+		base, err := profiling.ParseFile(basePath)
+		if err != nil {
+			return err
+		}
+		current, err := profiling.ParseFile(currentPath)
+		if err != nil {
+			return err
+		}
+		comparison, err := profiling.Compare(kind, base, current, baseRequests, currentRequests, opts.top)
+		if err != nil {
+			return err
+		}
+		fmt.Println()
+		if err = comparison.Write(os.Stdout); err != nil {
+			return err
+		}
+		violations = append(violations, comparison.Check(opts.thresholds)...)
+		compared++
+	}
+	if compared == 0 {
+		return fmt.Errorf("no baseline profiles %q found in %s", opts.baseline, opts.dir)
+	}
+	if len(violations) != 0 {
+		return fmt.Errorf("performance regression against %q:\n  %s", opts.baseline, strings.Join(violations, "\n  "))
+	}
+	fmt.Printf("\nNo regressions against %q\n", opts.baseline)
+	return nil
+}
+
+// missingFile - метод получения первого отсутствующего файла из paths (пустая строка - все файлы есть)
+func missingFile(paths ...string) string {
+	for _, path := range paths {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return path
+		}
+	}
+	return ""
+}
+
+// requests - метод получения количества запросов из отчета о нагрузке (0 - отчет отсутствует)
+func requests(path string) int64 {
+	report, err := loadgen.LoadReport(path)
+	if err != nil {
+		return 0
+	}
+	return report.Total.Requests
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/denmor86/go-url-shortener/pkg/client"
+)
+
+// command - команда клиента
+type command struct {
+	name    string                                                 // имя команды
+	args    string                                                 // описание аргументов
+	summary string                                                 // краткое описание
+	run     func(ctx context.Context, c *cli, args []string) error // выполнение команды
+}
+
+// commands - метод получения списка команд
+func commands() []command {
+	return []command{
+		{"shorten", "URL...", "Shorten URLs", runShorten},
+		{"batch", "[-f FILE]", "Shorten URLs from CSV (url or correlation_id,url per line)", runBatch},
+		{"list", "", "List user URLs", runList},
+		{"delete", "ID...", "Delete user URLs by short ID or short URL", runDelete},
+		{"expand", "ID...", "Show original URLs", runExpand},
+		{"stats", "", "Show service statistics (trusted subnets only)", runStats},
+		{"profile", "[show|set|reset]", "Show profile, save --server/--transport, or forget user", runProfile},
+		{"completion", "bash|zsh|fish", "Print shell completion script", runCompletion},
+	}
+}
+
+// findCommand - метод поиска команды по имени
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// runShorten - команда сокращения ссылок
+func runShorten(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("shorten: URL is required")
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	results := make([]client.URL, 0, len(args))
+	for _, arg := range args {
+		shortURL, err := api.Shorten(ctx, arg)
+		// уже сокращенная ссылка не считается ошибкой: выводится существующая короткая ссылка
+		if err != nil && !errors.Is(err, client.ErrUniqueViolation) {
+			return fmt.Errorf("shorten %s: %w", arg, err)
+		}
+		results = append(results, client.URL{ShortURL: shortURL, OriginalURL: arg})
+	}
+	return c.out.URLs(results)
+}
+
+// runBatch - команда пакетного сокращения ссылок из CSV
+func runBatch(ctx context.Context, c *cli, args []string) error {
+	in := c.in
+	if c.opts.file != "-" {
+		file, err := os.Open(c.opts.file)
+		if err != nil {
+			return fmt.Errorf("batch: %w", err)
+		}
+		defer file.Close()
+		in = file
+	}
+	items, err := readBatch(in)
+	if err != nil {
+		return fmt.Errorf("batch: %w", err)
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("batch: no URLs in input")
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	results, err := api.ShortenBatch(ctx, items)
+	if err != nil {
+		return fmt.Errorf("batch: %w", err)
+	}
+	return c.out.Batch(results)
+}
+
+// readBatch - метод чтения ссылок для пакетного сокращения: CSV с URL или парой идентификатор, URL в строке.
+// Строка заголовка (без URL) пропускается, для строк без идентификатора он формируется по номеру строки
+func readBatch(r io.Reader) ([]client.BatchItem, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	var items []client.BatchItem
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var item client.BatchItem
+		switch len(record) {
+		case 1:
+			item = client.BatchItem{ID: strconv.Itoa(line), URL: strings.TrimSpace(record[0])}
+		case 2:
+			item = client.BatchItem{ID: strings.TrimSpace(record[0]), URL: strings.TrimSpace(record[1])}
+		default:
+			return nil, fmt.Errorf("line %d: expected url or correlation_id,url", line)
+		}
+		if line == 1 && !strings.Contains(item.URL, "://") {
+			continue
+		}
+		if len(item.URL) != 0 {
+			items = append(items, item)
+		}
+	}
+}
+
+// runList - команда вывода ссылок пользователя
+func runList(ctx context.Context, c *cli, args []string) error {
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	results, err := api.List(ctx)
+	if err != nil {
+		return fmt.Errorf("list: %w", err)
+	}
+	return c.out.URLs(results)
+}
+
+// runDelete - команда удаления ссылок пользователя
+func runDelete(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("delete: ID is required")
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	if err = api.Delete(ctx, args); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+	return c.out.Message(fmt.Sprintf("%d URLs scheduled for deletion", len(args)))
+}
+
+// runExpand - команда получения оригинальных URL
+func runExpand(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expand: ID is required")
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	results := make([]client.URL, 0, len(args))
+	for _, arg := range args {
+		original, err := api.Expand(ctx, arg)
+		if err != nil {
+			return fmt.Errorf("expand %s: %w", arg, err)
+		}
+		results = append(results, client.URL{ShortURL: arg, OriginalURL: original})
+	}
+	return c.out.URLs(results)
+}
+
+// runStats - команда получения статистики сервиса
+func runStats(ctx context.Context, c *cli, args []string) error {
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	stats, err := api.Stats(ctx)
+	if err != nil {
+		return fmt.Errorf("stats: %w", err)
+	}
+	return c.out.Stats(stats)
+}
+
+// runProfile - команда работы с профилем: show - вывод, set - сохранение адреса и транспорта, reset - удаление учетных данных
+func runProfile(ctx context.Context, c *cli, args []string) error {
+	action := "show"
+	if len(args) > 0 {
+		action = args[0]
+	}
+	switch action {
+	case "show":
+	case "set":
+		c.profile.Transport = c.transport()
+		c.profile.Server = c.server()
+		c.profile.changed = true
+	case "reset":
+		c.profile.Reset()
+	default:
+		return fmt.Errorf("profile: unknown action %q (show, set, reset)", action)
+	}
+	return c.out.Profile(c.opts.profile, c.profile, c.transport(), c.server())
+}
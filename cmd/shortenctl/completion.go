@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// runCompletion - команда вывода скрипта автодополнения для оболочки bash, zsh или fish
+func runCompletion(ctx context.Context, c *cli, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("completion: shell is required (bash, zsh, fish)")
+	}
+	switch args[0] {
+	case "bash":
+		return writeBashCompletion(c.out.w, c.flags)
+	case "zsh":
+		// zsh использует скрипт bash через bashcompinit
+		fmt.Fprintln(c.out.w, "autoload -U +X bashcompinit && bashcompinit")
+		return writeBashCompletion(c.out.w, c.flags)
+	case "fish":
+		return writeFishCompletion(c.out.w, c.flags)
+	default:
+		return fmt.Errorf("completion: unknown shell %q (bash, zsh, fish)", args[0])
+	}
+}
+
+// writeBashCompletion - метод вывода скрипта автодополнения bash
+func writeBashCompletion(w io.Writer, fs *pflag.FlagSet) error {
+	var names, flags []string
+	for _, cmd := range commands() {
+		names = append(names, cmd.name)
+	}
+	fs.VisitAll(func(f *pflag.Flag) {
+		flags = append(flags, "--"+f.Name)
+	})
+	_, err := fmt.Fprintf(w, `_shortenctl() {
+	local cur prev words cword
+	COMPREPLY=()
+	cur="${COMP_WORDS[COMP_CWORD]}"
+	prev="${COMP_WORDS[COMP_CWORD-1]}"
+	case "$prev" in
+	--transport|-t) COMPREPLY=($(compgen -W "http grpc" -- "$cur")); return ;;
+	--output|-o) COMPREPLY=($(compgen -W "table json" -- "$cur")); return ;;
+	--file|-f|--ca) COMPREPLY=($(compgen -f -- "$cur")); return ;;
+	completion) COMPREPLY=($(compgen -W "bash zsh fish" -- "$cur")); return ;;
+	profile) COMPREPLY=($(compgen -W "show set reset" -- "$cur")); return ;;
+	esac
+	if [[ "$cur" == -* ]]; then
+		COMPREPLY=($(compgen -W "%s" -- "$cur"))
+		return
+	fi
+	COMPREPLY=($(compgen -W "%s" -- "$cur"))
+}
+complete -F _shortenctl shortenctl
+`, strings.Join(flags, " "), strings.Join(names, " "))
+	return err
+}
+
+// writeFishCompletion - метод вывода скрипта автодополнения fish
+func writeFishCompletion(w io.Writer, fs *pflag.FlagSet) error {
+	var names []string
+	for _, cmd := range commands() {
+		names = append(names, cmd.name)
+	}
+	fmt.Fprintln(w, "complete -c shortenctl -f")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "complete -c shortenctl -n 'not __fish_seen_subcommand_from %s' -a %s -d '%s'\n",
+			strings.Join(names, " "), cmd.name, strings.ReplaceAll(cmd.summary, "'", `\'`))
+	}
+	fmt.Fprintln(w, "complete -c shortenctl -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'")
+	fmt.Fprintln(w, "complete -c shortenctl -n '__fish_seen_subcommand_from profile' -a 'show set reset'")
+	var err error
+	fs.VisitAll(func(f *pflag.Flag) {
+		line := "complete -c shortenctl -l " + f.Name
+		if len(f.Shorthand) != 0 {
+			line += " -s " + f.Shorthand
+		}
+		switch f.Name {
+		case "transport":
+			line += " -x -a 'http grpc'"
+		case "output":
+			line += " -x -a 'table json'"
+		case "file", "ca":
+			line += " -r -F"
+		default:
+			if f.Value.Type() != "bool" {
+				line += " -x"
+			}
+		}
+		line += " -d '" + strings.ReplaceAll(f.Usage, "'", `\'`) + "'"
+		if _, e := fmt.Fprintln(w, line); e != nil && err == nil {
+			err = e
+		}
+	})
+	return err
+}
@@ -0,0 +1,187 @@
+// Command shortenctl - консольный клиент сервиса сокращения ссылок.
+// Работает через HTTP API (токен пользователя сохраняется в локальном профиле) или GRPC API,
+// результаты выводятся таблицей или в формате JSON.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/denmor86/go-url-shortener/pkg/client"
+)
+
+// Адреса сервиса по-умолчанию
+const (
+	DefaultHTTPServer = "http://localhost:8080"
+	DefaultGRPCServer = "localhost:8081"
+)
+
+// options - глобальные параметры запуска
+type options struct {
+	profile   string        // имя профиля
+	server    string        // адрес сервиса
+	transport string        // транспорт: http или grpc
+	output    string        // формат вывода: table или json
+	timeout   time.Duration // время выполнения команды
+	tls       bool          // признак подключения к GRPC по TLS
+	caFile    string        // файл сертификатов CA для проверки сервера
+	insecure  bool          // признак отключения проверки сертификата сервера
+	file      string        // файл со ссылками для пакетного сокращения (batch)
+}
+
+// cli - состояние выполнения команды
+type cli struct {
+	opts    options
+	flags   *pflag.FlagSet
+	profile *Profile
+	in      io.Reader
+	out     *printer
+	conn    client.Client
+}
+
+// функция main вызывается автоматически при запуске приложения
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("shortenctl: ")
+	if err := run(context.Background(), os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run - метод разбора параметров и выполнения команды
+func run(ctx context.Context, args []string, in io.Reader, out io.Writer) error {
+	c := &cli{in: in}
+	c.flags = newFlagSet(&c.opts)
+	if err := c.flags.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			usage(out, c.flags)
+			return nil
+		}
+		return err
+	}
+	if c.flags.NArg() == 0 {
+		usage(out, c.flags)
+		return fmt.Errorf("command is required")
+	}
+	cmd, ok := findCommand(c.flags.Arg(0))
+	if !ok {
+		return fmt.Errorf("unknown command %q", c.flags.Arg(0))
+	}
+	printer, err := newPrinter(out, c.opts.output)
+	if err != nil {
+		return err
+	}
+	c.out = printer
+	if c.profile, err = LoadProfile(c.opts.profile); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.opts.timeout)
+	defer cancel()
+	err = cmd.run(ctx, c, c.flags.Args()[1:])
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	// токен пользователя сохраняется и при ошибке команды: сервис мог выдать новый
+	if saveErr := c.profile.Save(); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	return err
+}
+
+// newFlagSet - метод формирования глобальных параметров запуска
+func newFlagSet(opts *options) *pflag.FlagSet {
+	fs := pflag.NewFlagSet("shortenctl", pflag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVarP(&opts.profile, "profile", "p", "default", "Local profile name")
+	fs.StringVarP(&opts.server, "server", "s", "", "Service address: base URL for http, host:port for grpc (default from profile)")
+	fs.StringVarP(&opts.transport, "transport", "t", client.TransportHTTP, "Transport: http or grpc (profile value is used if not set)")
+	fs.StringVarP(&opts.output, "output", "o", formatTable, "Output format: table or json")
+	fs.DurationVar(&opts.timeout, "timeout", 30*time.Second, "Command timeout")
+	fs.BoolVar(&opts.tls, "tls", false, "Connect to grpc using TLS")
+	fs.StringVar(&opts.caFile, "ca", "", "Path to CA bundle to verify server certificate")
+	fs.BoolVar(&opts.insecure, "insecure", false, "Skip server certificate verification")
+	fs.StringVarP(&opts.file, "file", "f", "-", "CSV file for batch command (- for stdin)")
+	return fs
+}
+
+// transport - метод определения транспорта: флаг, затем профиль
+func (c *cli) transport() string {
+	if c.flags.Changed("transport") || len(c.profile.Transport) == 0 {
+		return c.opts.transport
+	}
+	return c.profile.Transport
+}
+
+// server - метод определения адреса сервиса: флаг, затем профиль (если транспорт совпадает), затем адрес по-умолчанию
+func (c *cli) server() string {
+	transport := c.transport()
+	if c.flags.Changed("server") {
+		return c.opts.server
+	}
+	if len(c.profile.Server) != 0 && (c.profile.Transport == transport || (len(c.profile.Transport) == 0 && transport == client.TransportHTTP)) {
+		return c.profile.Server
+	}
+	if transport == client.TransportGRPC {
+		return DefaultGRPCServer
+	}
+	return DefaultHTTPServer
+}
+
+// client - метод получения клиента API (создается при первом обращении)
+func (c *cli) client() (client.Client, error) {
+	if c.conn != nil {
+		return c.conn, nil
+	}
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig == nil && c.opts.tls && c.transport() == client.TransportGRPC {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	c.conn, err = client.New(c.transport(), c.server(), client.Options{TLSConfig: tlsConfig, Store: c.profile})
+	if err != nil {
+		return nil, err
+	}
+	return c.conn, nil
+}
+
+// tlsConfig - метод формирования настроек TLS (nil - настройки по-умолчанию)
+func (c *cli) tlsConfig() (*tls.Config, error) {
+	if len(c.opts.caFile) == 0 && !c.opts.insecure {
+		return nil, nil
+	}
+	config := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: c.opts.insecure}
+	if len(c.opts.caFile) != 0 {
+		buf, err := os.ReadFile(c.opts.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't read CA file: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(buf) {
+			return nil, fmt.Errorf("no certificates in CA file %s", c.opts.caFile)
+		}
+	}
+	return config, nil
+}
+
+// usage - метод вывода справки
+func usage(w io.Writer, fs *pflag.FlagSet) {
+	fmt.Fprintln(w, "Usage: shortenctl [flags] <command> [args]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-10s %-18s %s\n", cmd.name, cmd.args, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nFlags:")
+	fmt.Fprint(w, fs.FlagUsages())
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/denmor86/go-url-shortener/internal/helpers"
+	"github.com/denmor86/go-url-shortener/pkg/client"
+)
+
+// testService - тестовый HTTP сервис: выдает токен пользователя и хранит сокращенные им ссылки
+type testService struct {
+	mu    sync.Mutex
+	base  string       // адрес сервиса
+	token string       // токен, выдаваемый сервисом
+	urls  []client.URL // ссылки пользователя
+}
+
+func (s *testService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cookie, err := r.Cookie("user-token")
+	authorized := err == nil && cookie.Value == s.token
+	if !authorized {
+		http.SetCookie(w, &http.Cookie{Name: "user-token", Value: s.token})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/shorten":
+		var request struct {
+			URL string `json:"url"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		for _, url := range s.urls {
+			if url.OriginalURL == request.URL {
+				w.WriteHeader(http.StatusConflict)
+				json.NewEncoder(w).Encode(map[string]string{"result": url.ShortURL})
+				return
+			}
+		}
+		url := client.URL{ShortURL: s.base + "/" + strings.TrimPrefix(request.URL, "https://"), OriginalURL: request.URL}
+		s.urls = append(s.urls, url)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"result": url.ShortURL})
+	case r.Method == http.MethodPost && r.URL.Path == "/api/shorten/batch":
+		var items []client.BatchItem
+		json.NewDecoder(r.Body).Decode(&items)
+		results := make([]client.BatchResult, 0, len(items))
+		for _, item := range items {
+			url := client.URL{ShortURL: s.base + "/" + strings.TrimPrefix(item.URL, "https://"), OriginalURL: item.URL}
+			s.urls = append(s.urls, url)
+			results = append(results, client.BatchResult{ID: item.ID, ShortURL: url.ShortURL})
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(results)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/user/urls":
+		if !authorized || len(s.urls) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		json.NewEncoder(w).Encode(s.urls)
+	default:
+		http.Error(w, "URL not found", http.StatusBadRequest)
+	}
+}
+
+func TestRun(t *testing.T) {
+	token, err := helpers.BuildJWT("user", []byte("secret"))
+	require.NoError(t, err)
+	service := &testService{token: token}
+	server := httptest.NewServer(service)
+	defer server.Close()
+	service.base = server.URL
+
+	dir := t.TempDir()
+	t.Setenv(profileDirEnv, dir)
+
+	testCases := []struct {
+		name    string
+		args    []string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Shorten #1 (good)",
+			args: []string{"-s", "{server}", "shorten", "https://ya.ru"},
+			want: "SHORT URL ORIGINAL URL\n{server}/ya.ru https://ya.ru\n",
+		},
+		{
+			name: "Shorten already shortened #2 (good)",
+			args: []string{"-s", "{server}", "-o", "json", "shorten", "https://ya.ru"},
+			want: "[\n  {\n    \"short_url\": \"{server}/ya.ru\",\n    \"original_url\": \"https://ya.ru\"\n  }\n]\n",
+		},
+		{
+			name: "Batch from CSV #3 (good)",
+			args: []string{"-s", "{server}", "batch"},
+			in:   "correlation_id,original_url\n1,https://google.com\n2,https://example.com\n",
+			want: "CORRELATION ID SHORT URL\n1 {server}/google.com\n2 {server}/example.com\n",
+		},
+		{
+			name:    "Batch without URLs #4 (bad)",
+			args:    []string{"-s", "{server}", "batch"},
+			in:      "correlation_id,original_url\n",
+			wantErr: true,
+		},
+		{
+			name: "List in JSON #5 (good)",
+			args: []string{"-s", "{server}", "-o", "json", "list"},
+			want: "[\n  {\n    \"short_url\": \"{server}/ya.ru\",\n    \"original_url\": \"https://ya.ru\"\n  },\n" +
+				"  {\n    \"short_url\": \"{server}/google.com\",\n    \"original_url\": \"https://google.com\"\n  },\n" +
+				"  {\n    \"short_url\": \"{server}/example.com\",\n    \"original_url\": \"https://example.com\"\n  }\n]\n",
+		},
+		{
+			name: "List in table #6 (good)",
+			args: []string{"-s", "{server}", "list"},
+			want: "SHORT URL ORIGINAL URL\n{server}/ya.ru https://ya.ru\n" +
+				"{server}/google.com https://google.com\n{server}/example.com https://example.com\n",
+		},
+		{
+			name: "List of other profile #7 (good)",
+			args: []string{"-s", "{server}", "-p", "other", "-o", "json", "list"},
+			want: "[]\n",
+		},
+		{
+			name:    "Unknown output format #8 (bad)",
+			args:    []string{"-o", "yaml", "list"},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown command #9 (bad)",
+			args:    []string{"unknown"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			args := make([]string, 0, len(tc.args))
+			for _, arg := range tc.args {
+				args = append(args, strings.ReplaceAll(arg, "{server}", server.URL))
+			}
+			var out bytes.Buffer
+			err := run(context.Background(), args, strings.NewReader(tc.in), &out)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			want := strings.ReplaceAll(tc.want, "{server}", server.URL)
+			// ширина колонок таблицы зависит от длины адреса сервиса, поэтому ожидаемая таблица выравнивается при проверке
+			if !strings.HasPrefix(tc.want, "[") {
+				want = alignTable(want)
+			}
+			assert.Equal(t, want, out.String())
+		})
+	}
+
+	// токен пользователя, выданный сервисом, сохранен в профиле
+	buf, err := os.ReadFile(filepath.Join(dir, "default.json"))
+	require.NoError(t, err)
+	profile := &Profile{}
+	require.NoError(t, json.Unmarshal(buf, profile))
+	assert.Equal(t, token, profile.Token)
+	assert.Equal(t, "user", profile.UserID)
+}
+
+// alignTable - метод выравнивания колонок таблицы так же, как при выводе команд
+func alignTable(table string) string {
+	lines := strings.Split(strings.TrimSuffix(table, "\n"), "\n")
+	var header []string
+	rows := make([][]string, 0, len(lines))
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if i == 0 {
+			// заголовки колонок состоят из двух слов
+			header = []string{fields[0] + " " + fields[1], fields[2] + " " + fields[3]}
+			continue
+		}
+		rows = append(rows, fields)
+	}
+	var out bytes.Buffer
+	p := &printer{w: &out}
+	p.print(nil, header, rows)
+	return out.String()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/denmor86/go-url-shortener/pkg/client"
+)
+
+// Форматы вывода
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// printer - вывод результатов команд таблицей или в формате JSON
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+// newPrinter - метод создания вывода в формате format
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	switch format {
+	case formatTable:
+		return &printer{w: w}, nil
+	case formatJSON:
+		return &printer{w: w, json: true}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q (table, json)", format)
+	}
+}
+
+// URLs - метод вывода ссылок
+func (p *printer) URLs(urls []client.URL) error {
+	if urls == nil {
+		urls = []client.URL{}
+	}
+	rows := make([][]string, 0, len(urls))
+	for _, url := range urls {
+		rows = append(rows, []string{url.ShortURL, url.OriginalURL})
+	}
+	return p.print(urls, []string{"SHORT URL", "ORIGINAL URL"}, rows)
+}
+
+// Batch - метод вывода результатов пакетного сокращения
+func (p *printer) Batch(results []client.BatchResult) error {
+	rows := make([][]string, 0, len(results))
+	for _, result := range results {
+		rows = append(rows, []string{result.ID, result.ShortURL})
+	}
+	return p.print(results, []string{"CORRELATION ID", "SHORT URL"}, rows)
+}
+
+// Stats - метод вывода статистики сервиса
+func (p *printer) Stats(stats client.Stats) error {
+	return p.print(stats, []string{"URLS", "USERS"}, [][]string{{strconv.Itoa(stats.URLs), strconv.Itoa(stats.Users)}})
+}
+
+// Profile - метод вывода профиля (токен не выводится)
+func (p *printer) Profile(name string, profile *Profile, transport, server string) error {
+	token := "not set"
+	if len(profile.Token) != 0 {
+		token = "set"
+	}
+	value := map[string]string{
+		"profile":   name,
+		"server":    server,
+		"transport": transport,
+		"user_id":   profile.UserID,
+		"token":     token,
+	}
+	rows := [][]string{{"profile", name}, {"server", server}, {"transport", transport}, {"user_id", profile.UserID}, {"token", token}}
+	return p.print(value, []string{"NAME", "VALUE"}, rows)
+}
+
+// Message - метод вывода сообщения о результате команды
+func (p *printer) Message(message string) error {
+	if p.json {
+		return p.print(map[string]string{"message": message}, nil, nil)
+	}
+	_, err := fmt.Fprintln(p.w, message)
+	return err
+}
+
+// print - метод вывода значения value в формате JSON или таблицы с заголовком header
+func (p *printer) print(value any, header []string, rows [][]string) error {
+	if p.json {
+		encoder := json.NewEncoder(p.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
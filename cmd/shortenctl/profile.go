@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/denmor86/go-url-shortener/pkg/client"
+)
+
+// profileDirEnv - переменная окружения с каталогом профилей (по-умолчанию - каталог настроек пользователя)
+const profileDirEnv = "SHORTENCTL_HOME"
+
+// Profile - локальный профиль клиента: адрес сервиса, транспорт и учетные данные пользователя
+type Profile struct {
+	Server    string `json:"server,omitempty"`    // адрес сервиса: URL для HTTP, host:port для GRPC
+	Transport string `json:"transport,omitempty"` // транспорт: http или grpc
+	Token     string `json:"token,omitempty"`     // JWT токен пользователя (cookie user-token)
+	UserID    string `json:"user_id,omitempty"`   // идентификатор пользователя
+
+	path    string // путь к файлу профиля
+	changed bool   // признак изменения профиля после загрузки
+}
+
+// LoadProfile - метод загрузки профиля по имени, отсутствующий профиль считается пустым
+func LoadProfile(name string) (*Profile, error) {
+	dir := os.Getenv(profileDirEnv)
+	if len(dir) == 0 {
+		config, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("can't find profile directory: %w", err)
+		}
+		dir = filepath.Join(config, "shortenctl")
+	}
+	profile := &Profile{path: filepath.Join(dir, name+".json")}
+	buf, err := os.ReadFile(profile.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return profile, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't load profile: %w", err)
+	}
+	if err = json.Unmarshal(buf, profile); err != nil {
+		return nil, fmt.Errorf("can't parse profile %s: %w", profile.path, err)
+	}
+	return profile, nil
+}
+
+// Credentials - метод получения учетных данных пользователя (client.CredentialStore)
+func (p *Profile) Credentials() client.Credentials {
+	return client.Credentials{Token: p.Token, UserID: p.UserID}
+}
+
+// SetCredentials - метод сохранения учетных данных пользователя, выданных сервисом (client.CredentialStore)
+func (p *Profile) SetCredentials(creds client.Credentials) {
+	if p.Token != creds.Token || p.UserID != creds.UserID {
+		p.Token = creds.Token
+		p.UserID = creds.UserID
+		p.changed = true
+	}
+}
+
+// Reset - метод удаления учетных данных пользователя
+func (p *Profile) Reset() {
+	p.Token = ""
+	p.UserID = ""
+	p.changed = true
+}
+
+// Save - метод сохранения профиля, если он изменен (файл доступен только владельцу)
+func (p *Profile) Save() error {
+	if !p.changed {
+		return nil
+	}
+	buf, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(p.path), 0700); err != nil {
+		return fmt.Errorf("can't save profile: %w", err)
+	}
+	if err = os.WriteFile(p.path, buf, 0600); err != nil {
+		return fmt.Errorf("can't save profile: %w", err)
+	}
+	p.changed = false
+	return nil
+}
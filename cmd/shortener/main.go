@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/denmor86/go-url-shortener/internal/app"
 	"github.com/denmor86/go-url-shortener/internal/config"
@@ -27,8 +28,27 @@ func main() {
 
 	showBuildInfo()
 
-	config := config.NewConfig()
-	storage := storage.NewStorage(config)
+	config, err := config.NewConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Вывод действующей конфигурации с источниками значений, серверы не запускаются
+	if config.PrintConfig {
+		if err := config.WriteSettings(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := app.ValidateConfig(config); err != nil {
+		log.Fatal(err)
+	}
+	// В режиме проверки конфигурации серверы не запускаются
+	if config.CheckConfig {
+		log.Println("Configuration is valid")
+		return
+	}
+
+	storage := storage.NewTracingStorage(storage.NewMetricsStorage(storage.NewStorage(config)))
 
 	defer logger.Sync()
 	defer storage.Close()
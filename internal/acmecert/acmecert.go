@@ -0,0 +1,48 @@
+// Package acmecert предоставляет автоматическое получение и продление TLS сертификатов по протоколу ACME
+// (Let's Encrypt и совместимые удостоверяющие центры) для хоста сервиса
+package acmecert
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/denmor86/go-url-shortener/internal/config"
+)
+
+// Hosts - метод получения имен хостов для сертификата из базового адреса сервиса.
+// IP адреса и имена без домена (например, localhost) не поддерживаются удостоверяющими центрами
+func Hosts(baseURL string) ([]string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if len(host) == 0 {
+		return nil, fmt.Errorf("base URL has no host: %s", baseURL)
+	}
+	if net.ParseIP(host) != nil || !strings.Contains(host, ".") {
+		return nil, fmt.Errorf("ACME requires a domain name, got: %s", host)
+	}
+	return []string{host}, nil
+}
+
+// NewManager - метод создания менеджера сертификатов ACME для хостов из базового адреса сервиса.
+// Сертификаты получаются при первом TLS рукопожатии, хранятся в cache и продлеваются автоматически
+func NewManager(cfg *config.Config, cache autocert.Cache) (*autocert.Manager, error) {
+	hosts, err := Hosts(cfg.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      cache,
+		HostPolicy: autocert.HostWhitelist(hosts...),
+		Email:      cfg.ACMEEmail,
+		Client:     &acme.Client{DirectoryURL: cfg.ACMEDirectoryURL},
+	}, nil
+}
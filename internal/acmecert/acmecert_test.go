@@ -0,0 +1,84 @@
+package acmecert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/denmor86/go-url-shortener/internal/config"
+	"github.com/denmor86/go-url-shortener/internal/logger"
+	"github.com/denmor86/go-url-shortener/internal/network/router"
+)
+
+func TestHosts(t *testing.T) {
+	testCases := []struct {
+		name     string
+		baseURL  string
+		expected []string
+		wantErr  bool
+	}{
+		{"Domain #1 (good)", "https://short.example", []string{"short.example"}, false},
+		{"Domain with port and path #2 (good)", "https://Short.Example.:8443/s/", []string{"short.example"}, false},
+		{"Localhost #3 (bad)", "http://localhost:8080", nil, true},
+		{"IP address #4 (bad)", "https://127.0.0.1", nil, true},
+		{"Without host #5 (bad)", "/path", nil, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hosts, err := Hosts(tc.baseURL)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, hosts)
+		})
+	}
+}
+
+func TestManager(t *testing.T) {
+	require.NoError(t, logger.Initialize("info"))
+	cfg := config.NewDefaultConfig()
+	cfg.BaseURL = "https://short.example"
+	cfg.ACMEEmail = "admin@short.example"
+	cache := autocert.DirCache(t.TempDir())
+	manager, err := NewManager(cfg, cache)
+	require.NoError(t, err)
+
+	// HTTP сервер сервиса для проверки владения доменом и тестовый удостоверяющий центр
+	challenge := httptest.NewServer(router.ChallengeRouter(cfg, manager.HTTPHandler(nil)))
+	defer challenge.Close()
+	ca := newTestCA(t, challenge.URL)
+	cfg.ACMEDirectoryURL = ca.DirectoryURL()
+	manager.Client.DirectoryURL = ca.DirectoryURL()
+
+	hello := &tls.ClientHelloInfo{ServerName: "short.example"}
+
+	t.Run("Issue certificate #1 (good)", func(t *testing.T) {
+		cert, err := manager.GetCertificate(hello)
+		require.NoError(t, err)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		assert.Equal(t, []string{"short.example"}, leaf.DNSNames)
+		assert.Equal(t, 1, ca.Issued())
+	})
+
+	t.Run("Certificate from cache #2 (good)", func(t *testing.T) {
+		other, err := NewManager(cfg, cache)
+		require.NoError(t, err)
+		_, err = other.GetCertificate(hello)
+		require.NoError(t, err)
+		assert.Equal(t, 1, ca.Issued())
+	})
+
+	t.Run("Unknown host #3 (bad)", func(t *testing.T) {
+		_, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "other.example"})
+		assert.Error(t, err)
+		assert.Equal(t, 1, ca.Issued())
+	})
+}
@@ -0,0 +1,287 @@
+package acmecert
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/denmor86/go-url-shortener/internal/helpers"
+)
+
+// testCA - удостоверяющий центр ACME (RFC 8555) для тестов: одна учетная запись, один заказ,
+// проверка владения доменом только HTTP-01. Подписи запросов не проверяются
+type testCA struct {
+	t         *testing.T
+	server    *httptest.Server
+	challenge string // адрес HTTP сервера, на котором проверяется владение доменом
+
+	caCert *x509.Certificate
+	caKey  any
+	caPEM  []byte
+
+	mu         sync.Mutex
+	thumbprint string // отпечаток ключа учетной записи
+	domain     string // домен текущего заказа
+	token      string // токен проверки владения доменом
+	status     string // состояние авторизации: pending, valid, invalid
+	certPEM    []byte // выпущенный сертификат
+	issued     int    // количество выпущенных сертификатов
+}
+
+// newTestCA - метод запуска тестового удостоверяющего центра, проверяющего владение доменом на сервере challenge
+func newTestCA(t *testing.T, challenge string) *testCA {
+	t.Helper()
+	caPEM, caKeyPEM, err := helpers.GenerateCACert("test-acme-ca")
+	require.NoError(t, err)
+	pair, err := tls.X509KeyPair(caPEM, caKeyPEM)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(pair.Certificate[0])
+	require.NoError(t, err)
+
+	ca := &testCA{t: t, challenge: challenge, caCert: caCert, caKey: pair.PrivateKey, caPEM: caPEM}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/directory", ca.directory)
+	mux.HandleFunc("/nonce", ca.nonce)
+	mux.HandleFunc("/account", ca.account)
+	mux.HandleFunc("/order", ca.order)
+	mux.HandleFunc("/order/1", ca.orderStatus)
+	mux.HandleFunc("/authz", ca.authz)
+	mux.HandleFunc("/chal", ca.accept)
+	mux.HandleFunc("/finalize", ca.finalize)
+	mux.HandleFunc("/cert", ca.cert)
+	ca.server = httptest.NewServer(mux)
+	t.Cleanup(ca.server.Close)
+	return ca
+}
+
+// DirectoryURL - метод получения адреса каталога ACME
+func (ca *testCA) DirectoryURL() string {
+	return ca.server.URL + "/directory"
+}
+
+// Issued - метод получения количества выпущенных сертификатов
+func (ca *testCA) Issued() int {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	return ca.issued
+}
+
+func (ca *testCA) directory(w http.ResponseWriter, r *http.Request) {
+	ca.write(w, http.StatusOK, map[string]string{
+		"newNonce":   ca.server.URL + "/nonce",
+		"newAccount": ca.server.URL + "/account",
+		"newOrder":   ca.server.URL + "/order",
+	})
+}
+
+func (ca *testCA) nonce(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Replay-Nonce", newNonce())
+	w.WriteHeader(http.StatusOK)
+}
+
+func (ca *testCA) account(w http.ResponseWriter, r *http.Request) {
+	header, _ := ca.decode(r)
+	var jwk struct {
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+	require.NoError(ca.t, json.Unmarshal(header.JWK, &jwk))
+	// отпечаток ключа по RFC 7638 (ключ учетной записи autocert - ECDSA)
+	sum := sha256.Sum256([]byte(fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`, jwk.Crv, jwk.X, jwk.Y)))
+
+	ca.mu.Lock()
+	ca.thumbprint = base64.RawURLEncoding.EncodeToString(sum[:])
+	ca.mu.Unlock()
+	w.Header().Set("Location", ca.server.URL+"/account/1")
+	ca.write(w, http.StatusCreated, map[string]any{"status": "valid"})
+}
+
+func (ca *testCA) order(w http.ResponseWriter, r *http.Request) {
+	_, payload := ca.decode(r)
+	var req struct {
+		Identifiers []struct {
+			Value string `json:"value"`
+		} `json:"identifiers"`
+	}
+	require.NoError(ca.t, json.Unmarshal(payload, &req))
+	require.Len(ca.t, req.Identifiers, 1)
+
+	ca.mu.Lock()
+	ca.domain = req.Identifiers[0].Value
+	ca.token = newNonce()
+	ca.status = "pending"
+	ca.certPEM = nil
+	ca.mu.Unlock()
+	w.Header().Set("Location", ca.server.URL+"/order/1")
+	ca.write(w, http.StatusCreated, ca.orderState())
+}
+
+func (ca *testCA) orderStatus(w http.ResponseWriter, r *http.Request) {
+	ca.decode(r)
+	ca.write(w, http.StatusOK, ca.orderState())
+}
+
+func (ca *testCA) authz(w http.ResponseWriter, r *http.Request) {
+	ca.decode(r)
+	ca.write(w, http.StatusOK, ca.authzState())
+}
+
+// accept - обработка запроса на проверку владения доменом: ключ авторизации запрашивается с HTTP сервера сервиса
+func (ca *testCA) accept(w http.ResponseWriter, r *http.Request) {
+	ca.decode(r)
+	ca.mu.Lock()
+	domain, token, thumbprint := ca.domain, ca.token, ca.thumbprint
+	ca.mu.Unlock()
+
+	status := "invalid"
+	req, err := http.NewRequest(http.MethodGet, ca.challenge+"/.well-known/acme-challenge/"+token, nil)
+	require.NoError(ca.t, err)
+	req.Host = domain
+	if resp, err := http.DefaultClient.Do(req); err == nil {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK && string(body) == token+"."+thumbprint {
+			status = "valid"
+		}
+	}
+
+	ca.mu.Lock()
+	ca.status = status
+	ca.mu.Unlock()
+	ca.write(w, http.StatusOK, ca.challengeState())
+}
+
+// finalize - выпуск сертификата по запросу на подпись
+func (ca *testCA) finalize(w http.ResponseWriter, r *http.Request) {
+	_, payload := ca.decode(r)
+	var req struct {
+		CSR string `json:"csr"`
+	}
+	require.NoError(ca.t, json.Unmarshal(payload, &req))
+	der, err := base64.RawURLEncoding.DecodeString(req.CSR)
+	require.NoError(ca.t, err)
+	csr, err := x509.ParseCertificateRequest(der)
+	require.NoError(ca.t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(ca.t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		DNSNames:     csr.DNSNames,
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().AddDate(0, 0, 90),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, ca.caCert, csr.PublicKey, ca.caKey)
+	require.NoError(ca.t, err)
+
+	ca.mu.Lock()
+	ca.certPEM = append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), ca.caPEM...)
+	ca.issued++
+	ca.mu.Unlock()
+	w.Header().Set("Location", ca.server.URL+"/order/1")
+	ca.write(w, http.StatusOK, ca.orderState())
+}
+
+func (ca *testCA) cert(w http.ResponseWriter, r *http.Request) {
+	ca.decode(r)
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	w.Header().Set("Replay-Nonce", newNonce())
+	w.Header().Set("Content-Type", "application/pem-certificate-chain")
+	w.Write(ca.certPEM)
+}
+
+// orderState - метод формирования состояния заказа
+func (ca *testCA) orderState() map[string]any {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	order := map[string]any{
+		"status":         "pending",
+		"identifiers":    []map[string]string{{"type": "dns", "value": ca.domain}},
+		"authorizations": []string{ca.server.URL + "/authz"},
+		"finalize":       ca.server.URL + "/finalize",
+	}
+	switch {
+	case ca.certPEM != nil:
+		order["status"] = "valid"
+		order["certificate"] = ca.server.URL + "/cert"
+	case ca.status == "valid":
+		order["status"] = "ready"
+	case ca.status == "invalid":
+		order["status"] = "invalid"
+	}
+	return order
+}
+
+// authzState - метод формирования состояния авторизации
+func (ca *testCA) authzState() map[string]any {
+	challenge := ca.challengeState()
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	return map[string]any{
+		"status":     ca.status,
+		"identifier": map[string]string{"type": "dns", "value": ca.domain},
+		"challenges": []map[string]any{challenge},
+	}
+}
+
+// challengeState - метод формирования состояния проверки владения доменом
+func (ca *testCA) challengeState() map[string]any {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	return map[string]any{"type": "http-01", "url": ca.server.URL + "/chal", "token": ca.token, "status": ca.status}
+}
+
+// jwsHeader - защищенный заголовок запроса ACME
+type jwsHeader struct {
+	JWK json.RawMessage `json:"jwk"`
+}
+
+// decode - метод разбора запроса ACME в формате JWS
+func (ca *testCA) decode(r *http.Request) (jwsHeader, []byte) {
+	var req struct {
+		Protected string `json:"protected"`
+		Payload   string `json:"payload"`
+	}
+	require.NoError(ca.t, json.NewDecoder(r.Body).Decode(&req))
+	protected, err := base64.RawURLEncoding.DecodeString(req.Protected)
+	require.NoError(ca.t, err)
+	var header jwsHeader
+	require.NoError(ca.t, json.Unmarshal(protected, &header))
+	payload, err := base64.RawURLEncoding.DecodeString(req.Payload)
+	require.NoError(ca.t, err)
+	return header, payload
+}
+
+// write - метод отправки ответа в формате JSON
+func (ca *testCA) write(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Replay-Nonce", newNonce())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	require.NoError(ca.t, json.NewEncoder(w).Encode(v))
+}
+
+// newNonce - метод формирования случайного значения
+func newNonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return strings.TrimRight(base64.RawURLEncoding.EncodeToString(b), "=")
+}
@@ -0,0 +1,72 @@
+package acmecert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Используемые SQL запросы
+const (
+	// GetData - SQL запрос получения данных по ключу.
+	// Таблица acme_cache создается миграциями хранилища (internal/storage/migrations)
+	GetData = `SELECT data FROM acme_cache WHERE key = $1;`
+	// PutData - SQL запрос сохранения данных по ключу
+	PutData = `INSERT INTO acme_cache (key, data) VALUES ($1, $2)
+					ON CONFLICT (key) DO UPDATE SET data = EXCLUDED.data, updated_at = now();`
+	// DeleteData - SQL запрос удаления данных по ключу
+	DeleteData = `DELETE FROM acme_cache WHERE key = $1;`
+)
+
+// PostgresCache - хранилище сертификатов и ключа учетной записи ACME в PostgreSQL (autocert.Cache),
+// общее для нескольких экземпляров сервиса
+type PostgresCache struct {
+	Pool *pgxpool.Pool // пул подключений
+}
+
+// NewPostgresCache - метод создания хранилища сертификатов ACME в PostgreSQL
+func NewPostgresCache(ctx context.Context, dsn string) (*PostgresCache, error) {
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create connection pool: %w", err)
+	}
+	return &PostgresCache{Pool: pool}, nil
+}
+
+// Get - метод получения данных по ключу (autocert.ErrCacheMiss, если данных нет)
+func (c *PostgresCache) Get(ctx context.Context, key string) ([]byte, error) {
+	var data []byte
+	err := c.Pool.QueryRow(ctx, GetData, key).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, autocert.ErrCacheMiss
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ACME cache entry: %w", err)
+	}
+	return data, nil
+}
+
+// Put - метод сохранения данных по ключу
+func (c *PostgresCache) Put(ctx context.Context, key string, data []byte) error {
+	if _, err := c.Pool.Exec(ctx, PutData, key, data); err != nil {
+		return fmt.Errorf("failed to put ACME cache entry: %w", err)
+	}
+	return nil
+}
+
+// Delete - метод удаления данных по ключу
+func (c *PostgresCache) Delete(ctx context.Context, key string) error {
+	if _, err := c.Pool.Exec(ctx, DeleteData, key); err != nil {
+		return fmt.Errorf("failed to delete ACME cache entry: %w", err)
+	}
+	return nil
+}
+
+// Close - метод закрытия пула подключений
+func (c *PostgresCache) Close() {
+	c.Pool.Close()
+}
@@ -4,24 +4,36 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"runtime"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/pkg/errors"
 
+	"golang.org/x/crypto/acme/autocert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 
+	"github.com/denmor86/go-url-shortener/internal/acmecert"
 	"github.com/denmor86/go-url-shortener/internal/config"
+	"github.com/denmor86/go-url-shortener/internal/helpers"
+	"github.com/denmor86/go-url-shortener/internal/idempotency"
 	"github.com/denmor86/go-url-shortener/internal/logger"
+	"github.com/denmor86/go-url-shortener/internal/metrics"
+	"github.com/denmor86/go-url-shortener/internal/network/gateway"
+	"github.com/denmor86/go-url-shortener/internal/network/interceptors"
+	"github.com/denmor86/go-url-shortener/internal/ratelimit"
 	grpcServer "github.com/denmor86/go-url-shortener/internal/server/grpc"
 	httpServer "github.com/denmor86/go-url-shortener/internal/server/http"
 	"github.com/denmor86/go-url-shortener/internal/storage"
+	"github.com/denmor86/go-url-shortener/internal/tracing"
 	"github.com/denmor86/go-url-shortener/internal/usecase"
 	"github.com/denmor86/go-url-shortener/internal/workerpool"
 )
@@ -32,45 +44,270 @@ type App struct {
 	Storage      storage.IStorage
 	httpServer   *http.Server
 	grpcServer   *grpc.Server
+	grpcHealth   *grpcServer.HealthChecker
 	grpcListener net.Listener
+	gatewayGRPC  *grpc.Server
+	gatewayConn  *grpc.ClientConn
+	certificates *helpers.CertReloader
+	acme         *autocert.Manager
+	acmeServer   *http.Server
+	tlsVersion   uint16
+	limiter      *ratelimit.Limiter
+	keeper       *idempotency.Keeper
+	reloadMu     sync.Mutex
 }
 
+// Внутренние константы приложения
+const (
+	// rateLimitCleanupInterval - период удаления неактивных ограничителей из PostgreSQL
+	rateLimitCleanupInterval = 10 * time.Minute
+	// idempotencyCleanupInterval - период удаления устаревших ключей идемпотентности из PostgreSQL
+	idempotencyCleanupInterval = 10 * time.Minute
+)
+
 // Run - метод иницилизации приложения и запуска сервера обработки сообщений
 func (a *App) Run() {
+	a.RunContext(context.Background())
+}
+
+// RunContext - метод запуска приложения, которое останавливается по сигналу или при завершении контекста ctx
+// (например, при запуске сервиса внутри другой программы)
+func (a *App) RunContext(ctx context.Context) {
 	if err := logger.Initialize(a.Config.LogLevel); err != nil {
 		panic(fmt.Sprintf("can't initialize logger: %s ", errors.Cause(err).Error()))
 	}
 
+	// секреты в строковом представлении конфигурации скрыты
 	logger.Info(
-		"Starting server config:", a.Config,
+		"Starting server config:", a.Config.String(),
 	)
 
+	shutdownTracing, err := tracing.Initialize(context.Background(), a.Config)
+	if err != nil {
+		panic(fmt.Sprintf("can't initialize tracing: %s ", errors.Cause(err).Error()))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("Tracing shutdown error", err.Error())
+		}
+	}()
+
 	workerpool := workerpool.NewWorkerPool(runtime.NumCPU())
 
 	workerpool.Run()
+	metrics.RegisterWorkerPool(workerpool)
 	defer func() {
 		workerpool.Close()
 		logger.Info("Close worker pool...")
 		workerpool.Wait()
 	}()
 
+	// Ограничитель частоты запросов общий для HTTP и GRPC
+	limiter, closeLimiter, err := a.newLimiter()
+	if err != nil {
+		panic(fmt.Sprintf("can't initialize rate limiter: %s ", errors.Cause(err).Error()))
+	}
+	limiter.SetAPIKeys(ratelimit.ParseAPIKeys(a.Config.RateLimitAPIKeys))
+	a.limiter = limiter
+	defer closeLimiter()
+
+	// Уровень логирования и политики ограничения частоты запросов изменяются при перечитывании конфигурации
+	a.Config.OnReload(a.applyConfig)
+
+	// Хранение ответов по ключу идемпотентности общее для HTTP и GRPC
+	keeper, closeKeeper, err := a.newKeeper()
+	if err != nil {
+		panic(fmt.Sprintf("can't initialize idempotency keys: %s ", errors.Cause(err).Error()))
+	}
+	a.keeper = keeper
+	defer closeKeeper()
+
+	// Сертификат общий для HTTPS и GRPC
+	if a.Config.HTTPSEnabled {
+		version, err := helpers.ParseTLSVersion(a.Config.TLSMinVersion)
+		if err != nil {
+			panic(fmt.Sprintf("can't create TLS configuration: %s ", errors.Cause(err).Error()))
+		}
+		a.tlsVersion = version
+		if a.Config.ACMEEnabled {
+			manager, closeACME, err := a.newACME()
+			if err != nil {
+				panic(fmt.Sprintf("can't initialize ACME: %s ", errors.Cause(err).Error()))
+			}
+			a.acme = manager
+			defer closeACME()
+		} else {
+			certificates, closeCertificates, err := a.newCertificates()
+			if err != nil {
+				panic(fmt.Sprintf("can't create certificate: %s ", errors.Cause(err).Error()))
+			}
+			a.certificates = certificates
+			defer closeCertificates()
+		}
+	} else if a.Config.ACMEEnabled {
+		logger.Warn("ACME is ignored: HTTPS is disabled")
+	}
+
 	// Запускаем серверы
 	if len(a.Config.ListenAddr) > 0 {
 		use := usecase.NewUsecaseHTTP(a.Config, a.Storage, workerpool)
-		go a.runHTTP(use)
+		handler, err := a.newGateway(usecase.NewUsecaseGRPC(a.Config, a.Storage, workerpool))
+		if err != nil {
+			logger.Error("REST gateway starting failed", err.Error())
+		}
+		go a.runHTTP(use, handler)
 	}
 	if len(a.Config.GRPCAddr) > 0 {
 		use := usecase.NewUsecaseGRPC(a.Config, a.Storage, workerpool)
 		go a.runGRPC(use)
 	}
+	if a.acme != nil && len(a.Config.ACMEHTTPAddr) > 0 {
+		go a.runACMEChallenge()
+	}
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(stop)
+	defer signal.Stop(reload)
+
+	// Ждем сигнал остановки, по SIGHUP перечитываем сертификат
+	for {
+		select {
+		case <-reload:
+			logger.Info("Reload signal received")
+			if _, err := a.reload(); err != nil {
+				logger.Warn("Configuration reload failed:", err)
+			}
+		case <-stop:
+			logger.Info("Shutdown signal received")
+			a.shutdown()
+			return
+		case <-ctx.Done():
+			logger.Info("Shutdown requested")
+			a.shutdown()
+			return
+		}
+	}
+}
+
+// reload - метод перечитывания сертификата TLS и конфигурации без остановки серверов.
+// Конфигурация применяется, только если она проходит проверку (ValidateConfig)
+func (a *App) reload() (config.ReloadResult, error) {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	if a.certificates != nil {
+		if err := a.certificates.Reload(); err != nil {
+			logger.Warn("TLS certificate reload failed:", err)
+		} else {
+			logger.Info("TLS certificate reloaded")
+		}
+	}
+
+	next, err := config.Reload()
+	if err != nil {
+		return config.ReloadResult{}, err
+	}
+	if err := ValidateConfig(next); err != nil {
+		return config.ReloadResult{}, err
+	}
+	result := a.Config.Apply(next)
+	logger.Info("Configuration reloaded, applied:", result.Applied, "restart required:", result.RestartRequired)
+	return result, nil
+}
+
+// applyConfig - метод применения уровня логирования и политик ограничения частоты запросов
+func (a *App) applyConfig(cfg *config.Config) {
+	if err := logger.SetLevel(cfg.LogLevel); err != nil {
+		logger.Warn("Log level is not changed:", err)
+	}
+	a.limiter.SetAPIKeys(ratelimit.ParseAPIKeys(cfg.RateLimitAPIKeys))
+	policies, err := ratelimit.ParsePolicies(cfg.RateLimits)
+	if err != nil {
+		logger.Warn("Rate limits are not changed:", err)
+		return
+	}
+	a.limiter.SetPolicies(policies)
+}
+
+// newCertificates - метод создания источника сертификата TLS по настройкам.
+// Сертификат загружается из файлов и перечитывается при их изменении; самоподписанный сертификат
+// формируется, только если это явно указано в настройках. Возвращает также функцию остановки отслеживания файлов
+func (a *App) newCertificates() (*helpers.CertReloader, func(), error) {
+	switch {
+	case len(a.Config.TLSCertFile) > 0 || len(a.Config.TLSKeyFile) > 0:
+		if len(a.Config.TLSCertFile) == 0 || len(a.Config.TLSKeyFile) == 0 {
+			return nil, nil, fmt.Errorf("both TLS certificate and key files are required")
+		}
+		certificates, err := helpers.NewCertReloader(a.Config.TLSCertFile, a.Config.TLSKeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		go certificates.Watch(ctx, a.Config.TLSReloadInterval)
+		return certificates, cancel, nil
+	case a.Config.TLSSelfSigned:
+		logger.Warn("Using self-signed TLS certificate")
+		cert, err := helpers.SelfSignedCertificate()
+		if err != nil {
+			return nil, nil, err
+		}
+		return helpers.NewStaticCertReloader(cert), func() {}, nil
+	default:
+		return nil, nil, fmt.Errorf("https requires TLS certificate and key files (or self-signed certificate option)")
+	}
+}
+
+// newACME - метод создания менеджера сертификатов ACME по настройкам.
+// Сертификаты хранятся в каталоге, если он задан, иначе - в PostgreSQL (таблица acme_cache).
+// Возвращает также функцию освобождения ресурсов хранилища
+func (a *App) newACME() (*autocert.Manager, func(), error) {
+	if len(a.Config.ACMECacheDir) > 0 {
+		manager, err := acmecert.NewManager(a.Config, autocert.DirCache(a.Config.ACMECacheDir))
+		return manager, func() {}, err
+	}
+	if len(a.Config.DatabaseDSN) == 0 {
+		return nil, nil, fmt.Errorf("ACME requires cache directory or database DSN")
+	}
+	cache, err := acmecert.NewPostgresCache(context.Background(), a.Config.DatabaseDSN)
+	if err != nil {
+		return nil, nil, err
+	}
+	manager, err := acmecert.NewManager(a.Config, cache)
+	if err != nil {
+		cache.Close()
+		return nil, nil, err
+	}
+	return manager, cache.Close, nil
+}
+
+// getCertificate - метод получения функции выдачи сертификата TLS (ACME или из файлов)
+func (a *App) getCertificate() func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if a.acme != nil {
+		return a.acme.GetCertificate
+	}
+	return a.certificates.GetCertificate
+}
+
+// challenge - метод получения обработчика проверки владения доменом ACME (nil, если ACME не используется)
+func (a *App) challenge() http.Handler {
+	if a.acme == nil {
+		return nil
+	}
+	return a.acme.HTTPHandler(nil)
+}
 
-	// Ждем сигнал остановки
-	<-stop
-	logger.Info("Shutdown signal received")
-	a.shutdown()
+// runACMEChallenge - метод запускает HTTP сервер проверки владения доменом ACME (HTTP-01)
+func (a *App) runACMEChallenge() {
+	a.acmeServer = httpServer.NewChallengeServer(a.Config, a.challenge())
+	logger.Info("Starting ACME challenge server on", a.Config.ACMEHTTPAddr)
+	if err := a.acmeServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		logger.Error("ACME challenge server error", err.Error())
+	}
 }
 
 // runGRPC - метод запускает GRPC сервер.
@@ -80,19 +317,151 @@ func (a *App) runGRPC(use *usecase.UsecaseGRPC) {
 		logger.Error("GRPC server starting failed", err)
 		return
 	}
+	var tlsConfig *tls.Config
+	if a.Config.HTTPSEnabled {
+		tlsConfig, err = helpers.NewServerTLSConfig(a.getCertificate(), a.tlsVersion, a.Config.GRPCClientCAFile)
+		if err != nil {
+			logger.Error("GRPC TLS configuration failed", err.Error())
+			listen.Close()
+			return
+		}
+	} else if len(a.Config.GRPCClientCAFile) > 0 {
+		logger.Warn("GRPC client CA is ignored: TLS is disabled")
+	}
+
 	a.grpcListener = listen
-	a.grpcServer = grpcServer.NewServer(use)
+	a.grpcHealth = grpcServer.NewHealthChecker(use, grpcServer.DefaultHealthCheckInterval)
+	limit := interceptors.NewRateLimit(a.limiter)
+	idem := interceptors.NewIdempotency(a.keeper)
+	a.grpcServer = grpcServer.NewServer(a.Config, use, a.grpcHealth, tlsConfig,
+		grpc.ChainUnaryInterceptor(limit.Unary, idem.Unary),
+		grpc.ChainStreamInterceptor(limit.Stream))
+	go a.grpcHealth.Run()
 
-	logger.Info("Starting GRPC server on", a.Config.GRPCAddr)
+	logger.Info("Starting GRPC server on", a.Config.GRPCAddr, "TLS:", tlsConfig != nil)
 
 	if err := a.grpcServer.Serve(listen); err != nil {
 		logger.Error("GRPC server error", err.Error())
 	}
 }
 
+// newLimiter - метод создания ограничителя частоты запросов по настройкам.
+// Политики могут отсутствовать (ограничение отключено). Возвращает также функцию освобождения ресурсов хранилища
+func (a *App) newLimiter() (*ratelimit.Limiter, func(), error) {
+	policies, err := ratelimit.ParsePolicies(a.Config.RateLimits)
+	if err != nil {
+		return nil, nil, err
+	}
+	// ограничитель создается и без политик: они могут быть заданы при перечитывании конфигурации
+	if len(policies) == 0 {
+		logger.Info("Rate limiting is disabled")
+	}
+
+	switch a.Config.RateLimitStore {
+	case ratelimit.StoreMemory:
+		return ratelimit.NewLimiter(ratelimit.NewMemoryStore(), policies), func() {}, nil
+	case ratelimit.StorePostgres:
+		if len(a.Config.DatabaseDSN) == 0 {
+			return nil, nil, fmt.Errorf("rate limit store %q requires database DSN", a.Config.RateLimitStore)
+		}
+		store, err := ratelimit.NewPostgresStore(context.Background(), a.Config.DatabaseDSN)
+		if err != nil {
+			return nil, nil, err
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			ticker := time.NewTicker(rateLimitCleanupInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if err := store.DeleteIdle(ctx, rateLimitCleanupInterval); err != nil {
+						logger.Warn("Rate limit cleanup failed:", err)
+					}
+				}
+			}
+		}()
+		return ratelimit.NewLimiter(store, policies), func() {
+			cancel()
+			store.Close()
+		}, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown rate limit store %q", a.Config.RateLimitStore)
+	}
+}
+
+// newKeeper - метод создания хранения ответов по ключу идемпотентности.
+// Ответы хранятся в PostgreSQL, если задана строка подключения к БД, иначе - в памяти процесса.
+// Возвращает nil, если время хранения не задано, а также функцию освобождения ресурсов хранилища
+func (a *App) newKeeper() (*idempotency.Keeper, func(), error) {
+	if a.Config.IdempotencyTTL <= 0 {
+		logger.Info("Idempotency keys are disabled")
+		return nil, func() {}, nil
+	}
+	if len(a.Config.DatabaseDSN) == 0 {
+		return idempotency.NewKeeper(idempotency.NewMemoryStore(), a.Config.IdempotencyTTL), func() {}, nil
+	}
+	store, err := idempotency.NewPostgresStore(context.Background(), a.Config.DatabaseDSN)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(idempotencyCleanupInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := store.DeleteExpired(ctx); err != nil {
+					logger.Warn("Idempotency keys cleanup failed:", err)
+				}
+			}
+		}
+	}()
+	return idempotency.NewKeeper(store, a.Config.IdempotencyTTL), func() {
+		cancel()
+		store.Close()
+	}, nil
+}
+
+// newGateway - метод запускает REST шлюз.
+// Шлюз обращается к отдельному экземпляру GRPC сервера через соединение в памяти процесса,
+// поэтому проходит через те же interceptor и не зависит от сетевых настроек GRPC
+func (a *App) newGateway(use *usecase.UsecaseGRPC) (http.Handler, error) {
+	listener := grpcServer.NewPipeListener()
+	a.gatewayGRPC = grpcServer.NewServer(a.Config, use, nil, nil)
+	go func() {
+		if err := a.gatewayGRPC.Serve(listener); err != nil {
+			logger.Error("REST gateway GRPC server error", err.Error())
+		}
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///pipe",
+		grpc.WithContextDialer(listener.DialContext),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect REST gateway: %w", err)
+	}
+	a.gatewayConn = conn
+	return gateway.NewHandler(context.Background(), conn)
+}
+
 // runHTTP - метод запускает http сервер.
-func (a *App) runHTTP(use *usecase.UsecaseHTTP) {
-	a.httpServer = httpServer.NewServer(a.Config, use)
+func (a *App) runHTTP(use *usecase.UsecaseHTTP, gateway http.Handler) {
+	var tlsConfig *tls.Config
+	if a.Config.HTTPSEnabled {
+		var err error
+		tlsConfig, err = helpers.NewServerTLSConfig(a.getCertificate(), a.tlsVersion, "")
+		if err != nil {
+			logger.Error("HTTP TLS configuration failed", err.Error())
+			return
+		}
+	}
+	a.httpServer = httpServer.NewServer(a.Config, use, gateway, a.limiter, a.keeper, a.challenge(), a.reload, tlsConfig)
 	logger.Info("Starting HTTP server on", a.Config.ListenAddr)
 	if err := httpServer.StartServer(a.httpServer, a.Config.HTTPSEnabled); err != nil && err != http.ErrServerClosed {
 		logger.Error("Error listen server", err.Error())
@@ -104,6 +473,11 @@ func (a *App) shutdown() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Перевод GRPC сервисов в состояние NOT_SERVING
+	if a.grpcHealth != nil {
+		a.grpcHealth.Shutdown()
+	}
+
 	// Shutdown для GRPC сервера
 	if a.grpcServer != nil {
 		logger.Info("Stopping GRPC server gracefully...")
@@ -111,9 +485,9 @@ func (a *App) shutdown() {
 		logger.Info("GRPC server stopped")
 	}
 
-	// Закрытие GRPC listener
+	// Закрытие GRPC listener (после GracefulStop он уже закрыт сервером)
 	if a.grpcListener != nil {
-		if err := a.grpcListener.Close(); err != nil {
+		if err := a.grpcListener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
 			logger.Error("GRPC listener close error", err.Error())
 		}
 	}
@@ -127,4 +501,19 @@ func (a *App) shutdown() {
 			logger.Info("HTTP server stopped")
 		}
 	}
+
+	// Shutdown для HTTP сервера проверки владения доменом ACME
+	if a.acmeServer != nil {
+		if err := a.acmeServer.Shutdown(ctx); err != nil {
+			logger.Error("ACME challenge server shutdown error", err.Error())
+		}
+	}
+
+	// Остановка REST шлюза
+	if a.gatewayConn != nil {
+		a.gatewayConn.Close()
+	}
+	if a.gatewayGRPC != nil {
+		a.gatewayGRPC.GracefulStop()
+	}
 }
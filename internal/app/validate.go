@@ -0,0 +1,29 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/denmor86/go-url-shortener/internal/config"
+	"github.com/denmor86/go-url-shortener/internal/network/middleware"
+	"github.com/denmor86/go-url-shortener/internal/usecase"
+)
+
+// ValidateConfig - метод проверки конфигурации приложения: к проверкам config.Validate добавляются
+// параметры, формат которых определяется компонентами приложения. Возвращает *config.ValidationError
+// со всеми найденными ошибками или nil
+func ValidateConfig(cfg *config.Config) error {
+	v := &config.ValidationError{}
+	if err := cfg.Validate(); err != nil && !errors.As(err, &v) {
+		return err
+	}
+	if _, err := middleware.ParseBodyLimits(cfg.BodyLimits); err != nil {
+		v.Add("body_limits", err)
+	}
+	if _, err := middleware.ParseEncodings(cfg.CompressEncodings); err != nil {
+		v.Add("compress_encodings", err)
+	}
+	if _, err := usecase.ParseQuotaOverrides(cfg.QuotaOverrides); err != nil {
+		v.Add("quota_overrides", err)
+	}
+	return v.Err()
+}
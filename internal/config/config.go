@@ -3,7 +3,6 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -34,39 +33,176 @@ type Config struct {
 	DatabaseTimeout time.Duration `env:"DATABASE_TIMEOUT"  json:"database_timeout"`
 	// JWTSecret - секрет для JWT токена
 	JWTSecret string `env:"JWT_SECRET" json:"jwt_secret"`
+	// JWTPreviousSecrets - предыдущие секреты JWT через запятую, принимаются только для проверки токенов (ротация секрета)
+	JWTPreviousSecrets string `env:"JWT_PREVIOUS_SECRETS" json:"jwt_previous_secrets"`
 	// DebugEnable - признак включения отладочного режима (профилирование)
 	DebugEnable bool `json:"enable_debug"`
 	// HTTPSEnabled - признак включения https
 	HTTPSEnabled bool `env:"ENABLE_HTTPS" json:"enable_https"`
+	// TLSCertFile - путь к файлу сертификата TLS (PEM)
+	TLSCertFile string `env:"TLS_CERT_FILE" json:"tls_cert_file"`
+	// TLSKeyFile - путь к файлу приватного ключа TLS (PEM, RSA или ECDSA)
+	TLSKeyFile string `env:"TLS_KEY_FILE" json:"tls_key_file"`
+	// TLSSelfSigned - признак использования самоподписанного сертификата, если файлы сертификата не заданы
+	TLSSelfSigned bool `env:"TLS_SELF_SIGNED" json:"tls_self_signed"`
+	// TLSMinVersion - минимальная версия TLS (1.2, 1.3)
+	TLSMinVersion string `env:"TLS_MIN_VERSION" json:"tls_min_version"`
+	// TLSReloadInterval - период проверки изменения файлов сертификата и ключа (0 - только по SIGHUP)
+	TLSReloadInterval time.Duration `env:"TLS_RELOAD_INTERVAL" json:"tls_reload_interval"`
+	// ACMEEnabled - признак автоматического получения сертификата по протоколу ACME для хоста из BaseURL
+	ACMEEnabled bool `env:"ACME_ENABLED" json:"acme_enabled"`
+	// ACMEEmail - адрес электронной почты для регистрации в удостоверяющем центре
+	ACMEEmail string `env:"ACME_EMAIL" json:"acme_email"`
+	// ACMEDirectoryURL - адрес каталога ACME удостоверяющего центра
+	ACMEDirectoryURL string `env:"ACME_DIRECTORY_URL" json:"acme_directory_url"`
+	// ACMECacheDir - каталог хранения сертификатов ACME (если не задан, используется БД)
+	ACMECacheDir string `env:"ACME_CACHE_DIR" json:"acme_cache_dir"`
+	// ACMEHTTPAddr - адрес HTTP сервера для проверки владения доменом (HTTP-01) и перенаправления на HTTPS
+	ACMEHTTPAddr string `env:"ACME_HTTP_ADDR" json:"acme_http_addr"`
 	// ConfigFilePath - путь к файлу конфигурации
 	ConfigFilePath string `env:"CONFIG" json:"-"`
-	// TrustedSubnet - доверенная подсеть
+	// CheckConfig - признак проверки конфигурации без запуска серверов
+	CheckConfig bool `json:"-"`
+	// PrintConfig - признак вывода действующей конфигурации с источниками значений без запуска серверов
+	PrintConfig bool `json:"-"`
+	// TrustedSubnet - доверенные подсети через запятую (доступ к статистике)
 	TrustedSubnet string `env:"TRUSTED_SUBNET" json:"trusted_subnet"`
+	// TrustedMethods - GRPC методы, доступные только из доверенных подсетей, через запятую
+	TrustedMethods string `env:"TRUSTED_METHODS" json:"trusted_methods"`
+	// TrustedClients - клиенты GRPC (субъект или имя владельца сертификата mTLS) через точку с запятой,
+	// которым доступны защищаемые методы вне доверенных подсетей
+	TrustedClients string `env:"TRUSTED_CLIENTS" json:"trusted_clients"`
+	// TrustedProxies - подсети доверенных прокси через запятую (учитываются заголовки Forwarded, X-Forwarded-For, X-Real-IP)
+	TrustedProxies string `env:"TRUSTED_PROXIES" json:"trusted_proxies"`
+	// GRPCClientCAFile - путь к файлу с сертификатами CA для проверки клиентских сертификатов GRPC (mTLS)
+	GRPCClientCAFile string `env:"GRPC_CLIENT_CA_FILE" json:"grpc_client_ca_file"`
+	// GRPCMaxRecvMsgSize - максимальный размер принимаемого GRPC сообщения, байт
+	GRPCMaxRecvMsgSize int `env:"GRPC_MAX_RECV_MSG_SIZE" json:"grpc_max_recv_msg_size"`
+	// GRPCMaxSendMsgSize - максимальный размер отправляемого GRPC сообщения, байт
+	GRPCMaxSendMsgSize int `env:"GRPC_MAX_SEND_MSG_SIZE" json:"grpc_max_send_msg_size"`
+	// GRPCMaxConcurrentStreams - максимальное количество одновременных потоков на соединение
+	GRPCMaxConcurrentStreams uint32 `env:"GRPC_MAX_CONCURRENT_STREAMS" json:"grpc_max_concurrent_streams"`
+	// GRPCKeepaliveTime - период проверки активности соединения сервером
+	GRPCKeepaliveTime time.Duration `env:"GRPC_KEEPALIVE_TIME" json:"grpc_keepalive_time"`
+	// GRPCKeepaliveTimeout - время ожидания ответа на проверку активности соединения
+	GRPCKeepaliveTimeout time.Duration `env:"GRPC_KEEPALIVE_TIMEOUT" json:"grpc_keepalive_timeout"`
+	// GRPCKeepaliveMinTime - минимально допустимый период проверки активности соединения клиентом
+	GRPCKeepaliveMinTime time.Duration `env:"GRPC_KEEPALIVE_MIN_TIME" json:"grpc_keepalive_min_time"`
+	// TracingExporter - экспортер трассировки (none, stdout, otlp)
+	TracingExporter string `env:"TRACING_EXPORTER" json:"tracing_exporter"`
+	// TracingEndpoint - адрес OTLP коллектора (host:port, GRPC)
+	TracingEndpoint string `env:"TRACING_ENDPOINT" json:"tracing_endpoint"`
+	// TracingInsecure - признак подключения к OTLP коллектору без TLS
+	TracingInsecure bool `env:"TRACING_INSECURE" json:"tracing_insecure"`
+	// TracingSampleRatio - доля трассируемых запросов (0..1), если решение не принято вызывающей стороной
+	TracingSampleRatio float64 `env:"TRACING_SAMPLE_RATIO" json:"tracing_sample_ratio"`
+	// RateLimits - политики ограничения частоты запросов по группам ("группа=скорость:емкость,...", пусто - отключено)
+	RateLimits string `env:"RATE_LIMITS" json:"rate_limits"`
+	// RateLimitStore - хранилище состояния ограничителей (memory, postgres)
+	RateLimitStore string `env:"RATE_LIMIT_STORE" json:"rate_limit_store"`
+	// RateLimitAPIKeys - ключи API клиентов через запятую, ограничиваемых отдельно от IP адреса (X-API-Key)
+	RateLimitAPIKeys string `env:"RATE_LIMIT_API_KEYS" json:"rate_limit_api_keys"`
+	// QuotaDaily - количество ссылок, которое пользователь может создать за сутки (0 - без ограничения)
+	QuotaDaily int `env:"QUOTA_DAILY" json:"quota_daily"`
+	// QuotaTotal - общее количество ссылок, которое может создать пользователь (0 - без ограничения)
+	QuotaTotal int `env:"QUOTA_TOTAL" json:"quota_total"`
+	// QuotaOverrides - индивидуальные квоты пользователей ("UUID=суточная:общая,...")
+	QuotaOverrides string `env:"QUOTA_OVERRIDES" json:"quota_overrides"`
+	// IdempotencyTTL - время хранения ответов по ключу идемпотентности (0 - ключи не поддерживаются)
+	IdempotencyTTL time.Duration `env:"IDEMPOTENCY_TTL" json:"idempotency_ttl"`
+	// BodyLimits - максимальный размер тела запроса по группам маршрутов ("группа=байт,...", пусто - без ограничения)
+	BodyLimits string `env:"BODY_LIMITS" json:"body_limits"`
+	// MaxBatchSize - максимальное количество ссылок в пакетном запросе (0 - без ограничения)
+	MaxBatchSize int `env:"MAX_BATCH_SIZE" json:"max_batch_size"`
+	// CompressEncodings - алгоритмы сжатия ответов в порядке предпочтения через запятую (пусто - без сжатия)
+	CompressEncodings string `env:"COMPRESS_ENCODINGS" json:"compress_encodings"`
+	// CompressMinSize - минимальный размер сжимаемого ответа, байт
+	CompressMinSize int `env:"COMPRESS_MIN_SIZE" json:"compress_min_size"`
+	// ReadHeaderTimeout - время чтения заголовков HTTP запроса
+	ReadHeaderTimeout time.Duration `env:"READ_HEADER_TIMEOUT" json:"read_header_timeout"`
+	// ReadTimeout - время чтения HTTP запроса целиком
+	ReadTimeout time.Duration `env:"READ_TIMEOUT" json:"read_timeout"`
+	// WriteTimeout - время обработки HTTP запроса и записи ответа
+	WriteTimeout time.Duration `env:"WRITE_TIMEOUT" json:"write_timeout"`
+	// IdleTimeout - время ожидания следующего запроса в keep-alive соединении
+	IdleTimeout time.Duration `env:"IDLE_TIMEOUT" json:"idle_timeout"`
+
+	// reload - состояние перечитывания конфигурации (подписчики и примененные значения)
+	reload *reloadState
+	// sources - источники значений параметров (по имени в файле конфигурации)
+	sources map[string]Source
 }
 
 // Настройки по-умолчанию
 const (
-	DefaultListenServer    = "localhost:8080"
-	DefaultGRPCAddr        = ":8081"
-	DefaultBaseURL         = "http://" + DefaultListenServer
-	DefaultShortURLlen     = 8
-	DefaultLogLevel        = "info"
-	DefaultCacheFileName   = "shortener_cache.txt"
-	DefaultDatabaseDSN     = ""
-	DefaultDatabaseTimeout = time.Duration(5)
-	DefaultJWTSecret       = "secret"
-	DefaultDebugEnabled    = false
-	DefaultHTTPSEnabled    = false
-	DefaultConfigFilePath  = ""
-	DefaultTrustedSubnet   = ""
+	DefaultListenServer       = "localhost:8080"
+	DefaultGRPCAddr           = ":8081"
+	DefaultBaseURL            = "http://" + DefaultListenServer
+	DefaultShortURLlen        = 8
+	DefaultLogLevel           = "info"
+	DefaultCacheFileName      = "shortener_cache.txt"
+	DefaultDatabaseDSN        = ""
+	DefaultDatabaseTimeout    = 5 * time.Second
+	DefaultJWTSecret          = "secret"
+	DefaultJWTPreviousSecrets = ""
+	DefaultDebugEnabled       = false
+	DefaultHTTPSEnabled       = false
+	DefaultTLSCertFile        = ""
+	DefaultTLSKeyFile         = ""
+	DefaultTLSSelfSigned      = false
+	DefaultTLSMinVersion      = "1.2"
+	DefaultTLSReloadInterval  = 10 * time.Second
+	DefaultACMEEnabled        = false
+	DefaultACMEEmail          = ""
+	DefaultACMEDirectoryURL   = "https://acme-v02.api.letsencrypt.org/directory"
+	DefaultACMECacheDir       = ""
+	DefaultACMEHTTPAddr       = ":80"
+	DefaultConfigFilePath     = ""
+	DefaultTrustedSubnet      = ""
+	DefaultTrustedMethods     = "GetStatistic"
+	DefaultTrustedClients     = ""
+	DefaultTrustedProxies     = ""
+
+	DefaultGRPCClientCAFile         = ""
+	DefaultGRPCMaxRecvMsgSize       = 4 * 1024 * 1024
+	DefaultGRPCMaxSendMsgSize       = 4 * 1024 * 1024
+	DefaultGRPCMaxConcurrentStreams = 100
+	DefaultGRPCKeepaliveTime        = time.Minute
+	DefaultGRPCKeepaliveTimeout     = 20 * time.Second
+	DefaultGRPCKeepaliveMinTime     = 10 * time.Second
+
+	DefaultTracingExporter    = "none"
+	DefaultTracingEndpoint    = "localhost:4317"
+	DefaultTracingInsecure    = false
+	DefaultTracingSampleRatio = 1.0
+
+	DefaultRateLimits       = ""
+	DefaultRateLimitStore   = "memory"
+	DefaultRateLimitAPIKeys = ""
+
+	DefaultQuotaDaily     = 0
+	DefaultQuotaTotal     = 0
+	DefaultQuotaOverrides = ""
+
+	DefaultIdempotencyTTL = 24 * time.Hour
+
+	DefaultBodyLimits        = "shorten=65536,batch=1048576,user=1048576"
+	DefaultMaxBatchSize      = 1000
+	DefaultCompressEncodings = "gzip,deflate"
+	DefaultCompressMinSize   = 1024
+	DefaultReadHeaderTimeout = 5 * time.Second
+	DefaultReadTimeout       = 30 * time.Second
+	DefaultWriteTimeout      = 30 * time.Second
+	DefaultIdleTimeout       = 2 * time.Minute
 )
 
-func (cfg *Config) parseFromEnv() {
-	if err := env.Parse(cfg); err != nil {
-		panic(fmt.Sprintf("Failed to parse enviroment var: %s", err.Error()))
-	}
+// loadFromEnv - метод чтения параметров из переменных окружения
+func (cfg *Config) loadFromEnv() error {
+	return env.Parse(cfg)
 }
-func (cfg *Config) parseFromFlags() {
+
+// parseFromFlags - метод чтения флагов запуска, возвращает имена полей, заданных флагами явно
+func (cfg *Config) parseFromFlags() map[string]struct{} {
 
 	pflag.StringVarP(&cfg.ListenAddr, "server", "a", DefaultListenServer, "Server listen address in a form host:port.")
 	pflag.StringVarP(&cfg.GRPCAddr, "grpc", "g", DefaultGRPCAddr, "Server GRPC address as host:port")
@@ -75,110 +211,160 @@ func (cfg *Config) parseFromFlags() {
 	pflag.StringVar(&cfg.LogLevel, "log_level", DefaultLogLevel, "Log level.")
 	pflag.StringVarP(&cfg.FileStoragePath, "file_storage_path", "f", filepath.Join(os.TempDir(), DefaultCacheFileName), "Path to cache file.")
 	pflag.StringVarP(&cfg.DatabaseDSN, "db_dsn", "d", DefaultDatabaseDSN, "Database DSN")
-	pflag.DurationVar(&cfg.DatabaseTimeout, "db_timeout", DefaultDatabaseTimeout, "Database timeout connection, seconds.")
+	pflag.DurationVar(&cfg.DatabaseTimeout, "db_timeout", DefaultDatabaseTimeout, "Database timeout connection.")
 	pflag.StringVar(&cfg.JWTSecret, "jwt_secret", DefaultJWTSecret, "Secret to JWT")
+	pflag.StringVar(&cfg.JWTPreviousSecrets, "jwt_previous_secrets", DefaultJWTPreviousSecrets, "Previous JWT secrets accepted for verification, comma separated")
 	pflag.BoolVar(&cfg.DebugEnable, "debug", DefaultDebugEnabled, "Debug mode")
 	pflag.BoolVarP(&cfg.HTTPSEnabled, "https", "s", DefaultHTTPSEnabled, "Enable https")
+	pflag.StringVar(&cfg.TLSCertFile, "tls_cert", DefaultTLSCertFile, "Path to TLS certificate file (PEM)")
+	pflag.StringVar(&cfg.TLSKeyFile, "tls_key", DefaultTLSKeyFile, "Path to TLS private key file (PEM)")
+	pflag.BoolVar(&cfg.TLSSelfSigned, "tls_self_signed", DefaultTLSSelfSigned, "Use self-signed certificate when TLS certificate files are not set")
+	pflag.StringVar(&cfg.TLSMinVersion, "tls_min_version", DefaultTLSMinVersion, "Minimum TLS version (1.2, 1.3)")
+	pflag.DurationVar(&cfg.TLSReloadInterval, "tls_reload_interval", DefaultTLSReloadInterval, "Interval to check TLS certificate files for changes (0 - reload on SIGHUP only)")
+	pflag.BoolVar(&cfg.ACMEEnabled, "acme", DefaultACMEEnabled, "Obtain TLS certificate for BaseURL host via ACME")
+	pflag.StringVar(&cfg.ACMEEmail, "acme_email", DefaultACMEEmail, "ACME account contact email")
+	pflag.StringVar(&cfg.ACMEDirectoryURL, "acme_directory_url", DefaultACMEDirectoryURL, "ACME directory URL")
+	pflag.StringVar(&cfg.ACMECacheDir, "acme_cache_dir", DefaultACMECacheDir, "Directory to cache ACME certificates (database is used if empty)")
+	pflag.StringVar(&cfg.ACMEHTTPAddr, "acme_http_addr", DefaultACMEHTTPAddr, "HTTP address for ACME HTTP-01 challenge and redirect to HTTPS (empty - disabled)")
 	pflag.StringVarP(&cfg.ConfigFilePath, "config", "c", DefaultConfigFilePath, "Path to config file.")
-	pflag.StringVarP(&cfg.TrustedSubnet, "trusted_subnet", "t", DefaultTrustedSubnet, "Trusted subnet")
+	pflag.BoolVar(&cfg.CheckConfig, "check-config", false, "Validate configuration and exit without starting servers")
+	pflag.BoolVar(&cfg.PrintConfig, "print-config", false, "Print effective configuration with value sources and exit")
+	pflag.StringVarP(&cfg.TrustedSubnet, "trusted_subnet", "t", DefaultTrustedSubnet, "Trusted subnets in CIDR notation, comma separated")
+	pflag.StringVar(&cfg.TrustedMethods, "trusted_methods", DefaultTrustedMethods, "GRPC methods available from trusted subnets only, comma separated (GetStatistic or /shortener.Shortener/GetStatistic)")
+	pflag.StringVar(&cfg.TrustedClients, "trusted_clients", DefaultTrustedClients, "GRPC client certificate subjects (CN=name,O=org) or common names allowed to call trusted methods, separated by ';'")
+	pflag.StringVar(&cfg.TrustedProxies, "trusted_proxies", DefaultTrustedProxies, "Trusted proxy subnets in CIDR notation, comma separated")
+	pflag.StringVar(&cfg.GRPCClientCAFile, "grpc_client_ca", DefaultGRPCClientCAFile, "Path to CA bundle to verify GRPC client certificates (mTLS)")
+	pflag.IntVar(&cfg.GRPCMaxRecvMsgSize, "grpc_max_recv_msg_size", DefaultGRPCMaxRecvMsgSize, "GRPC max receive message size, bytes")
+	pflag.IntVar(&cfg.GRPCMaxSendMsgSize, "grpc_max_send_msg_size", DefaultGRPCMaxSendMsgSize, "GRPC max send message size, bytes")
+	pflag.Uint32Var(&cfg.GRPCMaxConcurrentStreams, "grpc_max_concurrent_streams", DefaultGRPCMaxConcurrentStreams, "GRPC max concurrent streams per connection")
+	pflag.DurationVar(&cfg.GRPCKeepaliveTime, "grpc_keepalive_time", DefaultGRPCKeepaliveTime, "GRPC keepalive ping period")
+	pflag.DurationVar(&cfg.GRPCKeepaliveTimeout, "grpc_keepalive_timeout", DefaultGRPCKeepaliveTimeout, "GRPC keepalive ping timeout")
+	pflag.DurationVar(&cfg.GRPCKeepaliveMinTime, "grpc_keepalive_min_time", DefaultGRPCKeepaliveMinTime, "GRPC minimum client keepalive ping period")
+	pflag.StringVar(&cfg.TracingExporter, "tracing_exporter", DefaultTracingExporter, "Tracing exporter: none, stdout or otlp")
+	pflag.StringVar(&cfg.TracingEndpoint, "tracing_endpoint", DefaultTracingEndpoint, "OTLP collector address as host:port")
+	pflag.BoolVar(&cfg.TracingInsecure, "tracing_insecure", DefaultTracingInsecure, "Connect to OTLP collector without TLS")
+	pflag.Float64Var(&cfg.TracingSampleRatio, "tracing_sample_ratio", DefaultTracingSampleRatio, "Ratio of sampled root traces, 0..1")
+	pflag.StringVar(&cfg.RateLimits, "rate_limits", DefaultRateLimits, "Rate limit policies as group=rate:burst,... (groups: shorten, batch, redirect, user), empty - disabled")
+	pflag.StringVar(&cfg.RateLimitStore, "rate_limit_store", DefaultRateLimitStore, "Rate limit state store: memory or postgres")
+	pflag.StringVar(&cfg.RateLimitAPIKeys, "rate_limit_api_keys", DefaultRateLimitAPIKeys, "API keys (X-API-Key) of clients limited separately from IP address, comma separated")
+	pflag.IntVar(&cfg.QuotaDaily, "quota_daily", DefaultQuotaDaily, "Links per user per day (UTC), 0 - unlimited")
+	pflag.IntVar(&cfg.QuotaTotal, "quota_total", DefaultQuotaTotal, "Total links per user, 0 - unlimited")
+	pflag.StringVar(&cfg.QuotaOverrides, "quota_overrides", DefaultQuotaOverrides, "Per-user quotas as UUID=daily:total,...")
+	pflag.DurationVar(&cfg.IdempotencyTTL, "idempotency_ttl", DefaultIdempotencyTTL, "How long responses are kept for Idempotency-Key replay, 0 - disabled")
+	pflag.StringVar(&cfg.BodyLimits, "body_limits", DefaultBodyLimits, "Max request body size as group=bytes,... (groups: shorten, batch, user)")
+	pflag.IntVar(&cfg.MaxBatchSize, "max_batch_size", DefaultMaxBatchSize, "Max URLs in a batch request, 0 - unlimited")
+	pflag.StringVar(&cfg.CompressEncodings, "compress_encodings", DefaultCompressEncodings, "Response encodings in order of preference, comma separated (gzip, deflate)")
+	pflag.IntVar(&cfg.CompressMinSize, "compress_min_size", DefaultCompressMinSize, "Min response size to compress, bytes")
+	pflag.DurationVar(&cfg.ReadHeaderTimeout, "read_header_timeout", DefaultReadHeaderTimeout, "HTTP request headers read timeout")
+	pflag.DurationVar(&cfg.ReadTimeout, "read_timeout", DefaultReadTimeout, "HTTP request read timeout")
+	pflag.DurationVar(&cfg.WriteTimeout, "write_timeout", DefaultWriteTimeout, "HTTP response write timeout")
+	pflag.DurationVar(&cfg.IdleTimeout, "idle_timeout", DefaultIdleTimeout, "HTTP keep-alive idle timeout")
 
 	pflag.Parse()
+
+	return flagFields(cfg, pflag.CommandLine)
 }
 
-func (cfg *Config) parseFromFile() {
+// loadFromFile - метод чтения параметров из файла конфигурации (формат JSON, YAML или TOML определяется
+// по расширению): устанавливаются только параметры, заданные в файле, их источником становится файл
+func (cfg *Config) loadFromFile() error {
 	if len(cfg.ConfigFilePath) == 0 {
-		return
+		return nil
 	}
 	buf, err := os.ReadFile(cfg.ConfigFilePath)
 	if err != nil {
-		panic(fmt.Sprintf("can't load config file: %s ", errors.Cause(err).Error()))
-	}
-	tmp := NewDefaultConfig()
-	if err = json.Unmarshal(buf, tmp); err != nil {
-		panic(fmt.Sprintf("can't parse config file: %s ", errors.Cause(err).Error()))
-	}
-	// Определение адреса сервера
-	if cfg.ListenAddr == DefaultListenServer {
-		cfg.ListenAddr = tmp.ListenAddr
-	}
-	// Определение GRPC адреса сервера
-	if cfg.GRPCAddr == DefaultGRPCAddr {
-		cfg.GRPCAddr = tmp.GRPCAddr
-	}
-	// Определение базового URL
-	if cfg.BaseURL == DefaultBaseURL {
-		cfg.BaseURL = tmp.BaseURL
-	}
-	// Определение максимальной длинны сокращаемых URLs
-	if cfg.ShortURLLen == DefaultShortURLlen {
-		cfg.ShortURLLen = tmp.ShortURLLen
+		return fmt.Errorf("can't load config file: %s ", errors.Cause(err).Error())
 	}
-	// Определение уровня логирования
-	if cfg.LogLevel == DefaultLogLevel {
-		cfg.LogLevel = tmp.LogLevel
-	}
-	// Определение пути к файлу с текстовым кэшем
-	if cfg.FileStoragePath == DefaultCacheFileName {
-		cfg.FileStoragePath = tmp.FileStoragePath
-	}
-	// Определение DSN для подключения к БД
-	if cfg.DatabaseDSN == DefaultDatabaseDSN {
-		cfg.DatabaseDSN = tmp.DatabaseDSN
-	}
-	// Определение таймаута работы с БД
-	if cfg.DatabaseTimeout == DefaultDatabaseTimeout {
-		cfg.DatabaseTimeout = tmp.DatabaseTimeout
-	}
-	// Определение секрета JWT токена
-	if cfg.JWTSecret == DefaultJWTSecret {
-		cfg.JWTSecret = tmp.JWTSecret
-	}
-	// Определение признака работы в debug (profiler)
-	if !cfg.DebugEnable {
-		cfg.DebugEnable = tmp.DebugEnable
+	values, err := decodeFile(cfg.ConfigFilePath, buf)
+	if err != nil {
+		return fmt.Errorf("can't parse config file: %s ", errors.Cause(err).Error())
 	}
-	// Определение признака работы по HTTPS
-	if !cfg.HTTPSEnabled {
-		cfg.HTTPSEnabled = tmp.HTTPSEnabled
+	if err = cfg.setValues(values); err != nil {
+		return fmt.Errorf("can't parse config file: %s ", errors.Cause(err).Error())
 	}
-	// Определение доверенной подсети
-	if cfg.TrustedSubnet == DefaultTrustedSubnet {
-		cfg.TrustedSubnet = tmp.TrustedSubnet
+	for name := range values {
+		cfg.setSource(name, SourceFile)
 	}
+	return nil
 }
 
 // NewConfig - метод формирования конфигурации приложения. Используются переменные окружения и флаги запуска приложения.
-func NewConfig() *Config {
-
-	// инициализируемся по дефолту
-	config := NewDefaultConfig()
+// Возвращает ошибку чтения переменных окружения или файла конфигурации, проверка значений выполняется методом Validate
+func NewConfig() (*Config, error) {
 
 	// Устанавливаем из флагов
-	config.parseFromFlags()
+	flags := NewDefaultConfig()
+	fields := flags.parseFromFlags()
 
-	// Перезаписываем параметры, которые есть в окружении
-	config.parseFromEnv()
+	// Запоминаем параметры из флагов для перечитывания конфигурации
+	commandLine, commandLineFields = flags, fields
 
-	// Устанавливаем файла те параметры, которые не установлены ранее
-	config.parseFromFile()
-
-	return config
+	// Накладываем источники: значения по-умолчанию, файл конфигурации, флаги, переменные окружения
+	return load(commandLine, commandLineFields)
 }
 
 // NewDefaultConfig - метод формирования конфигурации по-умолчанию
 func NewDefaultConfig() *Config {
 	return &Config{
-		ListenAddr:      DefaultListenServer,
-		GRPCAddr:        DefaultGRPCAddr,
-		BaseURL:         DefaultBaseURL,
-		ShortURLLen:     DefaultShortURLlen,
-		LogLevel:        DefaultLogLevel,
-		FileStoragePath: filepath.Join(os.TempDir(), DefaultCacheFileName),
-		DatabaseDSN:     DefaultDatabaseDSN,
-		JWTSecret:       DefaultJWTSecret,
-		DebugEnable:     DefaultDebugEnabled,
-		HTTPSEnabled:    DefaultHTTPSEnabled,
-		ConfigFilePath:  DefaultConfigFilePath,
-		TrustedSubnet:   DefaultTrustedSubnet,
+		reload:             &reloadState{},
+		ListenAddr:         DefaultListenServer,
+		GRPCAddr:           DefaultGRPCAddr,
+		BaseURL:            DefaultBaseURL,
+		ShortURLLen:        DefaultShortURLlen,
+		LogLevel:           DefaultLogLevel,
+		FileStoragePath:    filepath.Join(os.TempDir(), DefaultCacheFileName),
+		DatabaseDSN:        DefaultDatabaseDSN,
+		DatabaseTimeout:    DefaultDatabaseTimeout,
+		JWTSecret:          DefaultJWTSecret,
+		JWTPreviousSecrets: DefaultJWTPreviousSecrets,
+		DebugEnable:        DefaultDebugEnabled,
+		HTTPSEnabled:       DefaultHTTPSEnabled,
+		TLSCertFile:        DefaultTLSCertFile,
+		TLSKeyFile:         DefaultTLSKeyFile,
+		TLSSelfSigned:      DefaultTLSSelfSigned,
+		TLSMinVersion:      DefaultTLSMinVersion,
+		TLSReloadInterval:  DefaultTLSReloadInterval,
+		ACMEEnabled:        DefaultACMEEnabled,
+		ACMEEmail:          DefaultACMEEmail,
+		ACMEDirectoryURL:   DefaultACMEDirectoryURL,
+		ACMECacheDir:       DefaultACMECacheDir,
+		ACMEHTTPAddr:       DefaultACMEHTTPAddr,
+		ConfigFilePath:     DefaultConfigFilePath,
+		TrustedSubnet:      DefaultTrustedSubnet,
+		TrustedMethods:     DefaultTrustedMethods,
+		TrustedClients:     DefaultTrustedClients,
+		TrustedProxies:     DefaultTrustedProxies,
+
+		GRPCClientCAFile:         DefaultGRPCClientCAFile,
+		GRPCMaxRecvMsgSize:       DefaultGRPCMaxRecvMsgSize,
+		GRPCMaxSendMsgSize:       DefaultGRPCMaxSendMsgSize,
+		GRPCMaxConcurrentStreams: DefaultGRPCMaxConcurrentStreams,
+		GRPCKeepaliveTime:        DefaultGRPCKeepaliveTime,
+		GRPCKeepaliveTimeout:     DefaultGRPCKeepaliveTimeout,
+		GRPCKeepaliveMinTime:     DefaultGRPCKeepaliveMinTime,
+
+		TracingExporter:    DefaultTracingExporter,
+		TracingEndpoint:    DefaultTracingEndpoint,
+		TracingInsecure:    DefaultTracingInsecure,
+		TracingSampleRatio: DefaultTracingSampleRatio,
+
+		RateLimits:       DefaultRateLimits,
+		RateLimitStore:   DefaultRateLimitStore,
+		RateLimitAPIKeys: DefaultRateLimitAPIKeys,
+
+		QuotaDaily:     DefaultQuotaDaily,
+		QuotaTotal:     DefaultQuotaTotal,
+		QuotaOverrides: DefaultQuotaOverrides,
+
+		IdempotencyTTL: DefaultIdempotencyTTL,
+
+		BodyLimits:        DefaultBodyLimits,
+		MaxBatchSize:      DefaultMaxBatchSize,
+		CompressEncodings: DefaultCompressEncodings,
+		CompressMinSize:   DefaultCompressMinSize,
+		ReadHeaderTimeout: DefaultReadHeaderTimeout,
+		ReadTimeout:       DefaultReadTimeout,
+		WriteTimeout:      DefaultWriteTimeout,
+		IdleTimeout:       DefaultIdleTimeout,
 	}
 }
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// envFileSuffix - суффикс переменной окружения с путем к файлу, содержащему значение параметра (например, JWT_SECRET_FILE)
+const envFileSuffix = "_FILE"
+
+// decodeFile - метод разбора файла конфигурации в зависимости от расширения: .yaml, .yml - YAML, .toml - TOML, иначе JSON
+func decodeFile(path string, buf []byte) (map[string]any, error) {
+	values := make(map[string]any)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(buf, &values); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+	case ".toml":
+		if err := toml.Unmarshal(buf, &values); err != nil {
+			return nil, fmt.Errorf("invalid TOML: %w", err)
+		}
+	default:
+		decoder := json.NewDecoder(bytes.NewReader(buf))
+		decoder.UseNumber()
+		if err := decoder.Decode(&values); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	}
+	return values, nil
+}
+
+// setValues - метод установки параметров по именам в файле конфигурации. Длительности задаются
+// строкой ("5s", "1h30m") или числом наносекунд
+func (cfg *Config) setValues(values map[string]any) error {
+	value := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		name := fieldName(value.Type().Field(i))
+		s, ok := values[name].(string)
+		if !ok || value.Field(i).Type() != reflect.TypeOf(time.Duration(0)) {
+			continue
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %s: %w", name, err)
+		}
+		values[name] = d
+	}
+	buf, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(buf, cfg)
+}
+
+// loadFromEnvFiles - метод чтения строковых параметров из файлов, пути к которым заданы переменными
+// окружения с суффиксом _FILE (секреты в смонтированных файлах). Завершающий перевод строки отбрасывается,
+// одновременное задание переменной и ее варианта _FILE считается ошибкой
+func (cfg *Config) loadFromEnvFiles() error {
+	value := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("env"), ",")
+		name := fieldName(field)
+		if len(key) == 0 || len(name) == 0 || field.Type.Kind() != reflect.String {
+			continue
+		}
+		path := os.Getenv(key + envFileSuffix)
+		if len(path) == 0 {
+			continue
+		}
+		if len(os.Getenv(key)) != 0 {
+			return fmt.Errorf("both %s and %s are set", key, key+envFileSuffix)
+		}
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("can't read %s: %w", key+envFileSuffix, err)
+		}
+		value.Field(i).SetString(strings.TrimRight(string(buf), "\r\n"))
+		cfg.setSource(name, SourceEnvFile)
+	}
+	return nil
+}
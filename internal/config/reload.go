@@ -0,0 +1,109 @@
+package config
+
+import (
+	"maps"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// reloadableFields - параметры (имена в файле конфигурации), изменяемые без перезапуска приложения
+var reloadableFields = map[string]struct{}{
+	"log_level":            {},
+	"trusted_subnet":       {},
+	"trusted_methods":      {},
+	"trusted_clients":      {},
+	"rate_limits":          {},
+	"rate_limit_api_keys":  {},
+	"jwt_secret":           {},
+	"jwt_previous_secrets": {},
+}
+
+// Параметры, полученные из флагов запуска (основа для перечитывания конфигурации)
+var (
+	commandLine       *Config             // значения флагов
+	commandLineFields map[string]struct{} // имена полей, заданных флагами явно
+)
+
+// ReloadResult - результат перечитывания конфигурации
+type ReloadResult struct {
+	Applied         []string `json:"applied"`          // измененные параметры, примененные без перезапуска
+	RestartRequired []string `json:"restart_required"` // измененные параметры, требующие перезапуска
+}
+
+// reloadState - состояние перечитывания конфигурации
+type reloadState struct {
+	mu          sync.Mutex
+	current     *Config         // примененная конфигурация (nil - конфигурация запуска)
+	subscribers []func(*Config) // обработчики применения новых значений
+}
+
+// Reload - метод повторного чтения конфигурации: файл конфигурации и переменные окружения читаются заново,
+// флаги запуска сохраняются, как и при запуске приложения
+func Reload() (*Config, error) {
+	return load(commandLine, commandLineFields)
+}
+
+// OnReload - метод подписки на применение перечитанной конфигурации. Обработчик получает конфигурацию
+// с новыми значениями параметров, изменяемых без перезапуска, и должен применять их потокобезопасно
+func (cfg *Config) OnReload(apply func(*Config)) {
+	if cfg.reload == nil {
+		return
+	}
+	cfg.reload.mu.Lock()
+	defer cfg.reload.mu.Unlock()
+	cfg.reload.subscribers = append(cfg.reload.subscribers, apply)
+}
+
+// Apply - метод применения перечитанной конфигурации next. Параметры, изменяемые без перезапуска,
+// передаются подписчикам (OnReload); поля самой конфигурации не изменяются.
+// Возвращает измененные параметры: примененные и требующие перезапуска
+func (cfg *Config) Apply(next *Config) ReloadResult {
+	var result ReloadResult
+	if cfg.reload == nil {
+		return result
+	}
+	cfg.reload.mu.Lock()
+	defer cfg.reload.mu.Unlock()
+	if cfg.reload.current == nil {
+		current := *cfg
+		current.sources = maps.Clone(cfg.sources)
+		cfg.reload.current = &current
+	}
+
+	current := reflect.ValueOf(cfg.reload.current).Elem()
+	updated := reflect.ValueOf(next).Elem()
+	for i := 0; i < current.NumField(); i++ {
+		name := fieldName(current.Type().Field(i))
+		if len(name) == 0 || reflect.DeepEqual(current.Field(i).Interface(), updated.Field(i).Interface()) {
+			continue
+		}
+		if _, ok := reloadableFields[name]; !ok {
+			result.RestartRequired = append(result.RestartRequired, name)
+			continue
+		}
+		current.Field(i).Set(updated.Field(i))
+		cfg.reload.current.setSource(name, next.Source(name))
+		result.Applied = append(result.Applied, name)
+	}
+
+	for _, apply := range cfg.reload.subscribers {
+		apply(cfg.reload.current)
+	}
+	return result
+}
+
+// fieldName - метод получения имени параметра в файле конфигурации (пустая строка - параметр не сравнивается)
+func fieldName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if len(name) == 0 {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Apply(t *testing.T) {
+	cfg := NewDefaultConfig()
+	var applied []*Config
+	cfg.OnReload(func(c *Config) { applied = append(applied, c) })
+
+	next := NewDefaultConfig()
+	next.LogLevel = "debug"
+	next.RateLimits = "shorten=1:1"
+	next.ListenAddr = ":9090"
+
+	result := cfg.Apply(next)
+	assert.Equal(t, []string{"log_level", "rate_limits"}, result.Applied)
+	assert.Equal(t, []string{"server_address"}, result.RestartRequired)
+	require.Len(t, applied, 1)
+	assert.Equal(t, "debug", applied[0].LogLevel)
+	assert.Equal(t, "shorten=1:1", applied[0].RateLimits)
+	// параметры, требующие перезапуска, не применяются
+	assert.Equal(t, DefaultListenServer, applied[0].ListenAddr)
+	// сама конфигурация не изменяется
+	assert.Equal(t, DefaultLogLevel, cfg.LogLevel)
+
+	// повторное применение: примененные параметры не изменены, перезапуск по-прежнему требуется
+	result = cfg.Apply(next)
+	assert.Empty(t, result.Applied)
+	assert.Equal(t, []string{"server_address"}, result.RestartRequired)
+	require.Len(t, applied, 2)
+}
+
+func TestReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"log_level":"warn","trusted_subnet":"10.0.0.0/8","jwt_secret":"file"}`), 0600))
+
+	saved, savedFields := commandLine, commandLineFields
+	t.Cleanup(func() { commandLine, commandLineFields = saved, savedFields })
+	commandLine = NewDefaultConfig()
+	commandLine.ConfigFilePath = file
+	commandLine.TrustedSubnet = "192.168.0.0/16"
+	commandLineFields = map[string]struct{}{"ConfigFilePath": {}, "TrustedSubnet": {}}
+
+	t.Run("Flags, env and file #1 (good)", func(t *testing.T) {
+		t.Setenv("JWT_SECRET", "env")
+		cfg, err := Reload()
+		require.NoError(t, err)
+		assert.Equal(t, "warn", cfg.LogLevel)
+		assert.Equal(t, "192.168.0.0/16", cfg.TrustedSubnet)
+		assert.Equal(t, "env", cfg.JWTSecret)
+	})
+
+	t.Run("Invalid file #2 (bad)", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(file, []byte(`{"log_level":`), 0600))
+		_, err := Reload()
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+)
+
+// Source - источник значения параметра конфигурации
+type Source string
+
+// Источники значений в порядке возрастания приоритета
+const (
+	SourceDefault Source = "default"  // значение по-умолчанию
+	SourceFile    Source = "file"     // файл конфигурации
+	SourceFlag    Source = "flag"     // флаг запуска
+	SourceEnv     Source = "env"      // переменная окружения
+	SourceEnvFile Source = "env_file" // файл, путь к которому задан переменной окружения *_FILE (приоритет как у переменной)
+)
+
+// Setting - действующее значение параметра конфигурации
+type Setting struct {
+	Name   string `json:"name"`   // имя параметра в файле конфигурации
+	Value  any    `json:"value"`  // значение (секреты скрыты)
+	Source Source `json:"source"` // источник значения
+}
+
+// redactedValue - замена значения секрета при выводе конфигурации (как в url.URL.Redacted)
+const redactedValue = "xxxxx"
+
+// secretFields - параметры, значения которых скрываются при выводе конфигурации
+var secretFields = map[string]func(string) string{
+	"jwt_secret":           redactSecret,
+	"jwt_previous_secrets": redactSecret,
+	"database_dsn":         redactDSN,
+	"rate_limit_api_keys":  redactSecret,
+}
+
+// dsnPassword - пароль в строке подключения к БД в формате "ключ=значение"
+var dsnPassword = regexp.MustCompile(`(password=)('[^']*'|\S+)`)
+
+// load - метод формирования конфигурации из источников в порядке возрастания приоритета:
+// значения по-умолчанию, файл конфигурации, флаги запуска flags (только явно заданные fields),
+// переменные окружения и файлы из переменных *_FILE
+func load(flags *Config, fields map[string]struct{}) (*Config, error) {
+	cfg := NewDefaultConfig()
+	if flags == nil {
+		flags = NewDefaultConfig()
+	}
+
+	// Путь к файлу конфигурации задается флагом или переменной окружения
+	probe := *flags
+	if err := probe.loadFromEnv(); err != nil {
+		return nil, fmt.Errorf("can't parse environment: %w", err)
+	}
+	cfg.ConfigFilePath = probe.ConfigFilePath
+	if err := cfg.loadFromFile(); err != nil {
+		return nil, err
+	}
+
+	current := reflect.ValueOf(cfg).Elem()
+	values := reflect.ValueOf(flags).Elem()
+	for i := 0; i < current.NumField(); i++ {
+		field := current.Type().Field(i)
+		if _, ok := fields[field.Name]; !ok {
+			continue
+		}
+		current.Field(i).Set(values.Field(i))
+		cfg.setSource(fieldName(field), SourceFlag)
+	}
+
+	if err := cfg.loadFromEnv(); err != nil {
+		return nil, fmt.Errorf("can't parse environment: %w", err)
+	}
+	for i := 0; i < current.NumField(); i++ {
+		field := current.Type().Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("env"), ",")
+		// пустая переменная окружения не изменяет значение
+		if len(key) != 0 && len(os.Getenv(key)) != 0 {
+			cfg.setSource(fieldName(field), SourceEnv)
+		}
+	}
+	if err := cfg.loadFromEnvFiles(); err != nil {
+		return nil, fmt.Errorf("can't parse environment: %w", err)
+	}
+	return cfg, nil
+}
+
+// flagFields - метод получения имен полей конфигурации, заданных флагами явно
+func flagFields(cfg *Config, flags *pflag.FlagSet) map[string]struct{} {
+	addrs := make(map[uintptr]string)
+	value := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		if value.Type().Field(i).IsExported() {
+			addrs[value.Field(i).Addr().Pointer()] = value.Type().Field(i).Name
+		}
+	}
+	result := make(map[string]struct{})
+	// значение флага указывает на поле конфигурации, к которому флаг привязан
+	flags.Visit(func(f *pflag.Flag) {
+		if name, ok := addrs[reflect.ValueOf(f.Value).Pointer()]; ok {
+			result[name] = struct{}{}
+		}
+	})
+	return result
+}
+
+// setSource - метод установки источника значения параметра name
+func (cfg *Config) setSource(name string, source Source) {
+	if len(name) == 0 {
+		return
+	}
+	if cfg.sources == nil {
+		cfg.sources = make(map[string]Source)
+	}
+	cfg.sources[name] = source
+}
+
+// Source - метод получения источника значения параметра по имени в файле конфигурации
+func (cfg *Config) Source(name string) Source {
+	if source, ok := cfg.sources[name]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// Settings - метод получения действующих значений параметров с источниками, секреты скрыты
+func (cfg *Config) Settings() []Setting {
+	var result []Setting
+	value := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		name := fieldName(value.Type().Field(i))
+		if len(name) == 0 {
+			continue
+		}
+		setting := Setting{Name: name, Value: value.Field(i).Interface(), Source: cfg.Source(name)}
+		if redact, ok := secretFields[name]; ok {
+			setting.Value = redact(value.Field(i).String())
+		}
+		result = append(result, setting)
+	}
+	return result
+}
+
+// WriteSettings - метод вывода действующей конфигурации в виде таблицы: параметр, значение, источник
+func (cfg *Config) WriteSettings(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tVALUE\tSOURCE")
+	for _, setting := range cfg.Settings() {
+		fmt.Fprintf(tw, "%s\t%v\t%s\n", setting.Name, setting.Value, setting.Source)
+	}
+	return tw.Flush()
+}
+
+// String - метод получения строкового представления конфигурации (используется при логировании), секреты скрыты
+func (cfg *Config) String() string {
+	settings := cfg.Settings()
+	items := make([]string, 0, len(settings))
+	for _, setting := range settings {
+		items = append(items, fmt.Sprintf("%s=%v", setting.Name, setting.Value))
+	}
+	return "{" + strings.Join(items, " ") + "}"
+}
+
+// redactSecret - метод скрытия значения секрета (пустое значение не скрывается)
+func redactSecret(value string) string {
+	if len(value) == 0 {
+		return value
+	}
+	return redactedValue
+}
+
+// redactDSN - метод скрытия пароля в строке подключения к БД (URL или "ключ=значение")
+func redactDSN(value string) string {
+	if u, err := url.Parse(value); err == nil && len(u.Scheme) != 0 {
+		return u.Redacted()
+	}
+	return dsnPassword.ReplaceAllString(value, "${1}"+redactedValue)
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/denmor86/go-url-shortener/internal/gen"
+	"github.com/denmor86/go-url-shortener/internal/helpers"
+	"github.com/denmor86/go-url-shortener/internal/logger"
+	"github.com/denmor86/go-url-shortener/internal/ratelimit"
+)
+
+// FieldError - ошибка значения параметра конфигурации
+type FieldError struct {
+	Field string // имя параметра в файле конфигурации
+	Err   error  // описание ошибки
+}
+
+// Error - метод получения текста ошибки
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+// Unwrap - метод получения исходной ошибки
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError - ошибка проверки конфигурации, содержит все найденные ошибки параметров
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Error - метод получения текста ошибки (по одной ошибке параметра на строку)
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid configuration (%d errors):", len(e.Errors))
+	for _, err := range e.Errors {
+		b.WriteString("\n  ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap - метод получения ошибок параметров (для errors.Is и errors.As)
+func (e *ValidationError) Unwrap() []error {
+	result := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		result = append(result, err)
+	}
+	return result
+}
+
+// Add - метод добавления ошибки параметра field (nil игнорируется)
+func (e *ValidationError) Add(field string, err error) {
+	if err != nil {
+		e.Errors = append(e.Errors, FieldError{Field: field, Err: err})
+	}
+}
+
+// Err - метод получения ошибки проверки: nil, если ошибок параметров нет
+func (e *ValidationError) Err() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate - метод проверки конфигурации: адресов, URL, подсетей, длительностей, допустимых диапазонов
+// и взаимоисключающих параметров. Возвращает *ValidationError со всеми найденными ошибками или nil
+func (cfg *Config) Validate() error {
+	v := &ValidationError{}
+
+	// Адреса серверов
+	if len(cfg.ListenAddr) == 0 && len(cfg.GRPCAddr) == 0 {
+		v.Add("server_address", fmt.Errorf("server_address or grpc_address must be set"))
+	}
+	v.Add("server_address", validateAddr(cfg.ListenAddr))
+	v.Add("grpc_address", validateAddr(cfg.GRPCAddr))
+	v.Add("base_url", validateURL(cfg.BaseURL))
+	if cfg.ShortURLLen < 1 || cfg.ShortURLLen > helpers.MaxShortURLLen {
+		v.Add("max_url_len", fmt.Errorf("must be in range 1..%d, got %d", helpers.MaxShortURLLen, cfg.ShortURLLen))
+	}
+	v.Add("log_level", logger.ValidateLevel(cfg.LogLevel))
+	v.Add("database_timeout", positive(cfg.DatabaseTimeout))
+	if len(cfg.JWTSecret) == 0 {
+		v.Add("jwt_secret", fmt.Errorf("must not be empty"))
+	}
+
+	// TLS и ACME
+	if _, err := helpers.ParseTLSVersion(cfg.TLSMinVersion); err != nil {
+		v.Add("tls_min_version", err)
+	}
+	v.Add("tls_reload_interval", notNegative(cfg.TLSReloadInterval))
+	if (len(cfg.TLSCertFile) == 0) != (len(cfg.TLSKeyFile) == 0) {
+		v.Add("tls_cert_file", fmt.Errorf("tls_cert_file and tls_key_file must be set together"))
+	}
+	if cfg.ACMEEnabled {
+		if len(cfg.TLSCertFile) != 0 {
+			v.Add("acme_enabled", fmt.Errorf("mutually exclusive with tls_cert_file"))
+		}
+		if cfg.TLSSelfSigned {
+			v.Add("acme_enabled", fmt.Errorf("mutually exclusive with tls_self_signed"))
+		}
+		if len(cfg.ACMECacheDir) == 0 && len(cfg.DatabaseDSN) == 0 {
+			v.Add("acme_cache_dir", fmt.Errorf("acme_cache_dir or database_dsn must be set"))
+		}
+		v.Add("acme_directory_url", validateURL(cfg.ACMEDirectoryURL))
+		v.Add("acme_http_addr", validateAddr(cfg.ACMEHTTPAddr))
+	}
+	if cfg.HTTPSEnabled && !cfg.ACMEEnabled && !cfg.TLSSelfSigned && len(cfg.TLSCertFile) == 0 {
+		v.Add("enable_https", fmt.Errorf("requires tls_cert_file and tls_key_file, tls_self_signed or acme_enabled"))
+	}
+
+	// Доверенные подсети
+	if _, err := helpers.ParseSubnets(cfg.TrustedSubnet); err != nil {
+		v.Add("trusted_subnet", err)
+	}
+	if _, err := helpers.ParseGRPCMethods(cfg.TrustedMethods, &pb.Shortener_ServiceDesc); err != nil {
+		v.Add("trusted_methods", err)
+	}
+	if clients, err := helpers.ParseClientSubjects(cfg.TrustedClients); err != nil {
+		v.Add("trusted_clients", err)
+	} else if len(clients) != 0 && len(cfg.GRPCClientCAFile) == 0 {
+		v.Add("trusted_clients", fmt.Errorf("requires grpc_client_ca_file to verify client certificates"))
+	}
+	if _, err := helpers.ParseSubnets(cfg.TrustedProxies); err != nil {
+		v.Add("trusted_proxies", err)
+	}
+
+	// GRPC
+	if cfg.GRPCMaxRecvMsgSize <= 0 {
+		v.Add("grpc_max_recv_msg_size", fmt.Errorf("must be positive, got %d", cfg.GRPCMaxRecvMsgSize))
+	}
+	if cfg.GRPCMaxSendMsgSize <= 0 {
+		v.Add("grpc_max_send_msg_size", fmt.Errorf("must be positive, got %d", cfg.GRPCMaxSendMsgSize))
+	}
+	if cfg.GRPCMaxConcurrentStreams == 0 {
+		v.Add("grpc_max_concurrent_streams", fmt.Errorf("must be positive"))
+	}
+	v.Add("grpc_keepalive_time", positive(cfg.GRPCKeepaliveTime))
+	v.Add("grpc_keepalive_timeout", positive(cfg.GRPCKeepaliveTimeout))
+	v.Add("grpc_keepalive_min_time", notNegative(cfg.GRPCKeepaliveMinTime))
+
+	// Трассировка
+	if cfg.TracingSampleRatio < 0 || cfg.TracingSampleRatio > 1 {
+		v.Add("tracing_sample_ratio", fmt.Errorf("must be in range 0..1, got %g", cfg.TracingSampleRatio))
+	}
+	switch cfg.TracingExporter {
+	case "", "none", "stdout":
+	case "otlp":
+		if len(cfg.TracingEndpoint) == 0 {
+			v.Add("tracing_endpoint", fmt.Errorf("required for exporter %q", cfg.TracingExporter))
+		}
+	default:
+		v.Add("tracing_exporter", fmt.Errorf("unknown exporter %q (none, stdout, otlp)", cfg.TracingExporter))
+	}
+	v.Add("tracing_endpoint", validateAddr(cfg.TracingEndpoint))
+
+	// Ограничение частоты запросов, квоты и размеры запросов
+	if _, err := ratelimit.ParsePolicies(cfg.RateLimits); err != nil {
+		v.Add("rate_limits", err)
+	}
+	switch cfg.RateLimitStore {
+	case "memory":
+	case "postgres":
+		if len(cfg.DatabaseDSN) == 0 {
+			v.Add("rate_limit_store", fmt.Errorf("store %q requires database_dsn", cfg.RateLimitStore))
+		}
+	default:
+		v.Add("rate_limit_store", fmt.Errorf("unknown store %q (memory, postgres)", cfg.RateLimitStore))
+	}
+	if cfg.QuotaDaily < 0 {
+		v.Add("quota_daily", fmt.Errorf("must not be negative, got %d", cfg.QuotaDaily))
+	}
+	if cfg.QuotaTotal < 0 {
+		v.Add("quota_total", fmt.Errorf("must not be negative, got %d", cfg.QuotaTotal))
+	}
+	v.Add("idempotency_ttl", notNegative(cfg.IdempotencyTTL))
+	if cfg.MaxBatchSize < 0 {
+		v.Add("max_batch_size", fmt.Errorf("must not be negative, got %d", cfg.MaxBatchSize))
+	}
+	if cfg.CompressMinSize < 0 {
+		v.Add("compress_min_size", fmt.Errorf("must not be negative, got %d", cfg.CompressMinSize))
+	}
+
+	// Таймауты HTTP сервера
+	v.Add("read_header_timeout", notNegative(cfg.ReadHeaderTimeout))
+	v.Add("read_timeout", notNegative(cfg.ReadTimeout))
+	v.Add("write_timeout", notNegative(cfg.WriteTimeout))
+	v.Add("idle_timeout", notNegative(cfg.IdleTimeout))
+
+	return v.Err()
+}
+
+// validateAddr - метод проверки адреса в форме host:port (пустой адрес допустим - сервер не запускается)
+func validateAddr(addr string) error {
+	if len(addr) == 0 {
+		return nil
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return fmt.Errorf("invalid port in address %q", addr)
+	}
+	return nil
+}
+
+// validateURL - метод проверки абсолютного URL со схемой http или https
+func validateURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", value, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid URL %q: scheme must be http or https", value)
+	}
+	if len(u.Host) == 0 {
+		return fmt.Errorf("invalid URL %q: host is empty", value)
+	}
+	return nil
+}
+
+// positive - метод проверки положительной длительности
+func positive(d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("must be positive, got %s", d)
+	}
+	return nil
+}
+
+// notNegative - метод проверки неотрицательной длительности (0 - без ограничения)
+func notNegative(d time.Duration) error {
+	if d < 0 {
+		return fmt.Errorf("must not be negative, got %s", d)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(cfg *Config)
+		fields []string
+	}{
+		{"Default config #1 (good)", func(cfg *Config) {}, nil},
+		{"Self-signed HTTPS, GRPC only #2 (good)", func(cfg *Config) {
+			cfg.HTTPSEnabled = true
+			cfg.TLSSelfSigned = true
+			cfg.ListenAddr = ""
+		}, nil},
+		{"Short URL length #3 (bad)", func(cfg *Config) { cfg.ShortURLLen = 25 }, []string{"max_url_len"}},
+		{"Addresses and URL #4 (bad)", func(cfg *Config) {
+			cfg.ListenAddr = "localhost"
+			cfg.GRPCAddr = ":port"
+			cfg.BaseURL = "localhost:8080"
+		}, []string{"server_address", "grpc_address", "base_url"}},
+		{"No servers #5 (bad)", func(cfg *Config) {
+			cfg.ListenAddr = ""
+			cfg.GRPCAddr = ""
+		}, []string{"server_address"}},
+		{"Durations #6 (bad)", func(cfg *Config) {
+			cfg.DatabaseTimeout = 0
+			cfg.ReadTimeout = -1
+			cfg.GRPCKeepaliveTime = 0
+		}, []string{"database_timeout", "grpc_keepalive_time", "read_timeout"}},
+		{"Subnets and limits #7 (bad)", func(cfg *Config) {
+			cfg.TrustedSubnet = "10.0.0.0/8,bad"
+			cfg.TrustedMethods = "GetStatistic,Unknown"
+			cfg.TrustedClients = "CN=stats"
+			cfg.RateLimits = "shorten=1"
+			cfg.RateLimitStore = "postgres"
+			cfg.TracingSampleRatio = 2
+		}, []string{"trusted_subnet", "trusted_methods", "trusted_clients", "tracing_sample_ratio", "rate_limits", "rate_limit_store"}},
+		{"Mutually exclusive TLS options #8 (bad)", func(cfg *Config) {
+			cfg.HTTPSEnabled = true
+			cfg.ACMEEnabled = true
+			cfg.TLSSelfSigned = true
+			cfg.TLSCertFile = "cert.pem"
+		}, []string{"tls_cert_file", "acme_enabled", "acme_enabled", "acme_cache_dir"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewDefaultConfig()
+			tc.modify(cfg)
+			err := cfg.Validate()
+			if len(tc.fields) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var v *ValidationError
+			require.True(t, errors.As(err, &v))
+			var fields []string
+			for _, fieldErr := range v.Errors {
+				fields = append(fields, fieldErr.Field)
+			}
+			assert.Equal(t, tc.fields, fields)
+		})
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	cause := errors.New("cause")
+	v := &ValidationError{}
+	assert.NoError(t, v.Err())
+
+	v.Add("log_level", nil)
+	v.Add("log_level", cause)
+	v.Add("jwt_secret", errors.New("must not be empty"))
+	err := v.Err()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "invalid configuration (2 errors):\n  log_level: cause\n  jwt_secret: must not be empty", err.Error())
+}
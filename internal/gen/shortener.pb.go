@@ -11,6 +11,7 @@ import (
 	sync "sync"
 	unsafe "unsafe"
 
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 )
@@ -686,11 +687,363 @@ func (x *DeleteURLsResponse) GetUrls() []string {
 	return nil
 }
 
+type EncodeURLStreamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EncodeURLStreamRequest) Reset() {
+	*x = EncodeURLStreamRequest{}
+	mi := &file_shortener_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EncodeURLStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EncodeURLStreamRequest) ProtoMessage() {}
+
+func (x *EncodeURLStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shortener_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EncodeURLStreamRequest.ProtoReflect.Descriptor instead.
+func (*EncodeURLStreamRequest) Descriptor() ([]byte, []int) {
+	return file_shortener_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *EncodeURLStreamRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *EncodeURLStreamRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *EncodeURLStreamRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+type EncodeURLStreamResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EncodeURLStreamResponse) Reset() {
+	*x = EncodeURLStreamResponse{}
+	mi := &file_shortener_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EncodeURLStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EncodeURLStreamResponse) ProtoMessage() {}
+
+func (x *EncodeURLStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shortener_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EncodeURLStreamResponse.ProtoReflect.Descriptor instead.
+func (*EncodeURLStreamResponse) Descriptor() ([]byte, []int) {
+	return file_shortener_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *EncodeURLStreamResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *EncodeURLStreamResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *EncodeURLStreamResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListURLsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Cursor        string                 `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	PageSize      int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListURLsRequest) Reset() {
+	*x = ListURLsRequest{}
+	mi := &file_shortener_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListURLsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListURLsRequest) ProtoMessage() {}
+
+func (x *ListURLsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shortener_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListURLsRequest.ProtoReflect.Descriptor instead.
+func (*ListURLsRequest) Descriptor() ([]byte, []int) {
+	return file_shortener_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListURLsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListURLsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListURLsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListURLsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*URL                 `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Cursor        string                 `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListURLsResponse) Reset() {
+	*x = ListURLsResponse{}
+	mi := &file_shortener_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListURLsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListURLsResponse) ProtoMessage() {}
+
+func (x *ListURLsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shortener_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListURLsResponse.ProtoReflect.Descriptor instead.
+func (*ListURLsResponse) Descriptor() ([]byte, []int) {
+	return file_shortener_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListURLsResponse) GetResults() []*URL {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *ListURLsResponse) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type GetQuotaRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQuotaRequest) Reset() {
+	*x = GetQuotaRequest{}
+	mi := &file_shortener_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuotaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuotaRequest) ProtoMessage() {}
+
+func (x *GetQuotaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shortener_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuotaRequest.ProtoReflect.Descriptor instead.
+func (*GetQuotaRequest) Descriptor() ([]byte, []int) {
+	return file_shortener_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetQuotaRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type GetQuotaResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DailyLimit    int32                  `protobuf:"varint,1,opt,name=daily_limit,json=dailyLimit,proto3" json:"daily_limit,omitempty"`
+	DailyUsed     int32                  `protobuf:"varint,2,opt,name=daily_used,json=dailyUsed,proto3" json:"daily_used,omitempty"`
+	TotalLimit    int32                  `protobuf:"varint,3,opt,name=total_limit,json=totalLimit,proto3" json:"total_limit,omitempty"`
+	TotalUsed     int32                  `protobuf:"varint,4,opt,name=total_used,json=totalUsed,proto3" json:"total_used,omitempty"`
+	ResetAt       int64                  `protobuf:"varint,5,opt,name=reset_at,json=resetAt,proto3" json:"reset_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQuotaResponse) Reset() {
+	*x = GetQuotaResponse{}
+	mi := &file_shortener_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuotaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuotaResponse) ProtoMessage() {}
+
+func (x *GetQuotaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shortener_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuotaResponse.ProtoReflect.Descriptor instead.
+func (*GetQuotaResponse) Descriptor() ([]byte, []int) {
+	return file_shortener_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetQuotaResponse) GetDailyLimit() int32 {
+	if x != nil {
+		return x.DailyLimit
+	}
+	return 0
+}
+
+func (x *GetQuotaResponse) GetDailyUsed() int32 {
+	if x != nil {
+		return x.DailyUsed
+	}
+	return 0
+}
+
+func (x *GetQuotaResponse) GetTotalLimit() int32 {
+	if x != nil {
+		return x.TotalLimit
+	}
+	return 0
+}
+
+func (x *GetQuotaResponse) GetTotalUsed() int32 {
+	if x != nil {
+		return x.TotalUsed
+	}
+	return 0
+}
+
+func (x *GetQuotaResponse) GetResetAt() int64 {
+	if x != nil {
+		return x.ResetAt
+	}
+	return 0
+}
+
 var File_shortener_proto protoreflect.FileDescriptor
 
 const file_shortener_proto_rawDesc = "" +
 	"\n" +
-	"\x0fshortener.proto\x12\tshortener\";\n" +
+	"\x0fshortener.proto\x12\tshortener\x1a\x1cgoogle/api/annotations.proto\";\n" +
 	"\x03URL\x12\x1a\n" +
 	"\boriginal\x18\x01 \x01(\tR\boriginal\x12\x18\n" +
 	"\ashorten\x18\x02 \x01(\tR\ashorten\",\n" +
@@ -724,16 +1077,46 @@ const file_shortener_proto_rawDesc = "" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
 	"\x04urls\x18\x02 \x03(\tR\x04urls\"(\n" +
 	"\x12DeleteURLsResponse\x12\x12\n" +
-	"\x04urls\x18\x01 \x03(\tR\x04urls2\xbe\x03\n" +
-	"\tShortener\x12F\n" +
-	"\tDecodeURL\x12\x1b.shortener.DecodeURLRequest\x1a\x1c.shortener.DecodeURLResponse\x12F\n" +
-	"\tEncodeURL\x12\x1b.shortener.EncodeURLRequest\x1a\x1c.shortener.EncodeURLResponse\x12I\n" +
+	"\x04urls\x18\x01 \x03(\tR\x04urls\"S\n" +
+	"\x16EncodeURLStreamRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\"Q\n" +
+	"\x17EncodeURLStreamResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"_\n" +
+	"\x0fListURLsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\"T\n" +
+	"\x10ListURLsResponse\x12(\n" +
+	"\aresults\x18\x01 \x03(\v2\x0e.shortener.URLR\aresults\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\"*\n" +
+	"\x0fGetQuotaRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"\xad\x01\n" +
+	"\x10GetQuotaResponse\x12\x1f\n" +
+	"\vdaily_limit\x18\x01 \x01(\x05R\n" +
+	"dailyLimit\x12\x1d\n" +
+	"\n" +
+	"daily_used\x18\x02 \x01(\x05R\tdailyUsed\x12\x1f\n" +
+	"\vtotal_limit\x18\x03 \x01(\x05R\n" +
+	"totalLimit\x12\x1d\n" +
+	"\n" +
+	"total_used\x18\x04 \x01(\x05R\ttotalUsed\x12\x19\n" +
+	"\breset_at\x18\x05 \x01(\x03R\aresetAt2\xb2\a\n" +
+	"\tShortener\x12b\n" +
+	"\tDecodeURL\x12\x1b.shortener.DecodeURLRequest\x1a\x1c.shortener.DecodeURLResponse\"\x1a\x82\xd3\xe4\x93\x02\x14\x12\x12/api/v2/urls/{url}\x12_\n" +
+	"\tEncodeURL\x12\x1b.shortener.EncodeURLRequest\x1a\x1c.shortener.EncodeURLResponse\"\x17\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/api/v2/urls\x12h\n" +
 	"\n" +
-	"EncodeURLs\x12\x1c.shortener.EncodeURLsRequest\x1a\x1d.shortener.EncodeURLsResponse\x12@\n" +
-	"\aGetURLs\x12\x19.shortener.GetURLsRequest\x1a\x1a.shortener.GetURLsResponse\x12I\n" +
+	"EncodeURLs\x12\x1c.shortener.EncodeURLsRequest\x1a\x1d.shortener.EncodeURLsResponse\"\x1d\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/api/v2/urls/batch\x12[\n" +
+	"\aGetURLs\x12\x19.shortener.GetURLsRequest\x1a\x1a.shortener.GetURLsResponse\"\x19\x82\xd3\xe4\x93\x02\x13\x12\x11/api/v2/user/urls\x12g\n" +
 	"\n" +
-	"DeleteURLs\x12\x1c.shortener.DeleteURLsRequest\x1a\x1d.shortener.DeleteURLsResponse\x12I\n" +
-	"\fGetStatistic\x12\x1b.shortener.StatisticRequest\x1a\x1c.shortener.StatisticResponseB\x0eZ\finternal/genb\x06proto3"
+	"DeleteURLs\x12\x1c.shortener.DeleteURLsRequest\x1a\x1d.shortener.DeleteURLsResponse\"\x1c\x82\xd3\xe4\x93\x02\x16:\x01**\x11/api/v2/user/urls\x12i\n" +
+	"\fGetStatistic\x12\x1b.shortener.StatisticRequest\x1a\x1c.shortener.StatisticResponse\"\x1e\x82\xd3\xe4\x93\x02\x18\x12\x16/api/v2/internal/stats\x12|\n" +
+	"\x0fEncodeURLStream\x12!.shortener.EncodeURLStreamRequest\x1a\".shortener.EncodeURLStreamResponse\"\x1e\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/api/v2/urls/stream(\x010\x01\x12f\n" +
+	"\bListURLs\x12\x1a.shortener.ListURLsRequest\x1a\x1b.shortener.ListURLsResponse\"\x1f\x82\xd3\xe4\x93\x02\x19\x12\x17/api/v2/user/urls/pages0\x01\x12_\n" +
+	"\bGetQuota\x12\x1a.shortener.GetQuotaRequest\x1a\x1b.shortener.GetQuotaResponse\"\x1a\x82\xd3\xe4\x93\x02\x14\x12\x12/api/v2/user/quotaB\x0eZ\finternal/genb\x06proto3"
 
 var (
 	file_shortener_proto_rawDescOnce sync.Once
@@ -747,43 +1130,56 @@ func file_shortener_proto_rawDescGZIP() []byte {
 	return file_shortener_proto_rawDescData
 }
 
-var file_shortener_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_shortener_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
 var file_shortener_proto_goTypes = []any{
-	(*URL)(nil),                // 0: shortener.URL
-	(*ShortURL)(nil),           // 1: shortener.ShortURL
-	(*EncodeURLRequest)(nil),   // 2: shortener.EncodeURLRequest
-	(*EncodeURLResponse)(nil),  // 3: shortener.EncodeURLResponse
-	(*EncodeURLsRequest)(nil),  // 4: shortener.EncodeURLsRequest
-	(*EncodeURLsResponse)(nil), // 5: shortener.EncodeURLsResponse
-	(*GetURLsRequest)(nil),     // 6: shortener.GetURLsRequest
-	(*GetURLsResponse)(nil),    // 7: shortener.GetURLsResponse
-	(*DecodeURLRequest)(nil),   // 8: shortener.DecodeURLRequest
-	(*DecodeURLResponse)(nil),  // 9: shortener.DecodeURLResponse
-	(*StatisticRequest)(nil),   // 10: shortener.StatisticRequest
-	(*StatisticResponse)(nil),  // 11: shortener.StatisticResponse
-	(*DeleteURLsRequest)(nil),  // 12: shortener.DeleteURLsRequest
-	(*DeleteURLsResponse)(nil), // 13: shortener.DeleteURLsResponse
+	(*URL)(nil),                     // 0: shortener.URL
+	(*ShortURL)(nil),                // 1: shortener.ShortURL
+	(*EncodeURLRequest)(nil),        // 2: shortener.EncodeURLRequest
+	(*EncodeURLResponse)(nil),       // 3: shortener.EncodeURLResponse
+	(*EncodeURLsRequest)(nil),       // 4: shortener.EncodeURLsRequest
+	(*EncodeURLsResponse)(nil),      // 5: shortener.EncodeURLsResponse
+	(*GetURLsRequest)(nil),          // 6: shortener.GetURLsRequest
+	(*GetURLsResponse)(nil),         // 7: shortener.GetURLsResponse
+	(*DecodeURLRequest)(nil),        // 8: shortener.DecodeURLRequest
+	(*DecodeURLResponse)(nil),       // 9: shortener.DecodeURLResponse
+	(*StatisticRequest)(nil),        // 10: shortener.StatisticRequest
+	(*StatisticResponse)(nil),       // 11: shortener.StatisticResponse
+	(*DeleteURLsRequest)(nil),       // 12: shortener.DeleteURLsRequest
+	(*DeleteURLsResponse)(nil),      // 13: shortener.DeleteURLsResponse
+	(*EncodeURLStreamRequest)(nil),  // 14: shortener.EncodeURLStreamRequest
+	(*EncodeURLStreamResponse)(nil), // 15: shortener.EncodeURLStreamResponse
+	(*ListURLsRequest)(nil),         // 16: shortener.ListURLsRequest
+	(*ListURLsResponse)(nil),        // 17: shortener.ListURLsResponse
+	(*GetQuotaRequest)(nil),         // 18: shortener.GetQuotaRequest
+	(*GetQuotaResponse)(nil),        // 19: shortener.GetQuotaResponse
 }
 var file_shortener_proto_depIdxs = []int32{
 	1,  // 0: shortener.EncodeURLsResponse.results:type_name -> shortener.ShortURL
 	0,  // 1: shortener.GetURLsResponse.results:type_name -> shortener.URL
-	8,  // 2: shortener.Shortener.DecodeURL:input_type -> shortener.DecodeURLRequest
-	2,  // 3: shortener.Shortener.EncodeURL:input_type -> shortener.EncodeURLRequest
-	4,  // 4: shortener.Shortener.EncodeURLs:input_type -> shortener.EncodeURLsRequest
-	6,  // 5: shortener.Shortener.GetURLs:input_type -> shortener.GetURLsRequest
-	12, // 6: shortener.Shortener.DeleteURLs:input_type -> shortener.DeleteURLsRequest
-	10, // 7: shortener.Shortener.GetStatistic:input_type -> shortener.StatisticRequest
-	9,  // 8: shortener.Shortener.DecodeURL:output_type -> shortener.DecodeURLResponse
-	3,  // 9: shortener.Shortener.EncodeURL:output_type -> shortener.EncodeURLResponse
-	5,  // 10: shortener.Shortener.EncodeURLs:output_type -> shortener.EncodeURLsResponse
-	7,  // 11: shortener.Shortener.GetURLs:output_type -> shortener.GetURLsResponse
-	13, // 12: shortener.Shortener.DeleteURLs:output_type -> shortener.DeleteURLsResponse
-	11, // 13: shortener.Shortener.GetStatistic:output_type -> shortener.StatisticResponse
-	8,  // [8:14] is the sub-list for method output_type
-	2,  // [2:8] is the sub-list for method input_type
-	2,  // [2:2] is the sub-list for extension type_name
-	2,  // [2:2] is the sub-list for extension extendee
-	0,  // [0:2] is the sub-list for field type_name
+	0,  // 2: shortener.ListURLsResponse.results:type_name -> shortener.URL
+	8,  // 3: shortener.Shortener.DecodeURL:input_type -> shortener.DecodeURLRequest
+	2,  // 4: shortener.Shortener.EncodeURL:input_type -> shortener.EncodeURLRequest
+	4,  // 5: shortener.Shortener.EncodeURLs:input_type -> shortener.EncodeURLsRequest
+	6,  // 6: shortener.Shortener.GetURLs:input_type -> shortener.GetURLsRequest
+	12, // 7: shortener.Shortener.DeleteURLs:input_type -> shortener.DeleteURLsRequest
+	10, // 8: shortener.Shortener.GetStatistic:input_type -> shortener.StatisticRequest
+	14, // 9: shortener.Shortener.EncodeURLStream:input_type -> shortener.EncodeURLStreamRequest
+	16, // 10: shortener.Shortener.ListURLs:input_type -> shortener.ListURLsRequest
+	18, // 11: shortener.Shortener.GetQuota:input_type -> shortener.GetQuotaRequest
+	9,  // 12: shortener.Shortener.DecodeURL:output_type -> shortener.DecodeURLResponse
+	3,  // 13: shortener.Shortener.EncodeURL:output_type -> shortener.EncodeURLResponse
+	5,  // 14: shortener.Shortener.EncodeURLs:output_type -> shortener.EncodeURLsResponse
+	7,  // 15: shortener.Shortener.GetURLs:output_type -> shortener.GetURLsResponse
+	13, // 16: shortener.Shortener.DeleteURLs:output_type -> shortener.DeleteURLsResponse
+	11, // 17: shortener.Shortener.GetStatistic:output_type -> shortener.StatisticResponse
+	15, // 18: shortener.Shortener.EncodeURLStream:output_type -> shortener.EncodeURLStreamResponse
+	17, // 19: shortener.Shortener.ListURLs:output_type -> shortener.ListURLsResponse
+	19, // 20: shortener.Shortener.GetQuota:output_type -> shortener.GetQuotaResponse
+	12, // [12:21] is the sub-list for method output_type
+	3,  // [3:12] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_shortener_proto_init() }
@@ -797,7 +1193,7 @@ func file_shortener_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_shortener_proto_rawDesc), len(file_shortener_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   14,
+			NumMessages:   20,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
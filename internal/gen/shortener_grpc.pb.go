@@ -20,12 +20,15 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Shortener_DecodeURL_FullMethodName    = "/shortener.Shortener/DecodeURL"
-	Shortener_EncodeURL_FullMethodName    = "/shortener.Shortener/EncodeURL"
-	Shortener_EncodeURLs_FullMethodName   = "/shortener.Shortener/EncodeURLs"
-	Shortener_GetURLs_FullMethodName      = "/shortener.Shortener/GetURLs"
-	Shortener_DeleteURLs_FullMethodName   = "/shortener.Shortener/DeleteURLs"
-	Shortener_GetStatistic_FullMethodName = "/shortener.Shortener/GetStatistic"
+	Shortener_DecodeURL_FullMethodName       = "/shortener.Shortener/DecodeURL"
+	Shortener_EncodeURL_FullMethodName       = "/shortener.Shortener/EncodeURL"
+	Shortener_EncodeURLs_FullMethodName      = "/shortener.Shortener/EncodeURLs"
+	Shortener_GetURLs_FullMethodName         = "/shortener.Shortener/GetURLs"
+	Shortener_DeleteURLs_FullMethodName      = "/shortener.Shortener/DeleteURLs"
+	Shortener_GetStatistic_FullMethodName    = "/shortener.Shortener/GetStatistic"
+	Shortener_EncodeURLStream_FullMethodName = "/shortener.Shortener/EncodeURLStream"
+	Shortener_ListURLs_FullMethodName        = "/shortener.Shortener/ListURLs"
+	Shortener_GetQuota_FullMethodName        = "/shortener.Shortener/GetQuota"
 )
 
 // ShortenerClient is the client API for Shortener service.
@@ -38,6 +41,9 @@ type ShortenerClient interface {
 	GetURLs(ctx context.Context, in *GetURLsRequest, opts ...grpc.CallOption) (*GetURLsResponse, error)
 	DeleteURLs(ctx context.Context, in *DeleteURLsRequest, opts ...grpc.CallOption) (*DeleteURLsResponse, error)
 	GetStatistic(ctx context.Context, in *StatisticRequest, opts ...grpc.CallOption) (*StatisticResponse, error)
+	EncodeURLStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[EncodeURLStreamRequest, EncodeURLStreamResponse], error)
+	ListURLs(ctx context.Context, in *ListURLsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ListURLsResponse], error)
+	GetQuota(ctx context.Context, in *GetQuotaRequest, opts ...grpc.CallOption) (*GetQuotaResponse, error)
 }
 
 type shortenerClient struct {
@@ -108,6 +114,48 @@ func (c *shortenerClient) GetStatistic(ctx context.Context, in *StatisticRequest
 	return out, nil
 }
 
+func (c *shortenerClient) EncodeURLStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[EncodeURLStreamRequest, EncodeURLStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Shortener_ServiceDesc.Streams[0], Shortener_EncodeURLStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[EncodeURLStreamRequest, EncodeURLStreamResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Shortener_EncodeURLStreamClient = grpc.BidiStreamingClient[EncodeURLStreamRequest, EncodeURLStreamResponse]
+
+func (c *shortenerClient) ListURLs(ctx context.Context, in *ListURLsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ListURLsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Shortener_ServiceDesc.Streams[1], Shortener_ListURLs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListURLsRequest, ListURLsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Shortener_ListURLsClient = grpc.ServerStreamingClient[ListURLsResponse]
+
+func (c *shortenerClient) GetQuota(ctx context.Context, in *GetQuotaRequest, opts ...grpc.CallOption) (*GetQuotaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetQuotaResponse)
+	err := c.cc.Invoke(ctx, Shortener_GetQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ShortenerServer is the server API for Shortener service.
 // All implementations must embed UnimplementedShortenerServer
 // for forward compatibility.
@@ -118,6 +166,9 @@ type ShortenerServer interface {
 	GetURLs(context.Context, *GetURLsRequest) (*GetURLsResponse, error)
 	DeleteURLs(context.Context, *DeleteURLsRequest) (*DeleteURLsResponse, error)
 	GetStatistic(context.Context, *StatisticRequest) (*StatisticResponse, error)
+	EncodeURLStream(grpc.BidiStreamingServer[EncodeURLStreamRequest, EncodeURLStreamResponse]) error
+	ListURLs(*ListURLsRequest, grpc.ServerStreamingServer[ListURLsResponse]) error
+	GetQuota(context.Context, *GetQuotaRequest) (*GetQuotaResponse, error)
 	mustEmbedUnimplementedShortenerServer()
 }
 
@@ -146,6 +197,15 @@ func (UnimplementedShortenerServer) DeleteURLs(context.Context, *DeleteURLsReque
 func (UnimplementedShortenerServer) GetStatistic(context.Context, *StatisticRequest) (*StatisticResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetStatistic not implemented")
 }
+func (UnimplementedShortenerServer) EncodeURLStream(grpc.BidiStreamingServer[EncodeURLStreamRequest, EncodeURLStreamResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method EncodeURLStream not implemented")
+}
+func (UnimplementedShortenerServer) ListURLs(*ListURLsRequest, grpc.ServerStreamingServer[ListURLsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ListURLs not implemented")
+}
+func (UnimplementedShortenerServer) GetQuota(context.Context, *GetQuotaRequest) (*GetQuotaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQuota not implemented")
+}
 func (UnimplementedShortenerServer) mustEmbedUnimplementedShortenerServer() {}
 func (UnimplementedShortenerServer) testEmbeddedByValue()                   {}
 
@@ -275,6 +335,42 @@ func _Shortener_GetStatistic_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Shortener_EncodeURLStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ShortenerServer).EncodeURLStream(&grpc.GenericServerStream[EncodeURLStreamRequest, EncodeURLStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Shortener_EncodeURLStreamServer = grpc.BidiStreamingServer[EncodeURLStreamRequest, EncodeURLStreamResponse]
+
+func _Shortener_ListURLs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListURLsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ShortenerServer).ListURLs(m, &grpc.GenericServerStream[ListURLsRequest, ListURLsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Shortener_ListURLsServer = grpc.ServerStreamingServer[ListURLsResponse]
+
+func _Shortener_GetQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServer).GetQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Shortener_GetQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServer).GetQuota(ctx, req.(*GetQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Shortener_ServiceDesc is the grpc.ServiceDesc for Shortener service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -306,7 +402,23 @@ var Shortener_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetStatistic",
 			Handler:    _Shortener_GetStatistic_Handler,
 		},
+		{
+			MethodName: "GetQuota",
+			Handler:    _Shortener_GetQuota_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "EncodeURLStream",
+			Handler:       _Shortener_EncodeURLStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ListURLs",
+			Handler:       _Shortener_ListURLs_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "shortener.proto",
 }
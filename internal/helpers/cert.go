@@ -1,61 +1,135 @@
 package helpers
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
-	"crypto/rsa"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"fmt"
 	"math/big"
 	"net"
+	"os"
+	"strings"
 	"time"
-
-	"github.com/denmor86/go-url-shortener/internal/logger"
 )
 
-// GenerateSelfSignedCert - метод генерирует самоподписанный сертификат и приватный ключ
-func GenerateSelfSignedCert() ([]byte, *rsa.PrivateKey, error) {
-	// создаём новый приватный RSA-ключ длиной 4096 бит
-	// обратите внимание, что для генерации ключа и сертификата
-	// используется rand.Reader в качестве источника случайных данных
-	privateKey, err := rsa.GenerateKey(rand.Reader, 4096)
-	if err != nil {
-		logger.Panic(err)
-	}
-
-	// Создаем шаблон сертификата
-	cert := &x509.Certificate{
-		// указываем уникальный номер сертификата
-		SerialNumber: big.NewInt(1658),
-		// заполняем базовую информацию о владельце сертификата
+// SelfSignedCertificate - метод формирования самоподписанного сертификата с ECDSA ключом для TLS конфигурации
+// (127.0.0.1, ::1, localhost). Используется только при явном указании в настройках
+func SelfSignedCertificate() (tls.Certificate, error) {
+	template := &x509.Certificate{
 		Subject: pkix.Name{
 			Organization: []string{"Yandex.Praktikum"},
 			Country:      []string{"RU"},
 		},
-		// разрешаем использование сертификата для 127.0.0.1 и ::1
+		DNSNames:    []string{"localhost"},
 		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
-		// сертификат верен, начиная со времени создания
-		NotBefore: time.Now(),
-		// время жизни сертификата — 10 лет
-		NotAfter:     time.Now().AddDate(10, 0, 0),
-		SubjectKeyId: []byte{1, 2, 3, 4, 6},
-		// устанавливаем использование ключа для цифровой подписи,
-		// а также клиентской и серверной авторизации
 		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
 		KeyUsage:    x509.KeyUsageDigitalSignature,
 	}
+	certPEM, keyPEM, err := generateCert(template, nil, nil)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
 
-	// Создаем сертификат
-	certBytes, err := x509.CreateCertificate(rand.Reader, cert, cert, &privateKey.PublicKey, privateKey)
+// ParseTLSVersion - метод разбора минимальной версии TLS ("1.2", "1.3"; пустая строка - TLS 1.2)
+func ParseTLSVersion(version string) (uint16, error) {
+	switch strings.TrimSpace(version) {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version: %s", version)
+	}
+}
+
+// NewServerTLSConfig - метод формирования TLS конфигурации сервера.
+// Сертификат выдается функцией getCertificate при каждом рукопожатии (см. CertReloader).
+// Если указан файл с сертификатами CA, требуется клиентский сертификат, подписанный одним из них (mTLS)
+func NewServerTLSConfig(getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error), minVersion uint16, clientCAFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		GetCertificate: getCertificate,
+		MinVersion:     minVersion,
+	}
+	if len(clientCAFile) == 0 {
+		return tlsConfig, nil
+	}
+	caPEM, err := os.ReadFile(clientCAFile)
 	if err != nil {
-		return nil, nil, err
+		return nil, fmt.Errorf("can't read client CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in client CA file: %s", clientCAFile)
 	}
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	return tlsConfig, nil
+}
 
-	// Кодируем сертификат в PEM формате
-	certPEM := pem.EncodeToMemory(&pem.Block{
-		Type:  "CERTIFICATE",
-		Bytes: certBytes,
-	})
+// GenerateCACert - метод генерирует сертификат удостоверяющего центра (CA) и его приватный ключ в PEM формате
+func GenerateCACert(commonName string) ([]byte, []byte, error) {
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	return generateCert(template, nil, nil)
+}
 
-	return certPEM, privateKey, nil
+// GenerateSignedCert - метод генерирует сертификат, подписанный CA, и его приватный ключ в PEM формате.
+// Сертификат может использоваться как для серверной, так и для клиентской авторизации (127.0.0.1, ::1, localhost)
+func GenerateSignedCert(commonName string, caCertPEM []byte, caKeyPEM []byte) ([]byte, []byte, error) {
+	ca, err := tls.X509KeyPair(caCertPEM, caKeyPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid CA key pair: %w", err)
+	}
+	caCert, err := x509.ParseCertificate(ca.Certificate[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid CA certificate: %w", err)
+	}
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName},
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+	}
+	return generateCert(template, caCert, ca.PrivateKey)
+}
+
+// generateCert - метод генерирует ECDSA ключ и сертификат по шаблону (самоподписанный, если parent не задан)
+func generateCert(template *x509.Certificate, parent *x509.Certificate, parentKey any) ([]byte, []byte, error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Minute)
+	template.NotAfter = time.Now().AddDate(1, 0, 0)
+	if parent == nil {
+		parent, parentKey = template, privateKey
+	}
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, parent, &privateKey.PublicKey, parentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyBytes, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes})
+	return certPEM, keyPEM, nil
 }
@@ -1,76 +1,125 @@
 package helpers
 
 import (
-	"crypto/rsa"
+	"crypto/ecdsa"
+	"crypto/tls"
 	"crypto/x509"
-	"encoding/pem"
-	"math/big"
-	"net"
+	"os"
+	"path/filepath"
 	"testing"
-	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func TestGenerateSelfSignedCert(t *testing.T) {
-	t.Run("Successful", func(t *testing.T) {
-		certPEM, privKey, err := GenerateSelfSignedCert()
-		require.NoError(t, err)
-		require.NotNil(t, certPEM)
-		require.NotNil(t, privKey)
-
-		// Проверяем PEM кодировку сертификата
-		block, _ := pem.Decode(certPEM)
-		require.NotNil(t, block, "should decode PEM block successfully")
-		assert.Equal(t, "CERTIFICATE", block.Type, "PEM block should be of type CERTIFICATE")
+func TestSelfSignedCertificate(t *testing.T) {
+	cert, err := SelfSignedCertificate()
+	require.NoError(t, err)
+	require.Len(t, cert.Certificate, 1)
 
-		// Парсим сертификат
-		cert, err := x509.ParseCertificate(block.Bytes)
-		require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	assert.Equal(t, "Yandex.Praktikum", leaf.Subject.Organization[0])
+	assert.Contains(t, leaf.DNSNames, "localhost")
+	assert.IsType(t, &ecdsa.PrivateKey{}, cert.PrivateKey)
+}
 
-		// Проверяем основные поля сертификата
-		assert.Equal(t, big.NewInt(1658), cert.SerialNumber)
-		assert.Equal(t, "Yandex.Praktikum", cert.Subject.Organization[0])
-		assert.Equal(t, "RU", cert.Subject.Country[0])
-		assert.True(t, time.Now().After(cert.NotBefore), "certificate should be valid now")
-		assert.True(t, time.Now().AddDate(10, 0, 0).Sub(cert.NotAfter) < time.Hour*24, "certificate should expire in ~10 years")
-
-		// Проверяем IP адреса
-		expectedIPs := []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
-		require.Len(t, cert.IPAddresses, len(expectedIPs), "количество IP адресов не совпадает")
-
-		for _, expectedIP := range expectedIPs {
-			found := false
-			for _, actualIP := range cert.IPAddresses {
-				if expectedIP.Equal(actualIP) {
-					found = true
-					break
-				}
+func TestParseTLSVersion(t *testing.T) {
+	testCases := []struct {
+		name     string
+		version  string
+		expected uint16
+		wantErr  bool
+	}{
+		{"Default #1 (good)", "", tls.VersionTLS12, false},
+		{"TLS 1.2 #2 (good)", "1.2", tls.VersionTLS12, false},
+		{"TLS 1.3 #3 (good)", "1.3", tls.VersionTLS13, false},
+		{"TLS 1.1 #4 (bad)", "1.1", 0, true},
+		{"Invalid #5 (bad)", "tls", 0, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			version, err := ParseTLSVersion(tc.version)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
 			}
-			assert.True(t, found, "IP %v не найден в сертификате", expectedIP)
-		}
-
-		// Проверяем KeyUsage
-		assert.Equal(t, x509.KeyUsageDigitalSignature, cert.KeyUsage)
-		assert.ElementsMatch(t, []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth}, cert.ExtKeyUsage)
-
-		// Проверяем соответствие публичного ключа
-		pubKey, ok := cert.PublicKey.(*rsa.PublicKey)
-		require.True(t, ok, "public key should be RSA")
-		assert.Equal(t, privKey.PublicKey.N, pubKey.N, "public key modulus should match")
-		assert.Equal(t, privKey.PublicKey.E, pubKey.E, "public key exponent should match")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, version)
+		})
+	}
+}
+
+func TestGenerateSignedCert(t *testing.T) {
+	caPEM, caKeyPEM, err := GenerateCACert("test-ca")
+	require.NoError(t, err)
+
+	certPEM, keyPEM, err := GenerateSignedCert("client", caPEM, caKeyPEM)
+	require.NoError(t, err)
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	assert.Equal(t, "client", leaf.Subject.CommonName)
+
+	// сертификат подписан CA
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(caPEM))
+	_, err = leaf.Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	assert.NoError(t, err)
+
+	// сертификат не подписан другим CA
+	otherPEM, _, err := GenerateCACert("other-ca")
+	require.NoError(t, err)
+	otherPool := x509.NewCertPool()
+	require.True(t, otherPool.AppendCertsFromPEM(otherPEM))
+	_, err = leaf.Verify(x509.VerifyOptions{Roots: otherPool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	assert.Error(t, err)
+
+	_, _, err = GenerateSignedCert("client", caPEM, []byte("invalid"))
+	assert.Error(t, err)
+}
+
+func TestNewServerTLSConfig(t *testing.T) {
+	caPEM, caKeyPEM, err := GenerateCACert("test-ca")
+	require.NoError(t, err)
+	certPEM, keyPEM, err := GenerateSignedCert("server", caPEM, caKeyPEM)
+	require.NoError(t, err)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	getCertificate := NewStaticCertReloader(cert).GetCertificate
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0600))
+	invalidFile := filepath.Join(dir, "invalid.pem")
+	require.NoError(t, os.WriteFile(invalidFile, []byte("invalid"), 0600))
+
+	t.Run("Without client CA", func(t *testing.T) {
+		tlsConfig, err := NewServerTLSConfig(getCertificate, tls.VersionTLS13, "")
+		require.NoError(t, err)
+		assert.Equal(t, tls.NoClientCert, tlsConfig.ClientAuth)
+		assert.Equal(t, uint16(tls.VersionTLS13), tlsConfig.MinVersion)
+		served, err := tlsConfig.GetCertificate(nil)
+		require.NoError(t, err)
+		assert.Equal(t, cert.Certificate, served.Certificate)
 	})
 
-	t.Run("Check private key", func(t *testing.T) {
-		_, privKey, err := GenerateSelfSignedCert()
+	t.Run("With client CA", func(t *testing.T) {
+		tlsConfig, err := NewServerTLSConfig(getCertificate, tls.VersionTLS12, caFile)
 		require.NoError(t, err)
+		assert.Equal(t, tls.RequireAndVerifyClientCert, tlsConfig.ClientAuth)
+		assert.NotNil(t, tlsConfig.ClientCAs)
+	})
 
-		// Проверяем размер ключа (4096 бит)
-		assert.Equal(t, 4096, privKey.Size()*8, "key size should be 4096 bits")
+	t.Run("Missing client CA file", func(t *testing.T) {
+		_, err := NewServerTLSConfig(getCertificate, tls.VersionTLS12, filepath.Join(dir, "missing.pem"))
+		assert.Error(t, err)
+	})
 
-		// Проверяем, что ключ можно использовать для подписи
-		err = privKey.Validate()
-		assert.NoError(t, err, "private key should be valid")
+	t.Run("Invalid client CA file", func(t *testing.T) {
+		_, err := NewServerTLSConfig(getCertificate, tls.VersionTLS12, invalidFile)
+		assert.Error(t, err)
 	})
 }
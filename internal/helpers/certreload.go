@@ -0,0 +1,118 @@
+package helpers
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/denmor86/go-url-shortener/internal/logger"
+)
+
+// CertReloader - модель источника сертификата TLS с перезагрузкой из файлов.
+// Сертификат выдается через tls.Config.GetCertificate при каждом рукопожатии, поэтому замена
+// сертификата не затрагивает установленные соединения. Если перезагрузка не удалась, используется прежний сертификат
+type CertReloader struct {
+	certFile string // путь к файлу сертификата (PEM), пустой для статического сертификата
+	keyFile  string // путь к файлу приватного ключа (PEM): RSA, ECDSA или Ed25519
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate // текущий сертификат
+	certMod time.Time        // время изменения файла сертификата при последней загрузке
+	keyMod  time.Time        // время изменения файла ключа при последней загрузке
+}
+
+// NewCertReloader - метод создания источника сертификата из файлов сертификата и приватного ключа
+func NewCertReloader(certFile string, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// NewStaticCertReloader - метод создания источника с неизменяемым сертификатом (например, самоподписанным)
+func NewStaticCertReloader(cert tls.Certificate) *CertReloader {
+	return &CertReloader{cert: &cert}
+}
+
+// GetCertificate - метод получения текущего сертификата (tls.Config.GetCertificate)
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Reload - метод загрузки сертификата из файлов. Для статического сертификата ничего не делает
+func (r *CertReloader) Reload() error {
+	if len(r.certFile) == 0 {
+		return nil
+	}
+	certMod, keyMod, err := r.modTimes()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("can't load certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.certMod, r.keyMod = certMod, keyMod
+	return nil
+}
+
+// Watch - метод периодической проверки изменения файлов сертификата и ключа с перезагрузкой при изменении.
+// Работает до отмены контекста
+func (r *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	if len(r.certFile) == 0 || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			// при ошибке (например, ключ еще не обновлен) загрузка повторяется на следующей проверке
+			if err := r.Reload(); err != nil {
+				logger.Warn("TLS certificate reload failed:", err)
+				continue
+			}
+			logger.Info("TLS certificate reloaded from", r.certFile)
+		}
+	}
+}
+
+// changed - метод проверки изменения файлов сертификата и ключа с момента последней загрузки
+func (r *CertReloader) changed() bool {
+	certMod, keyMod, err := r.modTimes()
+	if err != nil {
+		logger.Warn("TLS certificate check failed:", err)
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return !certMod.Equal(r.certMod) || !keyMod.Equal(r.keyMod)
+}
+
+// modTimes - метод получения времени изменения файлов сертификата и ключа
+func (r *CertReloader) modTimes() (time.Time, time.Time, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("can't read certificate file: %w", err)
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("can't read key file: %w", err)
+	}
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
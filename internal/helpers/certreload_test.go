@@ -0,0 +1,97 @@
+package helpers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/denmor86/go-url-shortener/internal/logger"
+)
+
+// writeKeyPair - метод записи сертификата, подписанного CA, и его ключа в файлы
+func writeKeyPair(t *testing.T, certFile string, keyFile string, commonName string) {
+	t.Helper()
+	caPEM, caKeyPEM, err := GenerateCACert("test-ca")
+	require.NoError(t, err)
+	certPEM, keyPEM, err := GenerateSignedCert(commonName, caPEM, caKeyPEM)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0600))
+}
+
+// commonName - метод получения имени владельца выдаваемого сертификата
+func commonName(t *testing.T, r *CertReloader) string {
+	t.Helper()
+	cert, err := r.GetCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writeKeyPair(t, certFile, keyFile, "first")
+
+	r, err := NewCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, "first", commonName(t, r))
+
+	t.Run("Reload #1 (good)", func(t *testing.T) {
+		writeKeyPair(t, certFile, keyFile, "second")
+		require.NoError(t, r.Reload())
+		assert.Equal(t, "second", commonName(t, r))
+	})
+
+	t.Run("Invalid key keeps certificate #2 (bad)", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(keyFile, []byte("invalid"), 0600))
+		assert.Error(t, r.Reload())
+		assert.Equal(t, "second", commonName(t, r))
+	})
+
+	t.Run("Missing files #3 (bad)", func(t *testing.T) {
+		_, err := NewCertReloader(filepath.Join(dir, "missing.pem"), keyFile)
+		assert.Error(t, err)
+	})
+}
+
+func TestCertReloader_Watch(t *testing.T) {
+	require.NoError(t, logger.Initialize("info"))
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writeKeyPair(t, certFile, keyFile, "first")
+
+	r, err := NewCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Watch(ctx, 10*time.Millisecond)
+
+	writeKeyPair(t, certFile, keyFile, "second")
+	// время изменения файлов сдвигается явно, чтобы не зависеть от точности часов файловой системы
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	require.NoError(t, os.Chtimes(keyFile, future, future))
+
+	assert.Eventually(t, func() bool { return commonName(t, r) == "second" }, time.Second, 10*time.Millisecond)
+}
+
+func TestStaticCertReloader(t *testing.T) {
+	cert, err := SelfSignedCertificate()
+	require.NoError(t, err)
+	r := NewStaticCertReloader(cert)
+	assert.NoError(t, r.Reload())
+	served, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, cert.Certificate, served.Certificate)
+}
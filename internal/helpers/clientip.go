@@ -0,0 +1,108 @@
+package helpers
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Заголовки с адресом клиента, передаваемые прокси
+const (
+	// ForwardedHeader - стандартный заголовок прокси (RFC 7239)
+	ForwardedHeader = "Forwarded"
+	// ForwardedForHeader - заголовок со списком адресов клиента и промежуточных прокси
+	ForwardedForHeader = "X-Forwarded-For"
+	// RealIPHeader - заголовок с адресом клиента
+	RealIPHeader = "X-Real-IP"
+)
+
+// ClientIPResolver - модель определения IP адреса клиента.
+// Заголовки прокси учитываются, только если запрос получен от доверенного прокси
+type ClientIPResolver struct {
+	proxies []*net.IPNet // подсети доверенных прокси
+}
+
+// NewClientIPResolver - метод создания объекта определения IP адреса клиента с подсетями доверенных прокси
+func NewClientIPResolver(proxies []*net.IPNet) *ClientIPResolver {
+	return &ClientIPResolver{proxies: proxies}
+}
+
+// Resolve - метод определения IP адреса клиента по адресу соединения remoteAddr и заголовкам запроса.
+// Если соединение установлено доверенным прокси, цепочка адресов Forwarded (либо X-Forwarded-For, либо X-Real-IP)
+// просматривается справа налево до первого адреса вне доверенных подсетей. Иначе используется адрес соединения
+func (res *ClientIPResolver) Resolve(remoteAddr string, header http.Header) string {
+	remote := RemoteIP(remoteAddr)
+	if res == nil || !SubnetsContain(res.proxies, remote) {
+		return remote
+	}
+
+	hops := forwardedFor(header.Values(ForwardedHeader))
+	if len(hops) == 0 {
+		hops = splitList(header.Values(ForwardedForHeader))
+	}
+	if len(hops) == 0 {
+		hops = splitList(header.Values(RealIPHeader))
+	}
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := hostIP(hops[i])
+		// некорректный или скрытый адрес прерывает цепочку: клиентом считается последний проверенный прокси
+		if len(ip) == 0 {
+			break
+		}
+		client = ip
+		if !SubnetsContain(res.proxies, ip) {
+			break
+		}
+	}
+	return client
+}
+
+// RemoteIP - метод получения IP адреса из адреса соединения (адрес, не являющийся IP, возвращается без изменений)
+func RemoteIP(remoteAddr string) string {
+	if ip := hostIP(remoteAddr); len(ip) > 0 {
+		return ip
+	}
+	return remoteAddr
+}
+
+// forwardedFor - метод получения адресов из параметров for заголовка Forwarded
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, element := range splitList(values) {
+		for _, pair := range strings.Split(element, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(name, "for") {
+				hops = append(hops, value)
+			}
+		}
+	}
+	return hops
+}
+
+// splitList - метод разбора значений заголовков со списками через запятую
+func splitList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); len(item) > 0 {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// hostIP - метод получения IP адреса из значения вида "ip", "ip:port", "[ipv6]:port" (в том числе в кавычках).
+// Возвращает пустую строку, если значение не является IP адресом
+func hostIP(addr string) string {
+	addr = strings.Trim(strings.TrimSpace(addr), `"`)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	ip := net.ParseIP(strings.Trim(addr, "[]"))
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
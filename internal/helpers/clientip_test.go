@@ -0,0 +1,57 @@
+package helpers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIPResolver_Resolve(t *testing.T) {
+	proxies, err := ParseSubnets("10.0.0.0/8, fd00::/8")
+	require.NoError(t, err)
+	resolver := NewClientIPResolver(proxies)
+
+	testCases := []struct {
+		name       string
+		resolver   *ClientIPResolver
+		remoteAddr string
+		header     http.Header
+		expected   string
+	}{
+		{"Remote address #1 (good)", resolver, "203.0.113.7:5000", nil, "203.0.113.7"},
+		{"Untrusted remote with headers #2 (bad)", resolver, "203.0.113.7:5000",
+			http.Header{ForwardedForHeader: {"192.168.1.10"}, "X-Real-Ip": {"192.168.1.10"}}, "203.0.113.7"},
+		{"X-Forwarded-For from trusted proxy #3 (good)", resolver, "10.0.0.1:5000",
+			http.Header{ForwardedForHeader: {"198.51.100.1, 203.0.113.7", "10.0.0.2"}}, "203.0.113.7"},
+		{"Forwarded from trusted proxy #4 (good)", resolver, "[fd00::1]:5000",
+			http.Header{ForwardedHeader: {`for=198.51.100.1, for="[2001:db8::7]:4711";proto=https`}, ForwardedForHeader: {"198.51.100.2"}}, "2001:db8::7"},
+		{"X-Real-IP from trusted proxy #5 (good)", resolver, "10.0.0.1:5000", http.Header{"X-Real-Ip": {"203.0.113.7"}}, "203.0.113.7"},
+		{"Only trusted hops #6 (good)", resolver, "10.0.0.1:5000", http.Header{ForwardedForHeader: {"10.0.0.3, 10.0.0.2"}}, "10.0.0.3"},
+		{"Obfuscated hop #7 (bad)", resolver, "10.0.0.1:5000", http.Header{ForwardedHeader: {"for=198.51.100.1, for=_hidden"}}, "10.0.0.1"},
+		{"Without resolver #8 (good)", nil, "10.0.0.1:5000", http.Header{"X-Real-Ip": {"203.0.113.7"}}, "10.0.0.1"},
+		{"Not IP address #9 (good)", resolver, "pipe", nil, "pipe"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.resolver.Resolve(tc.remoteAddr, tc.header))
+		})
+	}
+}
+
+func TestParseSubnets(t *testing.T) {
+	subnets, err := ParseSubnets("192.168.1.0/24, fd00::/8,")
+	require.NoError(t, err)
+	require.Len(t, subnets, 2)
+	assert.True(t, SubnetsContain(subnets, "fd00::1"))
+	assert.False(t, SubnetsContain(subnets, "10.0.0.1"))
+
+	_, err = ParseSubnets("192.168.1.0/24,subnet")
+	assert.Error(t, err)
+
+	subnets, err = ParseSubnets("")
+	require.NoError(t, err)
+	assert.Empty(t, subnets)
+}
@@ -0,0 +1,35 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// ParseGRPCMethods - метод разбора списка методов GRPC сервиса через запятую. Метод задается кратким
+// (GetStatistic) или полным (/shortener.Shortener/GetStatistic) именем, результат - полные имена методов
+func ParseGRPCMethods(methods string, service *grpc.ServiceDesc) ([]string, error) {
+	known := make(map[string]struct{}, len(service.Methods)+len(service.Streams))
+	for _, method := range service.Methods {
+		known[method.MethodName] = struct{}{}
+	}
+	for _, stream := range service.Streams {
+		known[stream.StreamName] = struct{}{}
+	}
+	prefix := "/" + service.ServiceName + "/"
+
+	var result []string
+	for _, method := range strings.Split(methods, ",") {
+		method = strings.TrimSpace(method)
+		if len(method) == 0 {
+			continue
+		}
+		name := strings.TrimPrefix(method, prefix)
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("unknown method %q of service %s", method, service.ServiceName)
+		}
+		result = append(result, prefix+name)
+	}
+	return result, nil
+}
@@ -0,0 +1,41 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestParseGRPCMethods(t *testing.T) {
+	service := &grpc.ServiceDesc{
+		ServiceName: "shortener.Shortener",
+		Methods:     []grpc.MethodDesc{{MethodName: "GetStatistic"}, {MethodName: "GetURLs"}},
+	}
+
+	testCases := []struct {
+		name      string
+		methods   string
+		expected  []string
+		wantError bool
+	}{
+		{"Short names #1 (good)", "GetStatistic, GetURLs", []string{"/shortener.Shortener/GetStatistic", "/shortener.Shortener/GetURLs"}, false},
+		{"Full name #2 (good)", "/shortener.Shortener/GetURLs", []string{"/shortener.Shortener/GetURLs"}, false},
+		{"Empty list #3 (good)", " , ", nil, false},
+		{"Unknown method #4 (bad)", "GetStatistic,Unknown", nil, true},
+		{"Other service #5 (bad)", "/other.Service/GetURLs", nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			methods, err := ParseGRPCMethods(tc.methods, service)
+			if tc.wantError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, methods)
+		})
+	}
+}
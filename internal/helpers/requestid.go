@@ -0,0 +1,45 @@
+package helpers
+
+import (
+	guuid "github.com/google/uuid"
+)
+
+// Параметры идентификатора запроса
+const (
+	// RequestIDHeader - HTTP заголовок с идентификатором запроса
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDMetadataKey - ключ метаданных GRPC с идентификатором запроса
+	RequestIDMetadataKey = "x-request-id"
+	// maxRequestIDLen - максимальная длина принимаемого идентификатора запроса
+	maxRequestIDLen = 128
+)
+
+// NewRequestID - метод формирования нового идентификатора запроса
+func NewRequestID() string {
+	return guuid.New().String()
+}
+
+// ValidRequestID - метод проверки идентификатора запроса, полученного от клиента.
+// Допускаются только латинские буквы, цифры и символы "-", "_", ".", ":" (идентификатор попадает в журнал и заголовки ответа)
+func ValidRequestID(id string) bool {
+	if len(id) == 0 || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// RequestIDOrNew - метод получения идентификатора запроса: переданного клиентом (если корректен), либо нового
+func RequestIDOrNew(id string) string {
+	if ValidRequestID(id) {
+		return id
+	}
+	return NewRequestID()
+}
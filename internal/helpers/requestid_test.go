@@ -0,0 +1,37 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidRequestID(t *testing.T) {
+	testCases := []struct {
+		name  string
+		id    string
+		valid bool
+	}{
+		{"UUID", "c9c5cb66-dbbc-4d57-8cb9-55f58096f79b", true},
+		{"Custom ID", "edge-01:req_42.7", true},
+		{"Empty ID", "", false},
+		{"Too long ID", strings.Repeat("a", 129), false},
+		{"Line break", "id\nfake log line", false},
+		{"Spaces", "request id", false},
+		{"Non ASCII", "запрос", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.valid, ValidRequestID(tc.id))
+		})
+	}
+}
+
+func TestRequestIDOrNew(t *testing.T) {
+	assert.Equal(t, "edge-01", RequestIDOrNew("edge-01"))
+
+	generated := RequestIDOrNew("bad id")
+	assert.True(t, ValidRequestID(generated))
+	assert.NotEqual(t, generated, RequestIDOrNew("bad id"))
+}
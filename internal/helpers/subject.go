@@ -0,0 +1,34 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseClientSubjects - метод разбора списка клиентов через точку с запятой. Клиент задается субъектом
+// сертификата в форме RFC 2253 (CN=client,O=Org) или только именем владельца (client).
+// Пробелы вокруг разделителей субъекта удаляются
+func ParseClientSubjects(subjects string) ([]string, error) {
+	var result []string
+	for _, subject := range strings.Split(subjects, ";") {
+		subject = strings.TrimSpace(subject)
+		if len(subject) == 0 {
+			continue
+		}
+		if !strings.Contains(subject, "=") {
+			result = append(result, subject)
+			continue
+		}
+		parts := strings.Split(subject, ",")
+		for i, part := range parts {
+			key, value, ok := strings.Cut(part, "=")
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+			if !ok || len(key) == 0 || len(value) == 0 {
+				return nil, fmt.Errorf("invalid subject %q: expected attribute=value", subject)
+			}
+			parts[i] = key + "=" + value
+		}
+		result = append(result, strings.Join(parts, ","))
+	}
+	return result, nil
+}
@@ -0,0 +1,34 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClientSubjects(t *testing.T) {
+	testCases := []struct {
+		name      string
+		subjects  string
+		expected  []string
+		wantError bool
+	}{
+		{"Subjects and common name #1 (good)", "CN=stats, O=Ops ; monitoring", []string{"CN=stats,O=Ops", "monitoring"}, false},
+		{"Empty list #2 (good)", " ; ", nil, false},
+		{"Attribute without value #3 (bad)", "CN=", nil, true},
+		{"Part without attribute #4 (bad)", "CN=stats,Ops", nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			subjects, err := ParseClientSubjects(tc.subjects)
+			if tc.wantError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, subjects)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Package helpers предоставляет функциональность вспомогательных функций приложения
+package helpers
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ParseSubnet - метод разбора доверенной подсети в CIDR нотации
+func ParseSubnet(subnet string) (*net.IPNet, error) {
+	subnet = strings.TrimSpace(subnet)
+	if len(subnet) == 0 {
+		return nil, fmt.Errorf("subnet is empty")
+	}
+	_, ipNet, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subnet: %w", err)
+	}
+	return ipNet, nil
+}
+
+// SubnetContains - метод проверки вхождения IP адреса в подсеть
+func SubnetContains(subnet *net.IPNet, addr string) bool {
+	if subnet == nil {
+		return false
+	}
+	ip := net.ParseIP(strings.TrimSpace(addr))
+	if ip == nil {
+		return false
+	}
+	return subnet.Contains(ip)
+}
+
+// ParseSubnets - метод разбора списка подсетей в CIDR нотации через запятую (IPv4 и IPv6)
+func ParseSubnets(subnets string) ([]*net.IPNet, error) {
+	var result []*net.IPNet
+	for _, subnet := range strings.Split(subnets, ",") {
+		if len(strings.TrimSpace(subnet)) == 0 {
+			continue
+		}
+		ipNet, err := ParseSubnet(subnet)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ipNet)
+	}
+	return result, nil
+}
+
+// SubnetsContain - метод проверки вхождения IP адреса в одну из подсетей
+func SubnetsContain(subnets []*net.IPNet, addr string) bool {
+	for _, subnet := range subnets {
+		if SubnetContains(subnet, addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSubnet(t *testing.T) {
+	testCases := []struct {
+		name      string
+		subnet    string
+		expected  string
+		wantError bool
+	}{
+		{"IPv4 subnet", "192.168.1.0/24", "192.168.1.0/24", false},
+		{"IPv4 with spaces", " 10.0.0.0/8 ", "10.0.0.0/8", false},
+		{"IPv6 subnet", "fd00::/8", "fd00::/8", false},
+		{"Host address", "192.168.1.15/24", "192.168.1.0/24", false},
+		{"Empty subnet", "", "", true},
+		{"Without mask", "192.168.1.1", "", true},
+		{"Invalid subnet", "subnet", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			subnet, err := ParseSubnet(tc.subnet)
+			if tc.wantError {
+				assert.Error(t, err)
+				assert.Nil(t, subnet)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, subnet.String())
+		})
+	}
+}
+
+func TestSubnetContains(t *testing.T) {
+	subnet, err := ParseSubnet("192.168.1.0/24")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		addr     string
+		expected bool
+	}{
+		{"Inside subnet", "192.168.1.10", true},
+		{"Outside subnet", "192.168.2.10", false},
+		{"Invalid address", "address", false},
+		{"Empty address", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, SubnetContains(subnet, tc.addr))
+		})
+	}
+
+	t.Run("Nil subnet", func(t *testing.T) {
+		assert.False(t, SubnetContains(nil, "192.168.1.10"))
+	})
+}
@@ -12,6 +12,9 @@ import (
 // JWTExpire - время жизни токена
 const JWTExpire = time.Hour * 3
 
+// MaxShortURLLen - максимальная длина короткой ссылки (длина base64 от MD5)
+const MaxShortURLLen = 24
+
 // MakeShortURL - метод формирования короткого URL c использованием базового URL и длинны необходимого короткого URL
 func MakeShortURL(urlValue string, size int) (string, error) {
 	// Проверка корректности размера
@@ -49,8 +52,8 @@ func checkMD5HashSize(size int) error {
 	if size <= 0 {
 		return fmt.Errorf("size must be positive, got %d", size)
 	}
-	if size > 24 { // Максимальная длина для base64 от MD5 (24 символа)
-		return fmt.Errorf("size too large, maximum is 24, got %d", size)
+	if size > MaxShortURLLen {
+		return fmt.Errorf("size too large, maximum is %d, got %d", MaxShortURLLen, size)
 	}
 	return nil
 }
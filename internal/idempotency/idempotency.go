@@ -0,0 +1,118 @@
+// Package idempotency предоставляет хранение результатов запросов по ключу идемпотентности.
+// Повторный запрос с тем же ключом получает сохраненный ответ вместо повторного выполнения.
+// Состояние хранится в памяти процесса либо в PostgreSQL (для нескольких экземпляров сервиса)
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// Параметры хранения ключей идемпотентности
+const (
+	// HeaderName - HTTP заголовок с ключом идемпотентности
+	HeaderName = "Idempotency-Key"
+	// MetadataKey - ключ идемпотентности в метаданных GRPC запроса
+	MetadataKey = "idempotency-key"
+	// ReplayedHeader - HTTP заголовок (ключ метаданных GRPC) признака повторно отправленного ответа
+	ReplayedHeader = "Idempotent-Replayed"
+	// MaxKeyLength - максимальная длина ключа идемпотентности
+	MaxKeyLength = 255
+	// LockTimeout - время, в течение которого ключ считается занятым выполняемым запросом.
+	// Ограничивает блокировку ключа, если экземпляр сервиса остановился, не завершив запрос
+	LockTimeout = time.Minute
+)
+
+// ErrKeyMismatch - ошибка повторного использования ключа с другим запросом
+var ErrKeyMismatch = errors.New("idempotency key reused with a different request")
+
+// ErrInProgress - ошибка повторного запроса, пока первый запрос с тем же ключом еще выполняется
+var ErrInProgress = errors.New("request with this idempotency key is in progress")
+
+// Record - модель сохраненного результата запроса
+type Record struct {
+	Fingerprint string            // отпечаток запроса (метод, путь, тело)
+	Completed   bool              // признак завершения запроса
+	Status      int               // HTTP статус (код GRPC) ответа
+	Header      map[string]string // заголовки ответа
+	Body        []byte            // тело ответа
+}
+
+// Store - интерфейс хранилища результатов запросов
+type Store interface {
+	// Begin - метод резервирования ключа key за запросом с отпечатком fingerprint.
+	// Возвращает nil, если ключ свободен (запрос нужно выполнить), иначе - ранее сохраненную запись
+	Begin(ctx context.Context, key string, fingerprint string) (*Record, error)
+	// Complete - метод сохранения результата запроса на время ttl
+	Complete(ctx context.Context, key string, record Record, ttl time.Duration) error
+	// Abort - метод освобождения ключа без сохранения результата (запрос можно повторить)
+	Abort(ctx context.Context, key string) error
+}
+
+// Keeper - модель хранения результатов запросов с заданным временем жизни
+type Keeper struct {
+	Store Store         // хранилище результатов
+	TTL   time.Duration // время хранения результата
+}
+
+// NewKeeper - метод создания объекта хранения результатов запросов
+func NewKeeper(store Store, ttl time.Duration) *Keeper {
+	return &Keeper{Store: store, TTL: ttl}
+}
+
+// Begin - метод начала выполнения запроса с ключом key.
+// Возвращает сохраненный результат для повтора, ErrKeyMismatch или ErrInProgress
+func (k *Keeper) Begin(ctx context.Context, key string, fingerprint string) (*Record, error) {
+	record, err := k.Store.Begin(ctx, key, fingerprint)
+	if err != nil || record == nil {
+		return nil, err
+	}
+	if record.Fingerprint != fingerprint {
+		return nil, ErrKeyMismatch
+	}
+	if !record.Completed {
+		return nil, ErrInProgress
+	}
+	return record, nil
+}
+
+// Complete - метод сохранения результата запроса с ключом key
+func (k *Keeper) Complete(ctx context.Context, key string, record Record) error {
+	record.Completed = true
+	return k.Store.Complete(ctx, key, record, k.TTL)
+}
+
+// Abort - метод освобождения ключа key без сохранения результата
+func (k *Keeper) Abort(ctx context.Context, key string) error {
+	return k.Store.Abort(ctx, key)
+}
+
+// ValidKey - метод проверки ключа идемпотентности (непустой, не длиннее MaxKeyLength, печатные ASCII символы)
+func ValidKey(key string) bool {
+	if len(key) == 0 || len(key) > MaxKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// Fingerprint - метод формирования отпечатка запроса по его частям
+func Fingerprint(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		// длина части исключает совпадение отпечатков при разном разбиении на части
+		var size [8]byte
+		binary.LittleEndian.PutUint64(size[:], uint64(len(part)))
+		h.Write(size[:])
+		h.Write(part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,83 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeeper(t *testing.T) {
+	now := time.Date(2025, 10, 19, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	keeper := NewKeeper(store, time.Hour)
+	ctx := context.Background()
+	fingerprint := Fingerprint([]byte("POST"), []byte("/"), []byte("https://ya.ru"))
+
+	// первый запрос выполняется
+	record, err := keeper.Begin(ctx, "key", fingerprint)
+	require.NoError(t, err)
+	assert.Nil(t, record)
+
+	// пока запрос выполняется, повтор отклоняется
+	_, err = keeper.Begin(ctx, "key", fingerprint)
+	assert.ErrorIs(t, err, ErrInProgress)
+
+	require.NoError(t, keeper.Complete(ctx, "key", Record{Fingerprint: fingerprint, Status: 201, Body: []byte("short")}))
+
+	// повтор получает сохраненный ответ
+	record, err = keeper.Begin(ctx, "key", fingerprint)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, 201, record.Status)
+	assert.Equal(t, []byte("short"), record.Body)
+
+	// ключ с другим запросом отклоняется
+	_, err = keeper.Begin(ctx, "key", Fingerprint([]byte("POST"), []byte("/"), []byte("https://google.com")))
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+
+	// по истечении времени хранения ключ освобождается
+	now = now.Add(time.Hour)
+	record, err = keeper.Begin(ctx, "key", fingerprint)
+	require.NoError(t, err)
+	assert.Nil(t, record)
+
+	// после отмены запрос можно повторить
+	require.NoError(t, keeper.Abort(ctx, "key"))
+	record, err = keeper.Begin(ctx, "key", fingerprint)
+	require.NoError(t, err)
+	assert.Nil(t, record)
+
+	// незавершенный запрос освобождает ключ по истечении времени блокировки
+	now = now.Add(LockTimeout)
+	record, err = keeper.Begin(ctx, "key", fingerprint)
+	require.NoError(t, err)
+	assert.Nil(t, record)
+}
+
+func TestValidKey(t *testing.T) {
+	testCases := []struct {
+		name  string
+		key   string
+		valid bool
+	}{
+		{"UUID key #1 (good)", "6f1c3f1e-8a4b-4f59-9d5c-0f0e3c1d2b7a", true},
+		{"Empty key #2 (bad)", "", false},
+		{"Key with space #3 (bad)", "my key", false},
+		{"Long key #4 (bad)", string(make([]byte, MaxKeyLength+1)), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.valid, ValidKey(tc.key))
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t, Fingerprint([]byte("ab"), []byte("c")), Fingerprint([]byte("ab"), []byte("c")))
+	assert.NotEqual(t, Fingerprint([]byte("ab"), []byte("c")), Fingerprint([]byte("a"), []byte("bc")))
+}
@@ -0,0 +1,81 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Внутренние константы хранилища в памяти
+const (
+	// sweepInterval - количество запросов между очистками устаревших записей
+	sweepInterval = 1024
+)
+
+// entry - запись хранилища в памяти
+type entry struct {
+	record  Record    // результат запроса
+	expires time.Time // время устаревания записи
+}
+
+// MemoryStore - хранилище результатов запросов в памяти процесса
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	begins  int              // количество запросов с последней очистки
+	now     func() time.Time // источник времени
+}
+
+// NewMemoryStore - метод создания хранилища результатов запросов в памяти процесса
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]*entry), now: time.Now}
+}
+
+// Begin - метод резервирования ключа key за запросом с отпечатком fingerprint
+func (s *MemoryStore) Begin(ctx context.Context, key string, fingerprint string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.begins++
+	if s.begins >= sweepInterval {
+		s.sweep(now)
+	}
+
+	if e, ok := s.entries[key]; ok && now.Before(e.expires) {
+		record := e.record
+		return &record, nil
+	}
+	s.entries[key] = &entry{record: Record{Fingerprint: fingerprint}, expires: now.Add(LockTimeout)}
+	return nil, nil
+}
+
+// Complete - метод сохранения результата запроса на время ttl
+func (s *MemoryStore) Complete(ctx context.Context, key string, record Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = &entry{record: record, expires: s.now().Add(ttl)}
+	return nil
+}
+
+// Abort - метод освобождения ключа без сохранения результата
+func (s *MemoryStore) Abort(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok && !e.record.Completed {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// sweep - метод удаления устаревших записей
+func (s *MemoryStore) sweep(now time.Time) {
+	s.begins = 0
+	for key, e := range s.entries {
+		if !now.Before(e.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Используемые SQL запросы
+const (
+	// BeginKey - SQL запрос резервирования ключа (занимает отсутствующий или устаревший ключ).
+	// Таблица idempotency_keys создается миграциями хранилища (internal/storage/migrations)
+	BeginKey = `INSERT INTO idempotency_keys AS k (key, fingerprint, completed, status, header, body, expires_at)
+					VALUES ($1, $2, FALSE, 0, NULL, NULL, now() + $3::double precision * interval '1 second')
+					ON CONFLICT (key) DO UPDATE SET
+						fingerprint = EXCLUDED.fingerprint,
+						completed = FALSE,
+						status = 0,
+						header = NULL,
+						body = NULL,
+						expires_at = EXCLUDED.expires_at
+					WHERE k.expires_at <= now()
+					RETURNING key;`
+	// GetKey - SQL запрос получения сохраненного результата по ключу
+	GetKey = `SELECT fingerprint, completed, status, header, body FROM idempotency_keys WHERE key = $1;`
+	// CompleteKey - SQL запрос сохранения результата запроса
+	CompleteKey = `UPDATE idempotency_keys SET
+						completed = TRUE, status = $2, header = $3, body = $4,
+						expires_at = now() + $5::double precision * interval '1 second'
+					WHERE key = $1;`
+	// AbortKey - SQL запрос освобождения незавершенного ключа
+	AbortKey = `DELETE FROM idempotency_keys WHERE key = $1 AND NOT completed;`
+	// DeleteExpired - SQL запрос удаления устаревших записей
+	DeleteExpired = `DELETE FROM idempotency_keys WHERE expires_at <= now();`
+)
+
+// PostgresStore - хранилище результатов запросов в PostgreSQL (общее для нескольких экземпляров сервиса)
+type PostgresStore struct {
+	Pool *pgxpool.Pool // пул подключений
+}
+
+// NewPostgresStore - метод создания хранилища результатов запросов в PostgreSQL
+func NewPostgresStore(ctx context.Context, dsn string) (*PostgresStore, error) {
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create connection pool: %w", err)
+	}
+	return &PostgresStore{Pool: pool}, nil
+}
+
+// Begin - метод резервирования ключа key за запросом с отпечатком fingerprint
+func (s *PostgresStore) Begin(ctx context.Context, key string, fingerprint string) (*Record, error) {
+	var reserved string
+	err := s.Pool.QueryRow(ctx, BeginKey, key, fingerprint, LockTimeout.Seconds()).Scan(&reserved)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	// ключ занят другим запросом
+	var record Record
+	err = s.Pool.QueryRow(ctx, GetKey, key).Scan(&record.Fingerprint, &record.Completed, &record.Status, &record.Header, &record.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	return &record, nil
+}
+
+// Complete - метод сохранения результата запроса на время ttl
+func (s *PostgresStore) Complete(ctx context.Context, key string, record Record, ttl time.Duration) error {
+	if _, err := s.Pool.Exec(ctx, CompleteKey, key, record.Status, record.Header, record.Body, ttl.Seconds()); err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Abort - метод освобождения ключа без сохранения результата
+func (s *PostgresStore) Abort(ctx context.Context, key string) error {
+	if _, err := s.Pool.Exec(ctx, AbortKey, key); err != nil {
+		return fmt.Errorf("failed to abort idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired - метод удаления устаревших записей
+func (s *PostgresStore) DeleteExpired(ctx context.Context) error {
+	if _, err := s.Pool.Exec(ctx, DeleteExpired); err != nil {
+		return fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+	return nil
+}
+
+// Close - метод закрытия пула подключений
+func (s *PostgresStore) Close() {
+	s.Pool.Close()
+}
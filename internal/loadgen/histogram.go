@@ -0,0 +1,123 @@
+package loadgen
+
+import (
+	"math"
+	"time"
+)
+
+// Параметры гистограммы задержек
+const (
+	// histogramMin - нижняя граница первого интервала гистограммы
+	histogramMin = 10 * time.Microsecond
+	// histogramGrowth - отношение границ соседних интервалов, определяет точность перцентилей (5%)
+	histogramGrowth = 1.05
+)
+
+// Histogram - гистограмма задержек с интервалами, растущими в геометрической прогрессии.
+// Занимает фиксированный объем памяти независимо от количества измерений
+type Histogram struct {
+	counts []int64       // количество измерений по интервалам
+	total  int64         // количество измерений
+	sum    time.Duration // сумма задержек
+	min    time.Duration // минимальная задержка
+	max    time.Duration // максимальная задержка
+}
+
+// Record - метод добавления измерения задержки
+func (h *Histogram) Record(d time.Duration) {
+	i := bucket(d)
+	if i >= len(h.counts) {
+		h.counts = append(h.counts, make([]int64, i+1-len(h.counts))...)
+	}
+	h.counts[i]++
+	if h.total == 0 || d < h.min {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+	h.total++
+	h.sum += d
+}
+
+// Merge - метод добавления измерений другой гистограммы
+func (h *Histogram) Merge(other *Histogram) {
+	if other.total == 0 {
+		return
+	}
+	if len(other.counts) > len(h.counts) {
+		h.counts = append(h.counts, make([]int64, len(other.counts)-len(h.counts))...)
+	}
+	for i, count := range other.counts {
+		h.counts[i] += count
+	}
+	if h.total == 0 || other.min < h.min {
+		h.min = other.min
+	}
+	h.max = max(h.max, other.max)
+	h.total += other.total
+	h.sum += other.sum
+}
+
+// Count - метод получения количества измерений
+func (h *Histogram) Count() int64 {
+	return h.total
+}
+
+// Mean - метод получения средней задержки
+func (h *Histogram) Mean() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return h.sum / time.Duration(h.total)
+}
+
+// Quantile - метод получения перцентиля q (0..1) задержки: верхняя граница интервала, в который попадает перцентиль
+func (h *Histogram) Quantile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(q * float64(h.total)))
+	rank = min(max(rank, 1), h.total)
+	var seen int64
+	for i, count := range h.counts {
+		seen += count
+		if seen >= rank {
+			return min(max(upperBound(i), h.min), h.max)
+		}
+	}
+	return h.max
+}
+
+// Buckets - метод получения распределения задержек по интервалам с верхними границами bounds
+// (последний интервал - задержки больше последней границы). Измерения интервала гистограммы относятся
+// к интервалу распределения по середине интервала гистограммы, точность ограничена его шириной
+func (h *Histogram) Buckets(bounds []time.Duration) []int64 {
+	result := make([]int64, len(bounds)+1)
+	for i, count := range h.counts {
+		if count == 0 {
+			continue
+		}
+		middle := time.Duration(float64(upperBound(i)) / math.Sqrt(histogramGrowth))
+		middle = min(max(middle, h.min), h.max)
+		j := 0
+		for j < len(bounds) && middle > bounds[j] {
+			j++
+		}
+		result[j] += count
+	}
+	return result
+}
+
+// bucket - метод получения номера интервала для задержки d
+func bucket(d time.Duration) int {
+	if d <= histogramMin {
+		return 0
+	}
+	return int(math.Ceil(math.Log(float64(d)/float64(histogramMin)) / math.Log(histogramGrowth)))
+}
+
+// upperBound - метод получения верхней границы интервала i
+func upperBound(i int) time.Duration {
+	return time.Duration(float64(histogramMin) * math.Pow(histogramGrowth, float64(i)))
+}
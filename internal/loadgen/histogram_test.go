@@ -0,0 +1,57 @@
+package loadgen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram_Quantile(t *testing.T) {
+	var first, second Histogram
+	for i := 1; i <= 500; i++ {
+		first.Record(time.Duration(i) * time.Millisecond)
+	}
+	for i := 501; i <= 1000; i++ {
+		second.Record(time.Duration(i) * time.Millisecond)
+	}
+	first.Merge(&second)
+
+	testCases := []struct {
+		name     string
+		quantile float64
+		expected time.Duration
+	}{
+		{"Min #1 (good)", 0, time.Millisecond},
+		{"Median #2 (good)", 0.5, 500 * time.Millisecond},
+		{"P99 #3 (good)", 0.99, 990 * time.Millisecond},
+		{"Max #4 (good)", 1, time.Second},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// точность перцентиля ограничена шириной интервала гистограммы
+			assert.InEpsilon(t, float64(tc.expected), float64(first.Quantile(tc.quantile)), histogramGrowth-1)
+		})
+	}
+	assert.Equal(t, int64(1000), first.Count())
+	assert.Equal(t, 500500*time.Microsecond, first.Mean())
+	expected := []int64{1, 1, 3, 5, 10, 30, 50, 100, 300, 500, 0}
+	buckets := first.Buckets([]time.Duration{
+		time.Millisecond, 2 * time.Millisecond, 5 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond,
+		50 * time.Millisecond, 100 * time.Millisecond, 200 * time.Millisecond, 500 * time.Millisecond, time.Second,
+	})
+	var total int64
+	for i := range expected {
+		total += buckets[i]
+		assert.InDelta(t, expected[i], buckets[i], float64(expected[i])*(histogramGrowth-1)+1)
+	}
+	assert.Equal(t, int64(1000), total)
+	assert.Zero(t, buckets[len(buckets)-1])
+}
+
+func TestHistogram_Empty(t *testing.T) {
+	var h Histogram
+	assert.Zero(t, h.Quantile(0.5))
+	assert.Zero(t, h.Mean())
+	assert.Equal(t, []int64{0, 0}, h.Buckets([]time.Duration{time.Millisecond}))
+}
@@ -0,0 +1,268 @@
+// Package loadgen предоставляет генератор нагрузки на сервис сокращения ссылок.
+// Нагрузка формируется параллельными исполнителями по заданному соотношению операций через HTTP или GRPC API
+// в течение заданного времени либо до заданного количества запросов. Результат - отчет с перцентилями задержек,
+// гистограммой и распределением ошибок, который можно сравнить с отчетом предыдущего запуска
+package loadgen
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/denmor86/go-url-shortener/pkg/client"
+)
+
+// Параметры нагрузки по-умолчанию
+const (
+	DefaultConcurrency = 10
+	DefaultDuration    = 30 * time.Second
+	DefaultBatchSize   = 100
+	DefaultTimeout     = 10 * time.Second
+
+	// maxIDs - максимальное количество коротких ссылок, запоминаемых исполнителем для переходов и удаления
+	maxIDs = 1000
+)
+
+// Config - параметры нагрузки
+type Config struct {
+	Transport   string        // транспорт: http или grpc
+	Target      string        // адрес сервиса: базовый URL для HTTP, host:port для GRPC
+	TLSConfig   *tls.Config   // настройки TLS (nil - по-умолчанию)
+	Concurrency int           // количество параллельных исполнителей (пользователей сервиса)
+	Duration    time.Duration // время нагрузки (если не задано количество запросов)
+	Requests    int           // количество запросов (0 - ограничение по времени)
+	Mix         Mix           // соотношение операций
+	BatchSize   int           // количество ссылок в пакетном запросе
+	Timeout     time.Duration // время выполнения одного запроса
+	Progress    io.Writer     // вывод хода нагрузки раз в секунду (nil - не выводится)
+}
+
+// Run - метод выполнения нагрузки. Перед нагрузкой проверяется доступность сервиса
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	workers := make([]*worker, 0, cfg.Concurrency)
+	defer func() {
+		for _, w := range workers {
+			w.api.Close()
+		}
+	}()
+	prefix := uuid.New().String()[:8]
+	for i := 0; i < cfg.Concurrency; i++ {
+		// повторы отключены: каждая попытка учитывается в отчете
+		api, err := client.New(cfg.Transport, cfg.Target, client.Options{TLSConfig: cfg.TLSConfig, Retry: client.RetryPolicy{MaxAttempts: 1}})
+		if err != nil {
+			return nil, err
+		}
+		workers = append(workers, &worker{
+			cfg:    &cfg,
+			api:    api,
+			prefix: fmt.Sprintf("https://load.example/%s/%d/", prefix, i),
+			stats:  newStats(),
+		})
+	}
+	if err := workers[0].do(ctx, OpShorten); err != nil {
+		return nil, fmt.Errorf("target %s is not available: %w", cfg.Target, err)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	if cfg.Requests == 0 {
+		runCtx, cancel = context.WithTimeout(runCtx, cfg.Duration)
+	}
+	defer cancel()
+
+	var issued, done, failed atomic.Int64
+	next := func() bool {
+		if runCtx.Err() != nil {
+			return false
+		}
+		return cfg.Requests == 0 || issued.Add(1) <= int64(cfg.Requests)
+	}
+
+	started := time.Now()
+	stopProgress := progress(cfg.Progress, started, &done, &failed)
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.run(runCtx, next, &done, &failed)
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(started)
+	stopProgress()
+
+	total := newStats()
+	for _, w := range workers {
+		total.merge(w.stats)
+	}
+	return newReport(&cfg, started, elapsed, total), nil
+}
+
+// validate - метод проверки и заполнения параметров нагрузки значениями по-умолчанию
+func (cfg *Config) validate() error {
+	if cfg.Transport != client.TransportHTTP && cfg.Transport != client.TransportGRPC {
+		return fmt.Errorf("unknown transport %q (http, grpc)", cfg.Transport)
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultConcurrency
+	}
+	if cfg.Requests < 0 {
+		return fmt.Errorf("requests must not be negative")
+	}
+	if cfg.Requests == 0 && cfg.Duration <= 0 {
+		cfg.Duration = DefaultDuration
+	}
+	if len(cfg.Mix) == 0 {
+		cfg.Mix, _ = ParseMix(DefaultMix)
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	return nil
+}
+
+// progress - метод запуска вывода хода нагрузки раз в секунду, возвращает метод остановки вывода
+func progress(w io.Writer, started time.Time, done, failed *atomic.Int64) func() {
+	if w == nil {
+		return func() {}
+	}
+	stop := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				elapsed := time.Since(started)
+				fmt.Fprintf(w, "%6s  requests %-8d errors %-6d rps %.1f\n", elapsed.Round(time.Second),
+					done.Load(), failed.Load(), float64(done.Load())/elapsed.Seconds())
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-finished
+	}
+}
+
+// worker - исполнитель нагрузки от имени одного пользователя сервиса
+type worker struct {
+	cfg    *Config
+	api    client.Client
+	prefix string   // префикс формируемых URL
+	count  int      // количество сформированных URL
+	ids    []string // короткие ссылки пользователя для переходов и удаления
+	stats  stats
+}
+
+// run - метод выполнения операций, пока next разрешает следующий запрос
+func (w *worker) run(ctx context.Context, next func() bool, done, failed *atomic.Int64) {
+	for next() {
+		op := w.cfg.Mix.pick()
+		// переход и удаление требуют созданных пользователем ссылок
+		if (op == OpRedirect || op == OpDelete) && len(w.ids) == 0 {
+			op = OpShorten
+		}
+		started := time.Now()
+		err := w.do(ctx, op)
+		elapsed := time.Since(started)
+		// запросы, прерванные окончанием нагрузки, не учитываются
+		if ctx.Err() != nil {
+			return
+		}
+		w.stats.record(op, elapsed, err)
+		done.Add(1)
+		if err != nil {
+			failed.Add(1)
+		}
+	}
+}
+
+// do - метод выполнения операции op
+func (w *worker) do(ctx context.Context, op Operation) error {
+	ctx, cancel := context.WithTimeout(ctx, w.cfg.Timeout)
+	defer cancel()
+	switch op {
+	case OpShorten:
+		shortURL, err := w.api.Shorten(ctx, w.nextURL())
+		if err != nil && !errors.Is(err, client.ErrUniqueViolation) {
+			return err
+		}
+		w.remember(shortURL)
+	case OpBatch:
+		items := make([]client.BatchItem, 0, w.cfg.BatchSize)
+		for i := 0; i < w.cfg.BatchSize; i++ {
+			items = append(items, client.BatchItem{ID: fmt.Sprint(i), URL: w.nextURL()})
+		}
+		results, err := w.api.ShortenBatch(ctx, items)
+		if err != nil {
+			return err
+		}
+		for _, result := range results {
+			w.remember(result.ShortURL)
+		}
+	case OpRedirect:
+		_, err := w.api.Expand(ctx, w.ids[rand.N(len(w.ids))])
+		return err
+	case OpList:
+		_, err := w.api.List(ctx)
+		return err
+	case OpDelete:
+		id := w.ids[len(w.ids)-1]
+		w.ids = w.ids[:len(w.ids)-1]
+		return w.api.Delete(ctx, []string{id})
+	}
+	return nil
+}
+
+// nextURL - метод формирования нового уникального URL
+func (w *worker) nextURL() string {
+	w.count++
+	return fmt.Sprint(w.prefix, w.count)
+}
+
+// remember - метод сохранения короткой ссылки (при переполнении заменяется случайная)
+func (w *worker) remember(shortURL string) {
+	if len(shortURL) == 0 {
+		return
+	}
+	id := client.ShortID(shortURL)
+	if len(w.ids) < maxIDs {
+		w.ids = append(w.ids, id)
+		return
+	}
+	w.ids[rand.N(len(w.ids))] = id
+}
+
+// errorKind - метод получения вида ошибки для распределения ошибок в отчете
+func errorKind(err error) string {
+	var serviceErr *client.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded:
+		return "timeout"
+	case errors.As(err, &serviceErr):
+		return serviceErr.Kind.Error()
+	default:
+		return "transport error"
+	}
+}
@@ -0,0 +1,96 @@
+package loadgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/denmor86/go-url-shortener/pkg/client"
+)
+
+// newTestService - метод создания тестового HTTP сервиса: каждый десятый переход отклоняется с 410 Gone
+func newTestService(t *testing.T) *httptest.Server {
+	var count, redirects atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/shorten":
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{"result": fmt.Sprintf("http://short/%d", count.Add(1))})
+		case r.URL.Path == "/api/shorten/batch":
+			var items []client.BatchItem
+			json.NewDecoder(r.Body).Decode(&items)
+			results := make([]client.BatchResult, 0, len(items))
+			for _, item := range items {
+				results = append(results, client.BatchResult{ID: item.ID, ShortURL: fmt.Sprintf("http://short/%d", count.Add(1))})
+			}
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(results)
+		case r.URL.Path == "/api/user/urls" && r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/api/user/urls" && r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusAccepted)
+		case strings.Count(r.URL.Path, "/") == 1 && r.Method == http.MethodGet:
+			if redirects.Add(1)%10 == 0 {
+				http.Error(w, "URL is deleted", http.StatusGone)
+				return
+			}
+			w.Header().Set("Location", "https://example.com")
+			w.WriteHeader(http.StatusTemporaryRedirect)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRun(t *testing.T) {
+	server := newTestService(t)
+
+	testCases := []struct {
+		name     string
+		cfg      Config
+		requests int64
+		wantErr  bool
+	}{
+		{"Requests limit #1 (good)", Config{Transport: client.TransportHTTP, Target: server.URL, Concurrency: 4, Requests: 200, BatchSize: 5}, 200, false},
+		{"Duration limit #2 (good)", Config{Transport: client.TransportHTTP, Target: server.URL, Concurrency: 2, Duration: 100 * time.Millisecond}, -1, false},
+		{"Unknown transport #3 (bad)", Config{Transport: "udp", Target: server.URL}, 0, true},
+		{"Unavailable target #4 (bad)", Config{Transport: client.TransportHTTP, Target: "http://127.0.0.1:1", Requests: 1}, 0, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report, err := Run(context.Background(), tc.cfg)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tc.requests >= 0 {
+				assert.Equal(t, tc.requests, report.Total.Requests)
+			} else {
+				assert.Positive(t, report.Total.Requests)
+			}
+			var requests, errors int64
+			for _, op := range report.Operations {
+				requests += op.Requests
+				errors += op.Errors
+			}
+			assert.Equal(t, report.Total.Requests, requests)
+			assert.Equal(t, report.Total.Errors, errors)
+			if redirect, ok := report.Operations[OpRedirect]; ok && redirect.Errors > 0 {
+				assert.Equal(t, redirect.Errors, redirect.ErrorKind[client.ErrDeletedViolation.Error()])
+			}
+			assert.Positive(t, report.Total.Latency.P50)
+		})
+	}
+}
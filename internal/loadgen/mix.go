@@ -0,0 +1,101 @@
+package loadgen
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// Operation - операция нагрузки
+type Operation string
+
+// Операции нагрузки
+const (
+	// OpShorten - сокращение одиночной ссылки
+	OpShorten Operation = "shorten"
+	// OpBatch - пакетное сокращение ссылок
+	OpBatch Operation = "batch"
+	// OpRedirect - переход по короткой ссылке
+	OpRedirect Operation = "redirect"
+	// OpList - получение ссылок пользователя
+	OpList Operation = "list"
+	// OpDelete - удаление ссылки пользователя
+	OpDelete Operation = "delete"
+)
+
+// Operations - все операции нагрузки в порядке вывода
+var Operations = []Operation{OpShorten, OpBatch, OpRedirect, OpList, OpDelete}
+
+// DefaultMix - соотношение операций по-умолчанию
+const DefaultMix = "shorten=30,batch=5,redirect=50,list=10,delete=5"
+
+// Mix - соотношение операций нагрузки (веса операций)
+type Mix map[Operation]int
+
+// ParseMix - метод разбора соотношения операций в формате "shorten=30,redirect=70"
+func ParseMix(s string) (Mix, error) {
+	mix := Mix{}
+	total := 0
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid mix item %q, expected operation=weight", part)
+		}
+		op := Operation(strings.TrimSpace(name))
+		if !op.valid() {
+			return nil, fmt.Errorf("unknown operation %q in mix", op)
+		}
+		weight, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight %q for operation %s", value, op)
+		}
+		mix[op] += weight
+		total += weight
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("mix %q has no operations", s)
+	}
+	return mix, nil
+}
+
+// String - метод получения соотношения операций в формате "shorten=30,redirect=70"
+func (m Mix) String() string {
+	parts := make([]string, 0, len(m))
+	for _, op := range Operations {
+		if m[op] > 0 {
+			parts = append(parts, fmt.Sprintf("%s=%d", op, m[op]))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// pick - метод случайного выбора операции с учетом весов
+func (m Mix) pick() Operation {
+	total := 0
+	for _, op := range Operations {
+		total += m[op]
+	}
+	n := rand.N(total)
+	for _, op := range Operations {
+		if n < m[op] {
+			return op
+		}
+		n -= m[op]
+	}
+	return OpShorten
+}
+
+// valid - метод проверки имени операции
+func (op Operation) valid() bool {
+	for _, known := range Operations {
+		if op == known {
+			return true
+		}
+	}
+	return false
+}
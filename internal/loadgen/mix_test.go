@@ -0,0 +1,44 @@
+package loadgen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMix(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected Mix
+		wantErr  bool
+	}{
+		{"Default mix #1 (good)", DefaultMix, Mix{OpShorten: 30, OpBatch: 5, OpRedirect: 50, OpList: 10, OpDelete: 5}, false},
+		{"Spaces and zero weight #2 (good)", " shorten = 1, list=0 ", Mix{OpShorten: 1, OpList: 0}, false},
+		{"Unknown operation #3 (bad)", "shorten=1,ping=1", nil, true},
+		{"Invalid weight #4 (bad)", "shorten=-1", nil, true},
+		{"Missing weight #5 (bad)", "shorten", nil, true},
+		{"Zero total #6 (bad)", "shorten=0", nil, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mix, err := ParseMix(tc.value)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, mix)
+		})
+	}
+}
+
+func TestMix_Pick(t *testing.T) {
+	mix, err := ParseMix("redirect=1,list=0")
+	require.NoError(t, err)
+	assert.Equal(t, "redirect=1", mix.String())
+	for i := 0; i < 100; i++ {
+		require.Equal(t, OpRedirect, mix.pick())
+	}
+}
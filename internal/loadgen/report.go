@@ -0,0 +1,305 @@
+package loadgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// histogramBounds - верхние границы интервалов гистограммы задержек в отчете
+var histogramBounds = []time.Duration{
+	time.Millisecond, 2 * time.Millisecond, 5 * time.Millisecond,
+	10 * time.Millisecond, 20 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 200 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2 * time.Second, 5 * time.Second,
+}
+
+// Report - отчет о нагрузке. Задержки учитываются только для успешных запросов
+type Report struct {
+	Transport   string                         `json:"transport"`       // транспорт
+	Target      string                         `json:"target"`          // адрес сервиса
+	Concurrency int                            `json:"concurrency"`     // количество исполнителей
+	Mix         string                         `json:"mix"`             // соотношение операций
+	BatchSize   int                            `json:"batch_size"`      // количество ссылок в пакетном запросе
+	StartedAt   time.Time                      `json:"started_at"`      // время начала нагрузки
+	Elapsed     float64                        `json:"elapsed_seconds"` // продолжительность нагрузки, сек.
+	Total       OperationReport                `json:"total"`           // результаты всех операций
+	Operations  map[Operation]*OperationReport `json:"operations"`      // результаты по операциям
+}
+
+// OperationReport - результаты операции
+type OperationReport struct {
+	Requests  int64            `json:"requests"`                 // количество запросов
+	Errors    int64            `json:"errors"`                   // количество ошибок
+	RPS       float64          `json:"rps"`                      // запросов в секунду
+	Latency   Latency          `json:"latency_ms"`               // задержки, мс
+	Histogram []Bucket         `json:"histogram"`                // распределение задержек
+	ErrorKind map[string]int64 `json:"errors_by_kind,omitempty"` // количество ошибок по видам
+}
+
+// Latency - задержки успешных запросов, мс
+type Latency struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// Bucket - интервал гистограммы задержек
+type Bucket struct {
+	LE    string `json:"le"`    // верхняя граница интервала (+Inf - без границы)
+	Count int64  `json:"count"` // количество запросов
+}
+
+// stats - накопленные результаты исполнителя
+type stats map[Operation]*opStats
+
+// opStats - накопленные результаты операции
+type opStats struct {
+	latency Histogram
+	errors  map[string]int64
+}
+
+// newStats - метод создания накопителя результатов
+func newStats() stats {
+	s := stats{}
+	for _, op := range Operations {
+		s[op] = &opStats{errors: map[string]int64{}}
+	}
+	return s
+}
+
+// record - метод учета результата выполнения операции
+func (s stats) record(op Operation, elapsed time.Duration, err error) {
+	if err != nil {
+		s[op].errors[errorKind(err)]++
+		return
+	}
+	s[op].latency.Record(elapsed)
+}
+
+// merge - метод добавления результатов другого исполнителя
+func (s stats) merge(other stats) {
+	for op, result := range other {
+		s[op].latency.Merge(&result.latency)
+		for kind, count := range result.errors {
+			s[op].errors[kind] += count
+		}
+	}
+}
+
+// newReport - метод формирования отчета по накопленным результатам
+func newReport(cfg *Config, started time.Time, elapsed time.Duration, s stats) *Report {
+	report := &Report{
+		Transport:   cfg.Transport,
+		Target:      cfg.Target,
+		Concurrency: cfg.Concurrency,
+		Mix:         cfg.Mix.String(),
+		BatchSize:   cfg.BatchSize,
+		StartedAt:   started,
+		Elapsed:     elapsed.Seconds(),
+		Operations:  map[Operation]*OperationReport{},
+	}
+	total := &opStats{errors: map[string]int64{}}
+	for _, op := range Operations {
+		result := s[op]
+		total.latency.Merge(&result.latency)
+		for kind, count := range result.errors {
+			total.errors[kind] += count
+		}
+		if cfg.Mix[op] > 0 || result.latency.Count() > 0 || len(result.errors) > 0 {
+			report.Operations[op] = result.report(elapsed)
+		}
+	}
+	report.Total = *total.report(elapsed)
+	return report
+}
+
+// report - метод формирования результатов операции
+func (s *opStats) report(elapsed time.Duration) *OperationReport {
+	result := &OperationReport{Requests: s.latency.Count()}
+	for kind, count := range s.errors {
+		result.Errors += count
+		if result.ErrorKind == nil {
+			result.ErrorKind = map[string]int64{}
+		}
+		result.ErrorKind[kind] = count
+	}
+	result.Requests += result.Errors
+	if elapsed > 0 {
+		result.RPS = float64(result.Requests) / elapsed.Seconds()
+	}
+	h := &s.latency
+	result.Latency = Latency{
+		Min:  milliseconds(h.min),
+		Mean: milliseconds(h.Mean()),
+		P50:  milliseconds(h.Quantile(0.5)),
+		P90:  milliseconds(h.Quantile(0.9)),
+		P95:  milliseconds(h.Quantile(0.95)),
+		P99:  milliseconds(h.Quantile(0.99)),
+		Max:  milliseconds(h.max),
+	}
+	for i, count := range h.Buckets(histogramBounds) {
+		le := "+Inf"
+		if i < len(histogramBounds) {
+			le = histogramBounds[i].String()
+		}
+		result.Histogram = append(result.Histogram, Bucket{LE: le, Count: count})
+	}
+	return result
+}
+
+// milliseconds - метод перевода длительности в миллисекунды
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// LoadReport - метод чтения отчета из файла в формате JSON
+func LoadReport(path string) (*Report, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read report: %w", err)
+	}
+	report := &Report{}
+	if err = json.Unmarshal(buf, report); err != nil {
+		return nil, fmt.Errorf("can't parse report %s: %w", path, err)
+	}
+	return report, nil
+}
+
+// WriteJSON - метод записи отчета в формате JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText - метод вывода отчета таблицей: результаты операций, распределение ошибок и гистограмма задержек
+func (r *Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "%s %s, concurrency %d, %.1fs, mix %s\n\n", r.Transport, r.Target, r.Concurrency, r.Elapsed, r.Mix)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "OPERATION\tREQUESTS\tERRORS\tRPS\tMEAN\tP50\tP90\tP95\tP99\tMAX\t")
+	row := func(name string, op *OperationReport) {
+		l := op.Latency
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t\n",
+			name, op.Requests, op.Errors, op.RPS, l.Mean, l.P50, l.P90, l.P95, l.P99, l.Max)
+	}
+	for _, op := range Operations {
+		if result, ok := r.Operations[op]; ok {
+			row(string(op), result)
+		}
+	}
+	row("total", &r.Total)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "\nLatency in milliseconds, successful requests only.")
+
+	if len(r.Total.ErrorKind) != 0 {
+		fmt.Fprintln(w, "\nErrors:")
+		kinds := make([]string, 0, len(r.Total.ErrorKind))
+		for kind := range r.Total.ErrorKind {
+			kinds = append(kinds, kind)
+		}
+		sort.Slice(kinds, func(i, j int) bool { return r.Total.ErrorKind[kinds[i]] > r.Total.ErrorKind[kinds[j]] })
+		for _, kind := range kinds {
+			fmt.Fprintf(w, "  %-24s %d\n", kind, r.Total.ErrorKind[kind])
+		}
+	}
+
+	fmt.Fprintln(w, "\nLatency histogram:")
+	var peak int64
+	for _, bucket := range r.Total.Histogram {
+		peak = max(peak, bucket.Count)
+	}
+	for _, bucket := range r.Total.Histogram {
+		bar := 0
+		if peak > 0 {
+			bar = int(bucket.Count * 40 / peak)
+		}
+		fmt.Fprintf(w, "  <= %-6s %8d %s\n", bucket.LE, bucket.Count, strings.Repeat("#", bar))
+	}
+	return nil
+}
+
+// noiseThreshold - относительное изменение показателя, не считающееся ухудшением
+const noiseThreshold = 0.01
+
+// Delta - изменение показателя относительно предыдущего запуска
+type Delta struct {
+	Operation string  // операция (total - все операции)
+	Metric    string  // показатель: rps, error_rate, p50, p95, p99
+	Base      float64 // значение предыдущего запуска
+	Current   float64 // значение текущего запуска
+	Change    float64 // изменение, %
+	Worse     bool    // признак ухудшения показателя
+}
+
+// Compare - метод сравнения отчета с отчетом предыдущего запуска base.
+// Сравниваются операции, присутствующие в обоих отчетах, и результаты всех операций
+func (r *Report) Compare(base *Report) []Delta {
+	var deltas []Delta
+	add := func(name string, current, previous *OperationReport) {
+		metrics := []struct {
+			name          string
+			base, current float64
+			higherBetter  bool
+		}{
+			{"rps", previous.RPS, current.RPS, true},
+			{"error_rate", errorRate(previous), errorRate(current), false},
+			{"p50", previous.Latency.P50, current.Latency.P50, false},
+			{"p95", previous.Latency.P95, current.Latency.P95, false},
+			{"p99", previous.Latency.P99, current.Latency.P99, false},
+		}
+		for _, m := range metrics {
+			delta := Delta{Operation: name, Metric: m.name, Base: m.base, Current: m.current}
+			if m.base != 0 {
+				delta.Change = (m.current - m.base) / m.base * 100
+			}
+			// изменения меньше noiseThreshold считаются погрешностью измерения
+			delta.Worse = math.Abs(m.current-m.base) > noiseThreshold*math.Abs(m.base) &&
+				((m.higherBetter && m.current < m.base) || (!m.higherBetter && m.current > m.base))
+			deltas = append(deltas, delta)
+		}
+	}
+	for _, op := range Operations {
+		current, ok := r.Operations[op]
+		previous, found := base.Operations[op]
+		if ok && found {
+			add(string(op), current, previous)
+		}
+	}
+	add("total", &r.Total, &base.Total)
+	return deltas
+}
+
+// WriteComparison - метод вывода сравнения с отчетом предыдущего запуска
+func WriteComparison(w io.Writer, deltas []Delta) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "OPERATION\tMETRIC\tBASE\tCURRENT\tCHANGE\t\t")
+	for _, d := range deltas {
+		mark := ""
+		if d.Worse {
+			mark = "worse"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.2f\t%.2f\t%+.1f%%\t%s\t\n", d.Operation, d.Metric, d.Base, d.Current, d.Change, mark)
+	}
+	return tw.Flush()
+}
+
+// errorRate - метод получения доли ошибок, %
+func errorRate(op *OperationReport) float64 {
+	if op.Requests == 0 {
+		return 0
+	}
+	return float64(op.Errors) / float64(op.Requests) * 100
+}
@@ -0,0 +1,71 @@
+package loadgen
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport_Compare(t *testing.T) {
+	base := &Report{
+		Total: OperationReport{Requests: 100, Errors: 10, RPS: 100, Latency: Latency{P50: 10, P95: 20, P99: 30}},
+		Operations: map[Operation]*OperationReport{
+			OpShorten: {Requests: 100, Errors: 10, RPS: 100, Latency: Latency{P50: 10, P95: 20, P99: 30}},
+		},
+	}
+	current := &Report{
+		Total: OperationReport{Requests: 100, Errors: 5, RPS: 80, Latency: Latency{P50: 10.05, P95: 30, P99: 15}},
+		Operations: map[Operation]*OperationReport{
+			OpShorten: {Requests: 100, RPS: 100},
+			OpList:    {Requests: 10},
+		},
+	}
+	deltas := current.Compare(base)
+	// операции, отсутствующие в одном из отчетов, не сравниваются
+	require.Len(t, deltas, 10)
+
+	testCases := []struct {
+		name   string
+		delta  Delta
+		change float64
+		worse  bool
+	}{
+		{"RPS decreased #1 (bad)", deltas[5], -20, true},
+		{"Error rate decreased #2 (good)", deltas[6], -50, false},
+		{"P50 within noise #3 (good)", deltas[7], 0.5, false},
+		{"P95 increased #4 (bad)", deltas[8], 50, true},
+		{"P99 decreased #5 (good)", deltas[9], -50, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, "total", tc.delta.Operation)
+			assert.InDelta(t, tc.change, tc.delta.Change, 0.001)
+			assert.Equal(t, tc.worse, tc.delta.Worse)
+		})
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteComparison(&buf, deltas))
+	assert.Contains(t, buf.String(), "worse")
+}
+
+func TestLoadReport(t *testing.T) {
+	report := &Report{Transport: "http", Mix: DefaultMix, Total: OperationReport{Requests: 1, Histogram: []Bucket{{LE: "+Inf", Count: 1}}}}
+	path := filepath.Join(t.TempDir(), "report.json")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, report.WriteJSON(file))
+	require.NoError(t, file.Close())
+
+	loaded, err := LoadReport(path)
+	require.NoError(t, err)
+	assert.Equal(t, report.Total, loaded.Total)
+	assert.Equal(t, report.Mix, loaded.Mix)
+
+	_, err = LoadReport(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// fieldsKey - тип ключа полей логирования в контексте
+type fieldsKey struct{}
+
+// WithFields - метод добавления в контекст полей (пары ключ-значение),
+// которые будут добавлены ко всем записям журнала, сделанным с этим контекстом
+func WithFields(ctx context.Context, keysAndValues ...any) context.Context {
+	fields := Fields(ctx)
+	merged := make([]any, 0, len(fields)+len(keysAndValues))
+	merged = append(merged, fields...)
+	merged = append(merged, keysAndValues...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// Fields - метод получения полей логирования из контекста (используется для передачи в фоновые задачи)
+func Fields(ctx context.Context) []any {
+	fields, _ := ctx.Value(fieldsKey{}).([]any)
+	return fields
+}
+
+// FromContext - метод получения логгера с полями из контекста и идентификатором трассы (если есть)
+func FromContext(ctx context.Context) *zap.SugaredLogger {
+	log := Get()
+	if fields := Fields(ctx); len(fields) > 0 {
+		log = log.With(fields...)
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		log = log.With("trace_id", span.TraceID().String(), "span_id", span.SpanID().String())
+	}
+	return log
+}
+
+// DebugCtx — обертка над методом логирования уровня Debug с полями из контекста
+func DebugCtx(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).Debugln(args...)
+}
+
+// InfoCtx — обертка над методом логирования уровня Info с полями из контекста
+func InfoCtx(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).Infoln(args...)
+}
+
+// WarnCtx — обертка над методом логирования уровня Warn с полями из контекста
+func WarnCtx(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).Warnln(args...)
+}
+
+// ErrorCtx — обертка над методом логирования уровня Error с полями из контекста
+func ErrorCtx(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).Errorln(args...)
+}
@@ -2,6 +2,7 @@
 package logger
 
 import (
+	"fmt"
 	"sync"
 
 	"go.uber.org/zap"
@@ -9,8 +10,9 @@ import (
 )
 
 var (
-	once     sync.Once
-	instance *zap.SugaredLogger = nil
+	once        sync.Once
+	instance    *zap.SugaredLogger = nil
+	atomicLevel zap.AtomicLevel    // уровень логирования, изменяемый без пересоздания логера
 )
 
 // Initialize - инициализирует синглтон логера с необходимым уровнем логирования.
@@ -26,6 +28,7 @@ func Initialize(level string) error {
 		// создаём новую конфигурацию логера
 		cfg := zap.NewProductionConfig()
 		// устанавливаем уровень
+		atomicLevel = lvl
 		cfg.Level = lvl
 		cfg.EncoderConfig.TimeKey = "time"
 		cfg.EncoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout("2006-01-02 15:04:05")
@@ -41,6 +44,25 @@ func Initialize(level string) error {
 	return initErr
 }
 
+// ValidateLevel - метод проверки уровня логирования
+func ValidateLevel(value string) error {
+	_, err := zapcore.ParseLevel(value)
+	return err
+}
+
+// SetLevel - метод изменения уровня логирования инициализированного логера
+func SetLevel(value string) error {
+	lvl, err := zapcore.ParseLevel(value)
+	if err != nil {
+		return err
+	}
+	if instance == nil {
+		return fmt.Errorf("logger not initialized")
+	}
+	atomicLevel.SetLevel(lvl)
+	return nil
+}
+
 // Get - метод получения объекта логгера из синглтона
 func Get() *zap.SugaredLogger {
 	if instance == nil {
@@ -0,0 +1,136 @@
+// Package metrics предоставляет реестр метрик сервиса в формате Prometheus и обработчик их выгрузки (/api/internal/metrics)
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Внутренние константы метрик
+const (
+	// namespace - общий префикс имён метрик сервиса
+	namespace = "shortener"
+	// StatusOK - значение метки status для успешной операции хранилища
+	StatusOK = "ok"
+	// StatusError - значение метки status для операции хранилища, завершившейся ошибкой
+	StatusError = "error"
+)
+
+var (
+	// registry - реестр метрик сервиса (включает метрики Go runtime и процесса)
+	registry = prometheus.NewRegistry()
+
+	// httpRequests - количество обработанных HTTP запросов
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of HTTP requests by route template, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	// httpDuration - длительность обработки HTTP запросов
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route template and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	// grpcRequests - количество обработанных GRPC вызовов
+	grpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "requests_total",
+		Help:      "Total number of GRPC calls by full method name and status code.",
+	}, []string{"method", "code"})
+
+	// grpcDuration - длительность обработки GRPC вызовов
+	grpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "request_duration_seconds",
+		Help:      "GRPC call latency by full method name.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	// storageDuration - длительность операций с хранилищем
+	storageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "storage",
+		Name:      "operation_duration_seconds",
+		Help:      "Storage operation latency by operation and result.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "status"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		grpcRequests,
+		grpcDuration,
+		storageDuration,
+	)
+}
+
+// Handler - метод получения обработчика выгрузки метрик в формате Prometheus
+func Handler() http.Handler {
+	// ответ сжимается middleware HTTP сервера по Accept-Encoding
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry, DisableCompression: true})
+}
+
+// ObserveHTTP - метод учёта обработанного HTTP запроса
+func ObserveHTTP(route, method, status string, duration time.Duration) {
+	httpRequests.WithLabelValues(route, method, status).Inc()
+	httpDuration.WithLabelValues(route, method).Observe(duration.Seconds())
+}
+
+// ObserveGRPC - метод учёта обработанного GRPC вызова
+func ObserveGRPC(method, code string, duration time.Duration) {
+	grpcRequests.WithLabelValues(method, code).Inc()
+	grpcDuration.WithLabelValues(method).Observe(duration.Seconds())
+}
+
+// ObserveStorage - метод учёта операции с хранилищем
+func ObserveStorage(operation string, err error, duration time.Duration) {
+	status := StatusOK
+	if err != nil {
+		status = StatusError
+	}
+	storageDuration.WithLabelValues(operation, status).Observe(duration.Seconds())
+}
+
+// QueueStat - интерфейс получения состояния очереди задач
+type QueueStat interface {
+	QueueLen() int     // количество задач, ожидающих обработки
+	Processed() uint64 // количество обработанных задач
+}
+
+// RegisterWorkerPool - метод регистрации метрик пула воркеров.
+// Повторная регистрация заменяет ранее зарегистрированный пул
+func RegisterWorkerPool(pool QueueStat) {
+	queue := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "workerpool",
+		Name:      "queue_length",
+		Help:      "Number of jobs waiting in the worker pool queue.",
+	}, func() float64 { return float64(pool.QueueLen()) })
+	processed := prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "workerpool",
+		Name:      "jobs_processed_total",
+		Help:      "Total number of jobs processed by the worker pool.",
+	}, func() float64 { return float64(pool.Processed()) })
+
+	for _, c := range []prometheus.Collector{queue, processed} {
+		registry.Unregister(c)
+		registry.MustRegister(c)
+	}
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubPool - mock-модель очереди задач
+type stubPool struct {
+	queue     int
+	processed uint64
+}
+
+func (p *stubPool) QueueLen() int     { return p.queue }
+func (p *stubPool) Processed() uint64 { return p.processed }
+
+// scrape - получение метрик через обработчик выгрузки
+func scrape(t *testing.T) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestHandler(t *testing.T) {
+	ObserveHTTP("/{id}", http.MethodGet, "307", 10*time.Millisecond)
+	ObserveGRPC("/shortener.Shortener/DecodeURL", "NotFound", time.Millisecond)
+	ObserveStorage("GetRecord", nil, time.Millisecond)
+	ObserveStorage("AddRecord", errors.New("storage error"), time.Millisecond)
+	RegisterWorkerPool(&stubPool{queue: 3, processed: 42})
+
+	body := scrape(t)
+
+	testCases := []struct {
+		name   string
+		series string
+	}{
+		{"HTTP requests", `shortener_http_requests_total{method="GET",route="/{id}",status="307"} 1`},
+		{"HTTP latency", `shortener_http_request_duration_seconds_count{method="GET",route="/{id}"} 1`},
+		{"GRPC requests", `shortener_grpc_requests_total{code="NotFound",method="/shortener.Shortener/DecodeURL"} 1`},
+		{"GRPC latency", `shortener_grpc_request_duration_seconds_count{method="/shortener.Shortener/DecodeURL"} 1`},
+		{"Storage success", `shortener_storage_operation_duration_seconds_count{operation="GetRecord",status="ok"} 1`},
+		{"Storage error", `shortener_storage_operation_duration_seconds_count{operation="AddRecord",status="error"} 1`},
+		{"Workerpool queue", `shortener_workerpool_queue_length 3`},
+		{"Workerpool processed", `shortener_workerpool_jobs_processed_total 42`},
+		{"Go runtime", `go_goroutines`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Contains(t, body, tc.series)
+		})
+	}
+}
+
+func TestRegisterWorkerPool_Replace(t *testing.T) {
+	RegisterWorkerPool(&stubPool{queue: 1})
+	RegisterWorkerPool(&stubPool{queue: 7})
+	assert.Contains(t, scrape(t), "shortener_workerpool_queue_length 7")
+}
@@ -0,0 +1,152 @@
+// Package gateway предоставляет REST шлюз (HTTP/JSON), сформированный по аннотациям internal/proto/shortener.proto.
+// Запросы транслируются в GRPC вызовы, поэтому валидация и отображение ошибок совпадают с GRPC API
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/denmor86/go-url-shortener/internal/gen"
+	"github.com/denmor86/go-url-shortener/internal/helpers"
+	"github.com/denmor86/go-url-shortener/internal/tracing"
+	"github.com/denmor86/go-url-shortener/internal/usecase"
+)
+
+// Внутренние константы шлюза
+const (
+	// userIDField - имя поля сообщения с UUID пользователя
+	userIDField = "user_id"
+	// realIPMetadataKey - ключ метаданных с адресом клиента (используется для проверки доверенной подсети)
+	realIPMetadataKey = "x-real-ip"
+)
+
+// NewHandler - метод формирования обработчика REST шлюза.
+// Соединение conn должно вести к GRPC серверу со всеми interceptor (см. server.PipeListener)
+func NewHandler(ctx context.Context, conn grpc.ClientConnInterface) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true},
+			UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+		}),
+		runtime.WithIncomingHeaderMatcher(headerMatcher),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher),
+		runtime.WithErrorHandler(errorHandler),
+	)
+	client := pb.NewShortenerClient(&userConn{ClientConnInterface: conn})
+	if err := pb.RegisterShortenerHandlerClient(ctx, mux, client); err != nil {
+		return nil, err
+	}
+	return mux, nil
+}
+
+// headerMatcher - метод отбора HTTP заголовков, передаваемых в метаданные GRPC запроса.
+// Адрес клиента передается шлюзом (см. outgoingContext), поэтому заданный клиентом x-real-ip отбрасывается
+func headerMatcher(key string) (string, bool) {
+	name, ok := runtime.DefaultHeaderMatcher(key)
+	if ok && strings.EqualFold(name, realIPMetadataKey) {
+		return "", false
+	}
+	return name, ok
+}
+
+// outgoingHeaderMatcher - метод отбора метаданных GRPC ответа, передаваемых в HTTP заголовки.
+// Идентификатор запроса уже возвращен в заголовке X-Request-ID, поэтому не дублируется.
+// Сведения о квоте передаются в тех же заголовках, что и в HTTP API
+func outgoingHeaderMatcher(key string) (string, bool) {
+	if key == helpers.RequestIDMetadataKey {
+		return "", false
+	}
+	if strings.HasPrefix(key, "x-quota-") || key == "retry-after" {
+		return textproto.CanonicalMIMEHeaderKey(key), true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// errorHandler - метод формирования HTTP ответа с ошибкой.
+// Удаленные ссылки возвращают 410 Gone, как и в HTTP API; остальные коды отображаются по умолчанию
+func errorHandler(ctx context.Context, mux *runtime.ServeMux, m runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	if st, ok := status.FromError(err); ok {
+		for _, detail := range st.Details() {
+			if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetReason() == usecase.ReasonDeleted {
+				err = &runtime.HTTPStatusError{HTTPStatus: http.StatusGone, Err: err}
+				break
+			}
+		}
+	}
+	runtime.DefaultHTTPErrorHandler(ctx, mux, m, w, r, err)
+}
+
+// userConn - соединение, подставляющее UUID пользователя из контекста HTTP запроса в GRPC сообщения
+// и передающее идентификатор, адрес клиента и контекст трассировки HTTP запроса в GRPC вызов
+type userConn struct {
+	grpc.ClientConnInterface
+}
+
+// Invoke - метод выполнения унарного вызова
+func (c *userConn) Invoke(ctx context.Context, method string, args any, reply any, opts ...grpc.CallOption) error {
+	setUserID(ctx, args)
+	ctx = outgoingContext(ctx)
+	return c.ClientConnInterface.Invoke(ctx, method, args, reply, opts...)
+}
+
+// NewStream - метод открытия потока
+func (c *userConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	ctx = outgoingContext(ctx)
+	stream, err := c.ClientConnInterface.NewStream(ctx, desc, method, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &userStream{ClientStream: stream, ctx: ctx}, nil
+}
+
+// userStream - поток, подставляющий UUID пользователя в каждое отправляемое сообщение
+type userStream struct {
+	grpc.ClientStream
+	ctx context.Context
+}
+
+// SendMsg - метод отправки сообщения в поток
+func (s *userStream) SendMsg(m any) error {
+	setUserID(s.ctx, m)
+	return s.ClientStream.SendMsg(m)
+}
+
+// outgoingContext - метод передачи идентификатора запроса, адреса клиента и контекста трассировки в метаданные GRPC вызова
+func outgoingContext(ctx context.Context) context.Context {
+	if id := usecase.RequestIDFromContext(ctx); len(id) > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, helpers.RequestIDMetadataKey, id)
+	}
+	if ip := usecase.ClientIPFromContext(ctx); len(ip) > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, realIPMetadataKey, ip)
+	}
+	return tracing.InjectOutgoing(ctx)
+}
+
+// setUserID - метод заполнения поля user_id сообщения значением из контекста (авторизация по cookie)
+func setUserID(ctx context.Context, m any) {
+	userID, ok := ctx.Value(usecase.UserIDContextKey).(string)
+	if !ok {
+		return
+	}
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return
+	}
+	ref := msg.ProtoReflect()
+	field := ref.Descriptor().Fields().ByName(userIDField)
+	if field == nil || field.Kind() != protoreflect.StringKind {
+		return
+	}
+	ref.Set(field, protoreflect.ValueOfString(userID))
+}
@@ -0,0 +1,320 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/denmor86/go-url-shortener/internal/config"
+	"github.com/denmor86/go-url-shortener/internal/helpers"
+	"github.com/denmor86/go-url-shortener/internal/idempotency"
+	"github.com/denmor86/go-url-shortener/internal/logger"
+	"github.com/denmor86/go-url-shortener/internal/network/router"
+	server "github.com/denmor86/go-url-shortener/internal/server/grpc"
+	"github.com/denmor86/go-url-shortener/internal/storage"
+	"github.com/denmor86/go-url-shortener/internal/usecase"
+)
+
+// newTestServer - запуск HTTP сервера с REST шлюзом, подключенным к GRPC серверу в памяти
+func newTestServer(t *testing.T, cfg *config.Config) *httptest.Server {
+	t.Helper()
+	require.NoError(t, logger.Initialize("info"))
+
+	store := storage.NewMemStorage()
+	s := server.NewServer(cfg, usecase.NewUsecaseGRPC(cfg, store, nil), nil, nil)
+	listener := server.NewPipeListener()
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///pipe",
+		grpc.WithContextDialer(listener.DialContext),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	handler, err := NewHandler(context.Background(), conn)
+	require.NoError(t, err)
+
+	r := router.HandleRouter(cfg, usecase.NewUsecaseHTTP(cfg, store, nil), nil, nil)
+	router.HandleGateway(r, cfg, handler, nil, idempotency.NewKeeper(idempotency.NewMemoryStore(), time.Hour))
+	ts := httptest.NewServer(r)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestGateway(t *testing.T) {
+	const original = "https://practicum.yandex.ru/"
+
+	cfg := config.NewDefaultConfig()
+	ts := newTestServer(t, cfg)
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := &http.Client{Jar: jar}
+
+	do := func(t *testing.T, method, path, body string) (int, map[string]any) {
+		t.Helper()
+		req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var result map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		return resp.StatusCode, result
+	}
+
+	var shortURL string
+	t.Run("Encode URL #1 (good)", func(t *testing.T) {
+		code, body := do(t, http.MethodPost, "/api/v2/urls", `{"url":"`+original+`"}`)
+		require.Equal(t, http.StatusOK, code)
+		shortURL, _ = body["result"].(string)
+		assert.True(t, strings.HasPrefix(shortURL, cfg.BaseURL+"/"))
+	})
+
+	t.Run("Encode URL #2 (invalid)", func(t *testing.T) {
+		code, _ := do(t, http.MethodPost, "/api/v2/urls", `{"url":""}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("Decode URL #1 (good)", func(t *testing.T) {
+		id := strings.TrimPrefix(shortURL, cfg.BaseURL+"/")
+		code, body := do(t, http.MethodGet, "/api/v2/urls/"+id, "")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, original, body["result"])
+	})
+
+	t.Run("Decode URL #2 (not found)", func(t *testing.T) {
+		code, _ := do(t, http.MethodGet, "/api/v2/urls/unknown", "")
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("User URLs #1 (good)", func(t *testing.T) {
+		code, body := do(t, http.MethodGet, "/api/v2/user/urls", "")
+		require.Equal(t, http.StatusOK, code)
+		results, _ := body["results"].([]any)
+		require.Len(t, results, 1)
+		assert.Equal(t, map[string]any{"original": original, "shorten": shortURL}, results[0])
+	})
+
+	t.Run("Statistic #1 (untrusted)", func(t *testing.T) {
+		code, _ := do(t, http.MethodGet, "/api/v2/internal/stats", "")
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("Request ID #1 (good)", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v2/urls/unknown", nil)
+		require.NoError(t, err)
+		req.Header.Set(helpers.RequestIDHeader, "edge-42")
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, []string{"edge-42"}, resp.Header.Values(helpers.RequestIDHeader))
+		assert.Empty(t, resp.Header.Get("Grpc-Metadata-X-Request-Id"))
+	})
+}
+
+func TestGateway_TrustedSubnet(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.TrustedSubnet = "192.168.1.0/24"
+	// тестовый сервер принимает соединения с локального адреса, который считается доверенным прокси
+	cfg.TrustedProxies = "127.0.0.0/8,::1/128"
+	ts := newTestServer(t, cfg)
+
+	testCases := []struct {
+		name   string
+		header string
+		realIP string
+		status int
+	}{
+		{"Statistic #1 (good)", "X-Real-IP", "192.168.1.10", http.StatusOK},
+		{"Statistic #2 (forwarded)", "X-Forwarded-For", "192.168.1.10", http.StatusOK},
+		{"Statistic #3 (untrusted)", "X-Real-IP", "10.0.0.1", http.StatusForbidden},
+		{"Statistic #4 (without header)", "", "", http.StatusForbidden},
+		{"Statistic #5 (spoofed metadata)", "Grpc-Metadata-X-Real-IP", "192.168.1.10", http.StatusForbidden},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v2/internal/stats", nil)
+			require.NoError(t, err)
+			if len(tc.header) > 0 {
+				req.Header.Set(tc.header, tc.realIP)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tc.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestGateway_UntrustedProxy(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.TrustedSubnet = "192.168.1.0/24"
+	ts := newTestServer(t, cfg)
+
+	// без доверенных прокси заголовок с адресом клиента не учитывается
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v2/internal/stats", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Real-IP", "192.168.1.10")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestErrorHandler(t *testing.T) {
+	deleted, err := status.New(codes.FailedPrecondition, "url deleted").
+		WithDetails(&errdetails.ErrorInfo{Reason: usecase.ReasonDeleted, Domain: usecase.ErrorDomain})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"Deleted URL", deleted.Err(), http.StatusGone},
+		{"Failed precondition", status.Error(codes.FailedPrecondition, "failed"), http.StatusBadRequest},
+		{"Not found", status.Error(codes.NotFound, "not found"), http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v2/urls/test", nil)
+			errorHandler(context.Background(), runtime.NewServeMux(), &runtime.JSONPb{}, w, r, tc.err)
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+}
+
+func TestGateway_Quota(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.QuotaTotal = 1
+	ts := newTestServer(t, cfg)
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := &http.Client{Jar: jar}
+
+	testCases := []struct {
+		name      string
+		url       string
+		status    int
+		remaining string
+	}{
+		{"First link #1 (good)", "https://practicum.yandex.ru/", http.StatusOK, "0"},
+		{"Quota exceeded #2 (bad)", "https://google.com", http.StatusTooManyRequests, "0"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := client.Post(ts.URL+"/api/v2/urls", "application/json", strings.NewReader(`{"url":"`+tc.url+`"}`))
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tc.status, resp.StatusCode)
+			assert.Equal(t, tc.remaining, resp.Header.Get(usecase.QuotaTotalRemainingHeader))
+		})
+	}
+
+	resp, err := client.Get(ts.URL + "/api/v2/user/quota")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var quota map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&quota))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.EqualValues(t, 1, quota["total_used"])
+	assert.EqualValues(t, 1, quota["total_limit"])
+}
+
+func TestGateway_Streams(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	ts := newTestServer(t, cfg)
+
+	// readResults - чтение ответа потокового метода: по одному JSON сообщению {"result": ...} в строке
+	readResults := func(t *testing.T, resp *http.Response, compressed bool) []map[string]any {
+		t.Helper()
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		// ответ сжат, только если клиент запросил сжатие
+		assert.Equal(t, compressed, resp.Uncompressed)
+		var results []map[string]any
+		decoder := json.NewDecoder(resp.Body)
+		for decoder.More() {
+			var message map[string]any
+			require.NoError(t, decoder.Decode(&message))
+			require.Contains(t, message, "result", message)
+			results = append(results, message["result"].(map[string]any))
+		}
+		return results
+	}
+
+	testCases := []struct {
+		name     string
+		encoding string
+		key      string
+	}{
+		{"Streams without compression #1 (good)", "", ""},
+		{"Streams with gzip #2 (good)", "gzip", ""},
+		{"Streams with idempotency key #3 (good)", "", "stream-1"},
+	}
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// сжатый ответ распаковывается клиентом, если Accept-Encoding установлен им самим
+			client := &http.Client{Transport: &http.Transport{DisableCompression: len(tc.encoding) == 0}}
+
+			prefix := "https://example.com/" + strconv.Itoa(i) + "/"
+			body := `{"id":"1","url":"` + prefix + `a"}` + "\n" + `{"id":"2","url":"` + prefix + `b"}` + "\n" + `{"id":"3","url":""}`
+			req, err := http.NewRequest(http.MethodPost, ts.URL+"/api/v2/urls/stream", strings.NewReader(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			if len(tc.key) != 0 {
+				req.Header.Set(idempotency.HeaderName, tc.key)
+			}
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			cookies := resp.Cookies()
+			encoded := readResults(t, resp, len(tc.encoding) != 0)
+			require.Len(t, encoded, 3)
+			for j, id := range []string{"1", "2"} {
+				assert.Equal(t, id, encoded[j]["id"])
+				assert.True(t, strings.HasPrefix(encoded[j]["url"].(string), cfg.BaseURL+"/"), encoded[j])
+			}
+			assert.Equal(t, "3", encoded[2]["id"])
+			assert.NotEmpty(t, encoded[2]["error"])
+
+			// cookie выдается для пути запроса, поэтому передается явно
+			req, err = http.NewRequest(http.MethodGet, ts.URL+"/api/v2/user/urls/pages?page_size=1", nil)
+			require.NoError(t, err)
+			for _, cookie := range cookies {
+				req.AddCookie(cookie)
+			}
+			resp, err = client.Do(req)
+			require.NoError(t, err)
+			pages := readResults(t, resp, len(tc.encoding) != 0)
+			require.Len(t, pages, 2)
+			var originals []any
+			for _, page := range pages {
+				results, _ := page["results"].([]any)
+				require.Len(t, results, 1)
+				originals = append(originals, results[0].(map[string]any)["original"])
+			}
+			assert.ElementsMatch(t, []any{prefix + "a", prefix + "b"}, originals)
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/denmor86/go-url-shortener/internal/config"
+	"github.com/denmor86/go-url-shortener/internal/logger"
+)
+
+// ReloadConfig - метод-обработчик перечитывания конфигурации без перезапуска приложения.
+// Возвращает примененные параметры и измененные параметры, требующие перезапуска; некорректная конфигурация не применяется
+func ReloadConfig(reload func() (config.ReloadResult, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result, err := reload()
+		if err != nil {
+			logger.WarnCtx(r.Context(), "Configuration reload failed:", err)
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(result)
+	}
+}
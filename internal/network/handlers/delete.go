@@ -15,6 +15,10 @@ func DeleteURLs(u *usecase.UsecaseHTTP) http.HandlerFunc {
 
 		if userID := r.Context().Value(usecase.UserIDContextKey); userID != nil {
 			err := u.DeleteURLs(r.Context(), r.Body, userID.(string))
+			if errors.Is(err, usecase.ErrTooLarge) {
+				http.Error(w, errors.Cause(err).Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
 			if err != nil {
 				http.Error(w, errors.Cause(err).Error(), http.StatusBadRequest)
 				return
@@ -69,6 +69,24 @@ func GetURLs(u *usecase.UsecaseHTTP) http.HandlerFunc {
 	}
 }
 
+// GetQuota - метод-обработчик получения сведений о квоте пользователя
+func GetQuota(u *usecase.UsecaseHTTP) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if userID := r.Context().Value(usecase.UserIDContextKey); userID != nil {
+			responce, err := u.GetQuota(r.Context(), userID.(string))
+			if err != nil {
+				http.Error(w, errors.Cause(err).Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write(responce)
+			return
+		}
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
 // GetStats - метод-обработчик получения данных о статистике сокращенных URLs
 func GetStats(u *usecase.UsecaseHTTP) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -12,8 +12,18 @@ import (
 func EncodeURL(u *usecase.UsecaseHTTP) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if userID := r.Context().Value(usecase.UserIDContextKey); userID != nil {
-			shortURL, err := u.EncodeURL(r.Context(), r.Body, userID.(string))
+			ctx, quota := usecase.WithQuotaReport(r.Context())
+			shortURL, err := u.EncodeURL(ctx, r.Body, userID.(string))
 
+			setQuotaHeaders(w, quota)
+			if errors.Is(err, usecase.ErrQuotaExceeded) {
+				http.Error(w, errors.Cause(err).Error(), http.StatusTooManyRequests)
+				return
+			}
+			if errors.Is(err, usecase.ErrTooLarge) {
+				http.Error(w, errors.Cause(err).Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
 			w.Header().Set("content-type", "text/plain")
 
 			if err == nil {
@@ -38,8 +48,18 @@ func EncodeURL(u *usecase.UsecaseHTTP) http.HandlerFunc {
 func EncodeURLJson(u *usecase.UsecaseHTTP) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if userID := r.Context().Value(usecase.UserIDContextKey); userID != nil {
-			responce, err := u.EncodeURLJson(r.Context(), r.Body, userID.(string))
+			ctx, quota := usecase.WithQuotaReport(r.Context())
+			responce, err := u.EncodeURLJson(ctx, r.Body, userID.(string))
 
+			setQuotaHeaders(w, quota)
+			if errors.Is(err, usecase.ErrQuotaExceeded) {
+				http.Error(w, errors.Cause(err).Error(), http.StatusTooManyRequests)
+				return
+			}
+			if errors.Is(err, usecase.ErrTooLarge) {
+				http.Error(w, errors.Cause(err).Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
 			w.Header().Set("Content-Type", "application/json")
 
 			if err == nil {
@@ -64,7 +84,18 @@ func EncodeURLJson(u *usecase.UsecaseHTTP) http.HandlerFunc {
 func EncodeURLJsonBatch(u *usecase.UsecaseHTTP) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if userID := r.Context().Value(usecase.UserIDContextKey); userID != nil {
-			responce, err := u.EncodeURLJsonBatch(r.Context(), r.Body, userID.(string))
+			ctx, quota := usecase.WithQuotaReport(r.Context())
+			responce, err := u.EncodeURLJsonBatch(ctx, r.Body, userID.(string))
+
+			setQuotaHeaders(w, quota)
+			if errors.Is(err, usecase.ErrQuotaExceeded) {
+				http.Error(w, errors.Cause(err).Error(), http.StatusTooManyRequests)
+				return
+			}
+			if errors.Is(err, usecase.ErrTooLarge) {
+				http.Error(w, errors.Cause(err).Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
 			if err != nil {
 				http.Error(w, errors.Cause(err).Error(), http.StatusBadRequest)
 				return
@@ -77,3 +108,10 @@ func EncodeURLJsonBatch(u *usecase.UsecaseHTTP) http.HandlerFunc {
 		http.Error(w, "internal error", http.StatusInternalServerError)
 	}
 }
+
+// setQuotaHeaders - метод передачи сведений о квоте пользователя в заголовках ответа
+func setQuotaHeaders(w http.ResponseWriter, quota *usecase.Quota) {
+	for key, value := range quota.Headers() {
+		w.Header().Set(key, value)
+	}
+}
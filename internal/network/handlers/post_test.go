@@ -275,3 +275,35 @@ func TestEncondeJsonURLHandlerBatch(t *testing.T) {
 		})
 	}
 }
+
+func TestEncodeURLHandler_Quota(t *testing.T) {
+	u := usecase.NewUsecaseHTTP(&config.Config{BaseURL: "http://localhost:8080", ShortURLLen: 8, QuotaDaily: 1}, storage.NewMemStorage(), nil)
+	h := http.HandlerFunc(EncodeURL(u))
+
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+		remaining  string
+	}{
+		{"Quota test #1 (good)", "https://practicum.yandex.ru/", http.StatusCreated, "0"},
+		{"Quota test #2 (quota exceeded)", "https://google.com", http.StatusTooManyRequests, "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			request = request.WithContext(context.WithValue(request.Context(), usecase.UserIDContextKey, testUserID))
+			w := httptest.NewRecorder()
+			h(w, request)
+
+			result := w.Result()
+			defer result.Body.Close()
+			assert.Equal(t, tt.statusCode, result.StatusCode)
+			assert.Equal(t, "1", result.Header.Get(usecase.QuotaDailyLimitHeader))
+			assert.Equal(t, tt.remaining, result.Header.Get(usecase.QuotaDailyRemainingHeader))
+			if tt.statusCode == http.StatusTooManyRequests {
+				assert.NotEmpty(t, result.Header.Get(usecase.RetryAfterHeader))
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/denmor86/go-url-shortener/internal/gen"
+	"github.com/denmor86/go-url-shortener/internal/idempotency"
+	"github.com/denmor86/go-url-shortener/internal/logger"
+)
+
+// idempotentMethods - GRPC методы с поддержкой ключа идемпотентности и конструкторы их ответов
+var idempotentMethods = map[string]func() proto.Message{
+	pb.Shortener_EncodeURL_FullMethodName:  func() proto.Message { return &pb.EncodeURLResponse{} },
+	pb.Shortener_EncodeURLs_FullMethodName: func() proto.Message { return &pb.EncodeURLsResponse{} },
+}
+
+// Idempotency - модель interceptor повторного ответа по ключу идемпотентности
+type Idempotency struct {
+	keeper *idempotency.Keeper // хранение результатов (nil - ключи идемпотентности не поддерживаются)
+}
+
+// NewIdempotency - метод формирования объекта interceptor повторного ответа по ключу идемпотентности
+func NewIdempotency(keeper *idempotency.Keeper) *Idempotency {
+	return &Idempotency{keeper: keeper}
+}
+
+// Unary — interceptor обработки ключа идемпотентности (метаданные idempotency-key).
+// Первый результат вызова сохраняется и возвращается на вызовы с тем же ключом;
+// ключ, использованный с другим запросом, отклоняется с FailedPrecondition, ключ выполняемого вызова - с Aborted.
+// Ключи разных клиентов (сертификат mTLS) и пользователей (user_id запроса) не пересекаются
+func (i *Idempotency) Unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	newResponse, ok := idempotentMethods[info.FullMethod]
+	if !ok || i.keeper == nil {
+		return handler(ctx, req)
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(idempotency.MetadataKey)
+	if len(values) == 0 {
+		return handler(ctx, req)
+	}
+	key := values[0]
+	if !idempotency.ValidKey(key) {
+		return nil, status.Error(codes.InvalidArgument, "invalid idempotency key")
+	}
+	message, ok := req.(proto.Message)
+	if !ok {
+		return handler(ctx, req)
+	}
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to marshal request")
+	}
+
+	key = "grpc|" + idempotencyOwner(ctx, req) + "|" + key
+	storeCtx := context.WithoutCancel(ctx)
+	fingerprint := idempotency.Fingerprint([]byte(info.FullMethod), body)
+	record, err := i.keeper.Begin(storeCtx, key, fingerprint)
+	switch {
+	case errors.Is(err, idempotency.ErrKeyMismatch):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, idempotency.ErrInProgress):
+		return nil, status.Error(codes.Aborted, err.Error())
+	case err != nil:
+		// при недоступности хранилища вызов выполняется без сохранения результата
+		logger.WarnCtx(ctx, "Idempotency key check failed:", err)
+		return handler(ctx, req)
+	case record != nil:
+		_ = grpc.SetHeader(ctx, metadata.Pairs(idempotency.ReplayedHeader, "true"))
+		return replayRecord(record, newResponse())
+	}
+
+	resp, handlerErr := handler(ctx, req)
+	record, ok = newRecord(resp, handlerErr)
+	if !ok {
+		if err := i.keeper.Abort(storeCtx, key); err != nil {
+			logger.WarnCtx(ctx, "Idempotency key abort failed:", err)
+		}
+		return resp, handlerErr
+	}
+	record.Fingerprint = fingerprint
+	if err := i.keeper.Complete(storeCtx, key, *record); err != nil {
+		logger.WarnCtx(ctx, "Idempotency key save failed:", err)
+	}
+	return resp, handlerErr
+}
+
+// idempotencyOwner - метод определения владельца ключа идемпотентности: клиентский сертификат (mTLS) и пользователь запроса
+func idempotencyOwner(ctx context.Context, req any) string {
+	var owner string
+	if identity, ok := IdentityFromContext(ctx); ok {
+		owner = "cert:" + identity.Subject
+	}
+	if r, ok := req.(interface{ GetUserId() string }); ok {
+		owner += "|user:" + r.GetUserId()
+	}
+	return owner
+}
+
+// newRecord - метод формирования сохраняемого результата вызова.
+// Ошибки, после которых вызов можно повторить (недоступность, превышение лимитов, внутренние ошибки), не сохраняются
+func newRecord(resp any, err error) (*idempotency.Record, bool) {
+	if err != nil {
+		st := status.Convert(err)
+		switch st.Code() {
+		case codes.Unavailable, codes.ResourceExhausted, codes.Internal, codes.Unknown,
+			codes.Canceled, codes.DeadlineExceeded, codes.Aborted:
+			return nil, false
+		}
+		body, marshalErr := proto.Marshal(st.Proto())
+		if marshalErr != nil {
+			return nil, false
+		}
+		return &idempotency.Record{Status: int(st.Code()), Body: body}, true
+	}
+	message, ok := resp.(proto.Message)
+	if !ok {
+		return nil, false
+	}
+	body, marshalErr := proto.Marshal(message)
+	if marshalErr != nil {
+		return nil, false
+	}
+	return &idempotency.Record{Status: int(codes.OK), Body: body}, true
+}
+
+// replayRecord - метод восстановления результата вызова из сохраненной записи
+func replayRecord(record *idempotency.Record, resp proto.Message) (any, error) {
+	if codes.Code(record.Status) != codes.OK {
+		st := &spb.Status{}
+		if err := proto.Unmarshal(record.Body, st); err != nil {
+			return nil, status.Error(codes.Internal, "failed to restore response")
+		}
+		return nil, status.FromProto(st).Err()
+	}
+	if err := proto.Unmarshal(record.Body, resp); err != nil {
+		return nil, status.Error(codes.Internal, "failed to restore response")
+	}
+	return resp, nil
+}
@@ -0,0 +1,63 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/denmor86/go-url-shortener/internal/gen"
+	"github.com/denmor86/go-url-shortener/internal/idempotency"
+)
+
+func TestIdempotency_Unary(t *testing.T) {
+	idem := NewIdempotency(idempotency.NewKeeper(idempotency.NewMemoryStore(), idempotency.LockTimeout))
+	info := &grpc.UnaryServerInfo{FullMethod: pb.Shortener_EncodeURL_FullMethodName}
+	calls := 0
+	handler := func(ctx context.Context, req any) (any, error) {
+		calls++
+		if req.(*pb.EncodeURLRequest).GetUrl() == "invalid" {
+			return nil, status.Error(codes.InvalidArgument, "invalid url")
+		}
+		return &pb.EncodeURLResponse{Result: "http://localhost:8080/" + req.(*pb.EncodeURLRequest).GetUrl()}, nil
+	}
+	withKey := func(key string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(idempotency.MetadataKey, key))
+	}
+
+	testCases := []struct {
+		name  string
+		ctx   context.Context
+		user  string
+		url   string
+		code  codes.Code
+		calls int
+	}{
+		{"First call #1 (good)", withKey("key-1"), "user", "abc", codes.OK, 1},
+		{"Replayed call #2 (good)", withKey("key-1"), "user", "abc", codes.OK, 1},
+		{"Other request with same key #3 (bad)", withKey("key-1"), "user", "xyz", codes.FailedPrecondition, 1},
+		{"Without key #4 (good)", context.Background(), "user", "abc", codes.OK, 2},
+		{"Error is saved #5 (bad)", withKey("key-2"), "user", "invalid", codes.InvalidArgument, 3},
+		{"Error is replayed #6 (bad)", withKey("key-2"), "user", "invalid", codes.InvalidArgument, 3},
+		{"Invalid key #7 (bad)", withKey("bad key"), "user", "abc", codes.InvalidArgument, 3},
+		{"Same key of other user #8 (good)", withKey("key-1"), "other", "xyz", codes.OK, 4},
+		{"Replayed call of other user #9 (good)", withKey("key-1"), "other", "xyz", codes.OK, 4},
+		{"Replayed call of first user #10 (good)", withKey("key-1"), "user", "abc", codes.OK, 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := idem.Unary(tc.ctx, &pb.EncodeURLRequest{Url: tc.url, UserId: tc.user}, info, handler)
+			require.Equal(t, tc.code, status.Code(err))
+			assert.Equal(t, tc.calls, calls)
+			if tc.code == codes.OK {
+				assert.Equal(t, "http://localhost:8080/"+tc.url, resp.(*pb.EncodeURLResponse).GetResult())
+			}
+		})
+	}
+}
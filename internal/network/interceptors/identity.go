@@ -0,0 +1,67 @@
+// Package interceptors предоставляет вспомогательные interceptor методы для поддержки GRPC взаимодействия
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/denmor86/go-url-shortener/internal/usecase"
+)
+
+// IdentityContextKey - имя ключа идентичности клиента в передаваемом контексте
+var IdentityContextKey usecase.ContextKey = "identity"
+
+// Identity - модель идентичности клиента, определенной по проверенному клиентскому сертификату (mTLS)
+type Identity struct {
+	Subject      string   // субъект сертификата (RFC 2253)
+	CommonName   string   // имя владельца сертификата
+	Organization []string // организации владельца сертификата
+}
+
+// IdentityFromContext - метод получения идентичности клиента из контекста
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(IdentityContextKey).(Identity)
+	return identity, ok
+}
+
+// ClientIdentity — interceptor определения идентичности клиента для входящих GRPC-запросов.
+func ClientIdentity(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(withIdentity(ctx), req)
+}
+
+// ClientIdentityStream — interceptor определения идентичности клиента для входящих GRPC-потоков.
+func ClientIdentityStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &contextStream{ServerStream: ss, ctx: withIdentity(ss.Context())})
+}
+
+// withIdentity - метод добавления в контекст идентичности клиента (если клиент предъявил проверенный сертификат)
+func withIdentity(ctx context.Context) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return ctx
+	}
+	cert := tlsInfo.State.VerifiedChains[0][0]
+	return context.WithValue(ctx, IdentityContextKey, Identity{
+		Subject:      cert.Subject.String(),
+		CommonName:   cert.Subject.CommonName,
+		Organization: cert.Subject.Organization,
+	})
+}
+
+// contextStream - реализация grpc.ServerStream с переопределенным контекстом
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context - метод получения контекста потока
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,138 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/denmor86/go-url-shortener/internal/helpers"
+)
+
+func TestClientIdentity(t *testing.T) {
+	caPEM, caKeyPEM, err := helpers.GenerateCACert("test-ca")
+	require.NoError(t, err)
+	clientPEM, _, err := helpers.GenerateSignedCert("client", caPEM, caKeyPEM)
+	require.NoError(t, err)
+	block, _ := pem.Decode(clientPEM)
+	require.NotNil(t, block)
+	clientCert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	addr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 5000}
+
+	testCases := []struct {
+		name     string
+		peer     *peer.Peer
+		expected bool
+	}{
+		{
+			name: "Verified certificate #1 (good)",
+			peer: &peer.Peer{Addr: addr, AuthInfo: credentials.TLSInfo{
+				State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{clientCert}}},
+			}},
+			expected: true,
+		},
+		{
+			name: "Not verified certificate #2 (bad)",
+			peer: &peer.Peer{Addr: addr, AuthInfo: credentials.TLSInfo{
+				State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{clientCert}},
+			}},
+			expected: false,
+		},
+		{
+			name:     "Without TLS #3 (bad)",
+			peer:     &peer.Peer{Addr: addr},
+			expected: false,
+		},
+		{
+			name:     "Without peer #4 (bad)",
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.peer != nil {
+				ctx = peer.NewContext(ctx, tc.peer)
+			}
+			var identity Identity
+			var ok bool
+			handler := func(ctx context.Context, req any) (any, error) {
+				identity, ok = IdentityFromContext(ctx)
+				return nil, nil
+			}
+			_, err := ClientIdentity(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, ok)
+			if tc.expected {
+				assert.Equal(t, "client", identity.CommonName)
+				assert.Equal(t, "CN=client", identity.Subject)
+			}
+		})
+	}
+}
+
+func TestTrustNet_TrustGuardClients(t *testing.T) {
+	caPEM, caKeyPEM, err := helpers.GenerateCACert("test-ca")
+	require.NoError(t, err)
+	newPeer := func(commonName string, verified bool) *peer.Peer {
+		certPEM, _, err := helpers.GenerateSignedCert(commonName, caPEM, caKeyPEM)
+		require.NoError(t, err)
+		block, _ := pem.Decode(certPEM)
+		require.NotNil(t, block)
+		cert, err := x509.ParseCertificate(block.Bytes)
+		require.NoError(t, err)
+		state := tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		if verified {
+			state.VerifiedChains = [][]*x509.Certificate{{cert}}
+		}
+		return &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}, AuthInfo: credentials.TLSInfo{State: state}}
+	}
+	subnets, err := helpers.ParseSubnets("192.168.1.0/24")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		clients string
+		peer    *peer.Peer
+		code    codes.Code
+	}{
+		{"Trusted subject #1 (good)", "CN=other; CN=stats", newPeer("stats", true), codes.OK},
+		{"Trusted common name #2 (good)", "stats", newPeer("stats", true), codes.OK},
+		{"Untrusted client #3 (bad)", "CN=stats", newPeer("client", true), codes.PermissionDenied},
+		{"Not verified certificate #4 (bad)", "CN=stats", newPeer("stats", false), codes.PermissionDenied},
+		{"Clients not configured #5 (bad)", "", newPeer("stats", true), codes.PermissionDenied},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clients, err := helpers.ParseClientSubjects(tc.clients)
+			require.NoError(t, err)
+			guard := NewTrustNet(subnets, testGuardedMethod)
+			guard.SetClients(clients)
+
+			// идентичность клиента определяется предыдущим interceptor в цепочке
+			info := &grpc.UnaryServerInfo{FullMethod: testGuardedMethod}
+			_, err = ClientIdentity(peer.NewContext(context.Background(), tc.peer), nil, info,
+				func(ctx context.Context, req any) (any, error) {
+					return guard.TrustGuard(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+						return "ok", nil
+					})
+				})
+			assert.Equal(t, tc.code, status.Code(err))
+		})
+	}
+}
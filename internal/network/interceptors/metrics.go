@@ -0,0 +1,27 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/denmor86/go-url-shortener/internal/metrics"
+)
+
+// Metrics — interceptor учёта метрик входящих GRPC-запросов.
+func Metrics(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	metrics.ObserveGRPC(info.FullMethod, status.Code(err).String(), time.Since(start))
+	return resp, err
+}
+
+// MetricsStream — interceptor учёта метрик входящих GRPC-потоков (длительность - время жизни потока).
+func MetricsStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	metrics.ObserveGRPC(info.FullMethod, status.Code(err).String(), time.Since(start))
+	return err
+}
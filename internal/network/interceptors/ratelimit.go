@@ -0,0 +1,129 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	pb "github.com/denmor86/go-url-shortener/internal/gen"
+	"github.com/denmor86/go-url-shortener/internal/logger"
+	"github.com/denmor86/go-url-shortener/internal/network/middleware"
+	"github.com/denmor86/go-url-shortener/internal/ratelimit"
+)
+
+// Внутренние константы interceptor
+const (
+	// apiKeyMetadataKey имя ключа API клиента в метаданных запроса
+	apiKeyMetadataKey = "x-api-key"
+	// retryAfterMetadataKey имя заголовка ответа со временем повтора запроса, секунд
+	retryAfterMetadataKey = "retry-after"
+)
+
+// methodGroups - группы ограничения частоты запросов по GRPC методам
+var methodGroups = map[string]string{
+	pb.Shortener_EncodeURL_FullMethodName:       ratelimit.GroupShorten,
+	pb.Shortener_EncodeURLStream_FullMethodName: ratelimit.GroupShorten,
+	pb.Shortener_EncodeURLs_FullMethodName:      ratelimit.GroupBatch,
+	pb.Shortener_DecodeURL_FullMethodName:       ratelimit.GroupRedirect,
+	pb.Shortener_GetURLs_FullMethodName:         ratelimit.GroupUser,
+	pb.Shortener_DeleteURLs_FullMethodName:      ratelimit.GroupUser,
+	pb.Shortener_ListURLs_FullMethodName:        ratelimit.GroupUser,
+	pb.Shortener_GetQuota_FullMethodName:        ratelimit.GroupUser,
+}
+
+// RateLimit - модель interceptor ограничения частоты запросов
+type RateLimit struct {
+	limiter *ratelimit.Limiter // ограничитель
+}
+
+// NewRateLimit - метод формирования объекта interceptor ограничения частоты запросов
+func NewRateLimit(limiter *ratelimit.Limiter) *RateLimit {
+	return &RateLimit{limiter: limiter}
+}
+
+// Unary — interceptor ограничения частоты входящих GRPC-запросов.
+// При превышении лимита возвращается ResourceExhausted с RetryInfo и заголовком retry-after
+func (rl *RateLimit) Unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := rl.allow(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// Stream — interceptor ограничения частоты входящих GRPC-потоков.
+// Для потоков клиента лимит расходуется на каждое принятое сообщение, для остальных - на открытие потока
+func (rl *RateLimit) Stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if info.IsClientStream {
+		return handler(srv, &limitedStream{ServerStream: ss, limit: rl, method: info.FullMethod})
+	}
+	if err := rl.allow(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// allow - метод проверки лимита для вызова method
+func (rl *RateLimit) allow(ctx context.Context, method string) error {
+	group, ok := methodGroups[method]
+	if !ok {
+		return nil
+	}
+	result, err := rl.limiter.Allow(ctx, group, rl.clientKey(ctx))
+	if err != nil {
+		// при недоступности хранилища ограничителей запросы не блокируются
+		logger.WarnCtx(ctx, "Rate limit check failed:", err)
+		return nil
+	}
+	if result.Allowed {
+		return nil
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(retryAfterMetadataKey, middleware.RetryAfterSeconds(result.RetryAfter)))
+	return exhausted(result.RetryAfter)
+}
+
+// exhausted - метод формирования ошибки превышения лимита
+func exhausted(retryAfter time.Duration) error {
+	st := status.New(codes.ResourceExhausted, "too many requests")
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// clientKey - метод определения ключа клиента: зарегистрированный ключ API, клиентский сертификат (mTLS),
+// либо IP адрес клиента. Незарегистрированные ключи API не учитываются, чтобы новый ключ в каждом запросе
+// не обходил ограничение. Метаданные с адресом клиента учитываются только от доверенных прокси (ClientIP)
+func (rl *RateLimit) clientKey(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(apiKeyMetadataKey); len(values) > 0 {
+			if key, ok := rl.limiter.APIKeyClient(values[0]); ok {
+				return key
+			}
+		}
+	}
+	if identity, ok := IdentityFromContext(ctx); ok {
+		return "cert:" + identity.Subject
+	}
+	return "ip:" + clientIP(ctx)
+}
+
+// limitedStream - поток, расходующий лимит на каждое принятое сообщение
+type limitedStream struct {
+	grpc.ServerStream
+	limit  *RateLimit
+	method string
+}
+
+// RecvMsg - метод получения сообщения из потока
+func (s *limitedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.limit.allow(s.Context(), s.method)
+}
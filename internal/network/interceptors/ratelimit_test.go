@@ -0,0 +1,63 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/denmor86/go-url-shortener/internal/gen"
+	"github.com/denmor86/go-url-shortener/internal/ratelimit"
+)
+
+func TestRateLimit_Unary(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), map[string]ratelimit.Policy{
+		ratelimit.GroupBatch: {Rate: 1, Burst: 1},
+	})
+	limiter.SetAPIKeys([]string{"partner-key"})
+	limit := NewRateLimit(limiter)
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	peerCtx := func(ip string) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 5000}})
+	}
+	batch := &grpc.UnaryServerInfo{FullMethod: pb.Shortener_EncodeURLs_FullMethodName}
+
+	testCases := []struct {
+		name string
+		ctx  context.Context
+		info *grpc.UnaryServerInfo
+		code codes.Code
+	}{
+		{"First request #1 (good)", peerCtx("10.0.0.1"), batch, codes.OK},
+		{"Limit exceeded #2 (bad)", peerCtx("10.0.0.1"), batch, codes.ResourceExhausted},
+		{"Other client #3 (good)", peerCtx("10.0.0.2"), batch, codes.OK},
+		{"Spoofed real ip #4 (bad)", metadata.NewIncomingContext(peerCtx("10.0.0.1"), metadata.Pairs("x-real-ip", "10.0.0.3")), batch, codes.ResourceExhausted},
+		{"Registered API key #5 (good)", metadata.NewIncomingContext(peerCtx("10.0.0.1"), metadata.Pairs(apiKeyMetadataKey, "partner-key")), batch, codes.OK},
+		{"Registered API key exceeded #6 (bad)", metadata.NewIncomingContext(peerCtx("10.0.0.1"), metadata.Pairs(apiKeyMetadataKey, "partner-key")), batch, codes.ResourceExhausted},
+		{"Random API key #7 (bad)", metadata.NewIncomingContext(peerCtx("10.0.0.1"), metadata.Pairs(apiKeyMetadataKey, "random-1")), batch, codes.ResourceExhausted},
+		{"Other random API key #8 (bad)", metadata.NewIncomingContext(peerCtx("10.0.0.1"), metadata.Pairs(apiKeyMetadataKey, "random-2")), batch, codes.ResourceExhausted},
+		{"Group without policy #9 (good)", peerCtx("10.0.0.1"), &grpc.UnaryServerInfo{FullMethod: pb.Shortener_DecodeURL_FullMethodName}, codes.OK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := limit.Unary(tc.ctx, nil, tc.info, handler)
+			st := status.Convert(err)
+			require.Equal(t, tc.code, st.Code())
+			if tc.code == codes.ResourceExhausted {
+				require.Len(t, st.Details(), 1)
+				info, ok := st.Details()[0].(*errdetails.RetryInfo)
+				require.True(t, ok)
+				assert.Positive(t, info.GetRetryDelay().AsDuration())
+			}
+		})
+	}
+}
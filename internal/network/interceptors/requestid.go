@@ -0,0 +1,39 @@
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/denmor86/go-url-shortener/internal/helpers"
+	"github.com/denmor86/go-url-shortener/internal/usecase"
+)
+
+// RequestID — interceptor идентификации входящих GRPC-запросов.
+// Идентификатор принимается из метаданных x-request-id (либо формируется новый), возвращается в заголовке ответа
+// и добавляется в контекст запроса и ко всем записям журнала, сделанным при его обработке
+func RequestID(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx = withRequestID(ctx)
+	return handler(ctx, req)
+}
+
+// RequestIDStream — interceptor идентификации входящих GRPC-потоков.
+func RequestIDStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx := withRequestID(ss.Context())
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+// withRequestID - метод определения идентификатора запроса и передачи его клиенту в заголовке ответа
+func withRequestID(ctx context.Context) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(helpers.RequestIDMetadataKey); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	id = helpers.RequestIDOrNew(id)
+	// ошибка возможна только если заголовок уже отправлен, что исключено до вызова обработчика
+	_ = grpc.SetHeader(ctx, metadata.Pairs(helpers.RequestIDMetadataKey, id))
+	return usecase.WithRequestID(ctx, id)
+}
@@ -0,0 +1,52 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/denmor86/go-url-shortener/internal/helpers"
+	"github.com/denmor86/go-url-shortener/internal/logger"
+	"github.com/denmor86/go-url-shortener/internal/usecase"
+)
+
+func TestRequestID(t *testing.T) {
+	testCases := []struct {
+		name      string
+		requestID string
+		keep      bool
+	}{
+		{"Client request ID #1 (good)", "edge-42", true},
+		{"Without request ID #2 (good)", "", false},
+		{"Invalid request ID #3 (bad)", "id\nfake log line", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if len(tc.requestID) > 0 {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(helpers.RequestIDMetadataKey, tc.requestID))
+			}
+			var id string
+			var fields []any
+			handler := func(ctx context.Context, req any) (any, error) {
+				id = usecase.RequestIDFromContext(ctx)
+				fields = logger.Fields(ctx)
+				return nil, nil
+			}
+
+			_, err := RequestID(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testOpenMethod}, handler)
+			assert.NoError(t, err)
+			assert.True(t, helpers.ValidRequestID(id))
+			if tc.keep {
+				assert.Equal(t, tc.requestID, id)
+			} else {
+				assert.NotEqual(t, tc.requestID, id)
+			}
+			assert.Equal(t, []any{"request_id", id}, fields)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package interceptors
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/denmor86/go-url-shortener/internal/tracing"
+)
+
+// Tracing — interceptor трассировки входящих GRPC-запросов.
+// Контекст трассировки вызывающей стороны принимается из метаданных traceparent/tracestate (W3C)
+func Tracing(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, span := startSpan(ctx, info.FullMethod)
+	defer span.End()
+
+	resp, err := handler(ctx, req)
+	setStatus(span, err)
+	return resp, err
+}
+
+// TracingStream — interceptor трассировки входящих GRPC-потоков.
+func TracingStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, span := startSpan(ss.Context(), info.FullMethod)
+	defer span.End()
+
+	err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	setStatus(span, err)
+	return err
+}
+
+// startSpan - метод создания span GRPC вызова
+func startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = tracing.Extract(ctx, tracing.MetadataCarrier(md))
+	}
+	return tracing.Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.method", method),
+		))
+}
+
+// setStatus - метод записи результата GRPC вызова в span
+func setStatus(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+	if err != nil {
+		span.SetStatus(codes.Error, status.Convert(err).Message())
+	}
+}
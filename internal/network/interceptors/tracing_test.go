@@ -0,0 +1,70 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestTracing(t *testing.T) {
+	const parentTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	testCases := []struct {
+		name        string
+		traceparent string
+		err         error
+		status      otelcodes.Code
+	}{
+		{"With traceparent #1 (good)", "00-" + parentTraceID + "-00f067aa0ba902b7-01", nil, otelcodes.Unset},
+		{"Without traceparent #2 (good)", "", nil, otelcodes.Unset},
+		{"Handler error #3 (bad)", "", status.Error(codes.NotFound, "not found"), otelcodes.Error},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if len(tc.traceparent) > 0 {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("traceparent", tc.traceparent))
+			}
+			var handlerSpan trace.SpanContext
+			handler := func(ctx context.Context, req any) (any, error) {
+				handlerSpan = trace.SpanContextFromContext(ctx)
+				return nil, tc.err
+			}
+
+			_, err := Tracing(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testOpenMethod}, handler)
+			assert.Equal(t, tc.err, err)
+
+			spans := recorder.Ended()
+			require.NotEmpty(t, spans)
+			span := spans[len(spans)-1]
+			assert.Equal(t, testOpenMethod, span.Name())
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+			assert.Equal(t, tc.status, span.Status().Code)
+			assert.Equal(t, span.SpanContext().SpanID(), handlerSpan.SpanID())
+			if len(tc.traceparent) > 0 {
+				assert.Equal(t, parentTraceID, span.SpanContext().TraceID().String())
+				assert.True(t, span.Parent().IsRemote())
+			} else {
+				assert.False(t, span.Parent().IsValid())
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Package interceptors предоставляет вспомогательные interceptor методы для поддержки GRPC взаимодействия
+package interceptors
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/denmor86/go-url-shortener/internal/helpers"
+	"github.com/denmor86/go-url-shortener/internal/usecase"
+)
+
+// Внутренние константы interceptor
+const (
+	// realIPMetadataKey имя ключа для IP адреса в метаданных запроса
+	realIPMetadataKey = "x-real-ip"
+)
+
+// PipeNetwork - имя сети соединений в памяти процесса (REST шлюз). Адрес клиента для таких соединений
+// уже определен шлюзом и передается в метаданных x-real-ip
+const PipeNetwork = "pipe"
+
+// TrustNet - модель interceptor для проверки доверенной подсети
+type TrustNet struct {
+	subnets atomic.Pointer[[]*net.IPNet]        // доверенные подсети
+	methods atomic.Pointer[map[string]struct{}] // защищаемые методы
+	clients atomic.Pointer[map[string]struct{}] // доверенные клиенты (субъект или имя владельца сертификата)
+}
+
+// NewTrustNet - метод формирования объекта interceptor для проверки доверенных подсетей
+// для перечисленных методов (полное имя GRPC метода). Доверенные клиенты задаются через SetClients
+func NewTrustNet(subnets []*net.IPNet, methods ...string) *TrustNet {
+	guard := &TrustNet{}
+	guard.SetSubnets(subnets)
+	guard.SetMethods(methods)
+	guard.SetClients(nil)
+	return guard
+}
+
+// SetSubnets - метод замены доверенных подсетей (пустой список запрещает доступ)
+func (guard *TrustNet) SetSubnets(subnets []*net.IPNet) {
+	guard.subnets.Store(&subnets)
+}
+
+// SetMethods - метод замены защищаемых методов (полное имя GRPC метода)
+func (guard *TrustNet) SetMethods(methods []string) {
+	guarded := make(map[string]struct{}, len(methods))
+	for _, method := range methods {
+		guarded[method] = struct{}{}
+	}
+	guard.methods.Store(&guarded)
+}
+
+// SetClients - метод замены доверенных клиентов: субъект сертификата (RFC 2253) или имя владельца.
+// Клиент определяется по проверенному сертификату (ClientIdentity)
+func (guard *TrustNet) SetClients(clients []string) {
+	trusted := make(map[string]struct{}, len(clients))
+	for _, client := range clients {
+		trusted[client] = struct{}{}
+	}
+	guard.clients.Store(&trusted)
+}
+
+// trustedClient - метод проверки идентичности клиента по списку доверенных клиентов
+func (guard *TrustNet) trustedClient(ctx context.Context) bool {
+	identity, ok := IdentityFromContext(ctx)
+	if !ok {
+		return false
+	}
+	clients := *guard.clients.Load()
+	if _, ok = clients[identity.Subject]; ok {
+		return true
+	}
+	_, ok = clients[identity.CommonName]
+	return ok && len(identity.CommonName) > 0
+}
+
+// TrustGuard — interceptor-проверка доверенной подсети для входящих GRPC-запросов.
+// Доступ также разрешен доверенным клиентам, предъявившим проверенный сертификат
+func (guard *TrustNet) TrustGuard(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := guard.allow(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// TrustGuardStream — interceptor-проверка доверенной подсети для входящих GRPC-потоков.
+func (guard *TrustNet) TrustGuardStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := guard.allow(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// allow - метод проверки доступа к методу method: незащищенные методы доступны всем,
+// защищенные - доверенным клиентам и клиентам из доверенных подсетей
+func (guard *TrustNet) allow(ctx context.Context, method string) error {
+	if _, ok := (*guard.methods.Load())[method]; !ok {
+		return nil
+	}
+	if !guard.trustedClient(ctx) && !helpers.SubnetsContain(*guard.subnets.Load(), clientIP(ctx)) {
+		return status.Error(codes.PermissionDenied, "untrusted subnet or client")
+	}
+	return nil
+}
+
+// ClientIP - модель interceptor определения IP адреса клиента
+type ClientIP struct {
+	resolver *helpers.ClientIPResolver // определение адреса с учетом доверенных прокси
+}
+
+// NewClientIP - метод формирования объекта interceptor определения IP адреса клиента
+func NewClientIP(resolver *helpers.ClientIPResolver) *ClientIP {
+	return &ClientIP{resolver: resolver}
+}
+
+// Unary — interceptor определения IP адреса клиента. Адрес добавляется в контекст запроса
+// и используется ограничением частоты запросов и проверкой доверенной подсети
+func (c *ClientIP) Unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(usecase.WithClientIP(ctx, resolveClientIP(ctx, c.resolver)), req)
+}
+
+// Stream — interceptor определения IP адреса клиента для GRPC-потоков.
+func (c *ClientIP) Stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx := usecase.WithClientIP(ss.Context(), resolveClientIP(ss.Context(), c.resolver))
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+// clientIP - метод получения IP адреса клиента (из контекста, либо из адреса соединения)
+func clientIP(ctx context.Context) string {
+	if ip := usecase.ClientIPFromContext(ctx); len(ip) > 0 {
+		return ip
+	}
+	return resolveClientIP(ctx, nil)
+}
+
+// resolveClientIP - метод определения IP адреса клиента. Метаданные forwarded, x-forwarded-for и x-real-ip
+// учитываются только от доверенных прокси, для соединений REST шлюза - x-real-ip
+func resolveClientIP(ctx context.Context, resolver *helpers.ClientIPResolver) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if p.Addr.Network() == PipeNetwork {
+		if values := md.Get(realIPMetadataKey); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	header := make(http.Header)
+	for _, key := range []string{helpers.ForwardedHeader, helpers.ForwardedForHeader, helpers.RealIPHeader} {
+		for _, value := range md.Get(key) {
+			header.Add(key, value)
+		}
+	}
+	return resolver.Resolve(p.Addr.String(), header)
+}
@@ -0,0 +1,185 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/denmor86/go-url-shortener/internal/helpers"
+	"github.com/denmor86/go-url-shortener/internal/usecase"
+)
+
+const (
+	testGuardedMethod = "/shortener.Shortener/GetStatistic"
+	testOpenMethod    = "/shortener.Shortener/DecodeURL"
+)
+
+// testPipeAddr - адрес соединения REST шлюза в памяти процесса
+type testPipeAddr struct{}
+
+func (testPipeAddr) Network() string { return PipeNetwork }
+func (testPipeAddr) String() string  { return PipeNetwork }
+
+func TestTrustNet_TrustGuard(t *testing.T) {
+	subnets, err := helpers.ParseSubnets("192.168.1.0/24, fd00::/8")
+	require.NoError(t, err)
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	}
+
+	testCases := []struct {
+		name     string
+		subnets  []*net.IPNet
+		method   string
+		realIP   string
+		peerAddr net.Addr
+		code     codes.Code
+	}{
+		{
+			name:     "Trusted x-real-ip from gateway #1 (good)",
+			subnets:  subnets,
+			method:   testGuardedMethod,
+			realIP:   "192.168.1.10",
+			peerAddr: testPipeAddr{},
+			code:     codes.OK,
+		},
+		{
+			name:     "Trusted peer #2 (good)",
+			subnets:  subnets,
+			method:   testGuardedMethod,
+			peerAddr: &net.TCPAddr{IP: net.ParseIP("192.168.1.20"), Port: 5000},
+			code:     codes.OK,
+		},
+		{
+			name:     "Trusted IPv6 peer #3 (good)",
+			subnets:  subnets,
+			method:   testGuardedMethod,
+			peerAddr: &net.TCPAddr{IP: net.ParseIP("fd00::10"), Port: 5000},
+			code:     codes.OK,
+		},
+		{
+			name:     "Not guarded method #4 (good)",
+			subnets:  subnets,
+			method:   testOpenMethod,
+			peerAddr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000},
+			code:     codes.OK,
+		},
+		{
+			name:     "Untrusted x-real-ip from gateway #5 (bad)",
+			subnets:  subnets,
+			method:   testGuardedMethod,
+			realIP:   "10.0.0.1",
+			peerAddr: testPipeAddr{},
+			code:     codes.PermissionDenied,
+		},
+		{
+			name:     "Spoofed x-real-ip #6 (bad)",
+			subnets:  subnets,
+			method:   testGuardedMethod,
+			realIP:   "192.168.1.10",
+			peerAddr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000},
+			code:     codes.PermissionDenied,
+		},
+		{
+			name:     "Untrusted peer #7 (bad)",
+			subnets:  subnets,
+			method:   testGuardedMethod,
+			peerAddr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000},
+			code:     codes.PermissionDenied,
+		},
+		{
+			name:    "Without address #8 (bad)",
+			subnets: subnets,
+			method:  testGuardedMethod,
+			code:    codes.PermissionDenied,
+		},
+		{
+			name:     "Empty subnet #9 (bad)",
+			subnets:  nil,
+			method:   testGuardedMethod,
+			realIP:   "192.168.1.10",
+			peerAddr: testPipeAddr{},
+			code:     codes.PermissionDenied,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if len(tc.realIP) > 0 {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(realIPMetadataKey, tc.realIP))
+			}
+			if tc.peerAddr != nil {
+				ctx = peer.NewContext(ctx, &peer.Peer{Addr: tc.peerAddr})
+			}
+			guard := NewTrustNet(tc.subnets, testGuardedMethod)
+			resp, err := guard.TrustGuard(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+
+			assert.Equal(t, tc.code, status.Code(err))
+			if tc.code == codes.OK {
+				assert.Equal(t, "ok", resp)
+			} else {
+				assert.Nil(t, resp)
+			}
+		})
+	}
+}
+
+func TestClientIP_Unary(t *testing.T) {
+	proxies, err := helpers.ParseSubnets("10.0.0.0/8")
+	require.NoError(t, err)
+	clientIP := NewClientIP(helpers.NewClientIPResolver(proxies))
+
+	testCases := []struct {
+		name     string
+		peerAddr net.Addr
+		md       metadata.MD
+		expected string
+	}{
+		{"Peer address #1 (good)", &net.TCPAddr{IP: net.ParseIP("192.168.1.20"), Port: 5000}, nil, "192.168.1.20"},
+		{"Forwarded by trusted proxy #2 (good)", &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000},
+			metadata.Pairs("x-forwarded-for", "203.0.113.7, 10.0.0.2"), "203.0.113.7"},
+		{"Forwarded by untrusted peer #3 (bad)", &net.TCPAddr{IP: net.ParseIP("192.168.1.20"), Port: 5000},
+			metadata.Pairs("x-forwarded-for", "203.0.113.7"), "192.168.1.20"},
+		{"Gateway connection #4 (good)", testPipeAddr{}, metadata.Pairs(realIPMetadataKey, "203.0.113.7"), "203.0.113.7"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := peer.NewContext(metadata.NewIncomingContext(context.Background(), tc.md), &peer.Peer{Addr: tc.peerAddr})
+			var resolved string
+			_, err := clientIP.Unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testOpenMethod}, func(ctx context.Context, req any) (any, error) {
+				resolved = usecase.ClientIPFromContext(ctx)
+				return nil, nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, resolved)
+		})
+	}
+}
+
+func TestTrustNet_SetMethods(t *testing.T) {
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}})
+	guard := NewTrustNet(nil, testGuardedMethod)
+
+	_, err := guard.TrustGuard(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testOpenMethod}, handler)
+	require.NoError(t, err)
+
+	guard.SetMethods([]string{testOpenMethod})
+	_, err = guard.TrustGuard(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testOpenMethod}, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = guard.TrustGuard(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testGuardedMethod}, handler)
+	require.NoError(t, err)
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync/atomic"
 
 	"github.com/google/uuid"
 
@@ -19,44 +21,69 @@ const (
 	tokenCookie = "user-token"
 )
 
+// newUserContextKey - имя ключа признака нового пользователя (cookie выдана в текущем запросе) в передаваемом контексте
+var newUserContextKey usecase.ContextKey = "newUser"
+
 // Authorization - модель middelware для авторизации пользователя
 type Authorization struct {
-	Secret []byte // секрет для JWT
+	secrets atomic.Pointer[[][]byte] // секреты для JWT: текущий (подпись и проверка), затем предыдущие (только проверка)
 }
 
-// NewAuthorization - метод формирования объекта middelware для авторизации пользователя
+// NewAuthorization - метод формирования объекта middelware для авторизации пользователя.
+// Секреты обновляются при перечитывании конфигурации
 func NewAuthorization(cfg *config.Config) *Authorization {
-	return &Authorization{Secret: []byte(cfg.JWTSecret)}
+	auth := &Authorization{}
+	auth.SetSecrets(cfg)
+	cfg.OnReload(auth.SetSecrets)
+	return auth
+}
+
+// SetSecrets - метод установки секретов JWT из конфигурации
+func (auth *Authorization) SetSecrets(cfg *config.Config) {
+	secrets := [][]byte{[]byte(cfg.JWTSecret)}
+	for _, secret := range strings.Split(cfg.JWTPreviousSecrets, ",") {
+		if secret = strings.TrimSpace(secret); len(secret) > 0 {
+			secrets = append(secrets, []byte(secret))
+		}
+	}
+	auth.secrets.Store(&secrets)
+}
+
+// secret - метод получения текущего секрета для подписи JWT
+func (auth *Authorization) secret() []byte {
+	return (*auth.secrets.Load())[0]
 }
 
-// CheckCookie - метод проверки Cookie. Проводит валидацию токена и извлекает UUID пользователя
-func CheckCookie(secret []byte, r *http.Request) (string, error) {
+// CheckCookie - метод проверки Cookie. Проводит валидацию токена текущим или предыдущими секретами и извлекает UUID пользователя
+func (auth *Authorization) CheckCookie(r *http.Request) (string, error) {
 	tokenCookie, err := r.Cookie(tokenCookie)
 	if err != nil {
 		// в запросе нет cookie
 		return "", fmt.Errorf("the request does not contain cookies")
 	}
-	claims, err := helpers.ParseJWT(tokenCookie.Value, []byte(secret))
-	if err != nil {
-		// cookie не прошли валидацию
-		return "", fmt.Errorf("invalid cookies")
-	}
-	if err = claims.Valid(); err != nil {
-		return "", fmt.Errorf("invalid claims jwt")
+	for _, secret := range *auth.secrets.Load() {
+		claims, err := helpers.ParseJWT(tokenCookie.Value, secret)
+		if err != nil {
+			continue
+		}
+		if err = claims.Valid(); err != nil {
+			return "", fmt.Errorf("invalid claims jwt")
+		}
+		return claims.UserID, nil
 	}
-
-	return claims.UserID, nil
+	// cookie не прошли валидацию
+	return "", fmt.Errorf("invalid cookies")
 }
 
 // CookieHandle — middleware-создание cookie для входящих HTTP-запросов.
 func (auth *Authorization) CookieHandle(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		userID, err := CheckCookie(auth.Secret, r)
+		userID, err := auth.CheckCookie(r)
 		if err != nil {
 			// если coockie не прошли проверку, создаем новые
 			userID = uuid.New().String()
 
-			jwtToken, err := helpers.BuildJWT(userID, auth.Secret)
+			jwtToken, err := helpers.BuildJWT(userID, auth.secret())
 			if err != nil {
 				h.ServeHTTP(w, r)
 				return
@@ -68,6 +95,7 @@ func (auth *Authorization) CookieHandle(h http.Handler) http.Handler {
 				HttpOnly: true,
 				Value:    jwtToken,
 			})
+			r = r.WithContext(context.WithValue(r.Context(), newUserContextKey, true))
 		}
 		// создаем контекст, и добавляем в него ID пользователя (чтобы отвязать обработчик от парсинга cookie)
 		ctx := context.WithValue(r.Context(), usecase.UserIDContextKey, userID)
@@ -82,7 +110,7 @@ func (auth *Authorization) AuthHandle(h http.Handler) http.Handler {
 			// в запросе нет cookie, создаем новую
 			userID := uuid.New().String()
 
-			jwtToken, err := helpers.BuildJWT(userID, auth.Secret)
+			jwtToken, err := helpers.BuildJWT(userID, auth.secret())
 			if err != nil {
 				h.ServeHTTP(w, r)
 				return
@@ -97,7 +125,7 @@ func (auth *Authorization) AuthHandle(h http.Handler) http.Handler {
 			w.WriteHeader(http.StatusNoContent)
 			return
 		}
-		userID, err := CheckCookie(auth.Secret, r)
+		userID, err := auth.CheckCookie(r)
 		if err != nil {
 			w.WriteHeader(http.StatusUnauthorized)
 			return
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/denmor86/go-url-shortener/internal/config"
+	"github.com/denmor86/go-url-shortener/internal/helpers"
+)
+
+func TestAuthorization_Reload(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.JWTSecret = "old"
+	auth := NewAuthorization(cfg)
+
+	request := func(secret string) *http.Request {
+		token, err := helpers.BuildJWT("user", []byte(secret))
+		require.NoError(t, err)
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.AddCookie(&http.Cookie{Name: tokenCookie, Value: token})
+		return r
+	}
+
+	userID, err := auth.CheckCookie(request("old"))
+	require.NoError(t, err)
+	assert.Equal(t, "user", userID)
+
+	// ротация секрета: новые токены подписываются новым секретом, старые принимаются до удаления из предыдущих
+	next := config.NewDefaultConfig()
+	next.JWTSecret = "new"
+	next.JWTPreviousSecrets = "old"
+	cfg.Apply(next)
+
+	testCases := []struct {
+		name    string
+		secret  string
+		wantErr bool
+	}{
+		{"New secret #1 (good)", "new", false},
+		{"Previous secret #2 (good)", "old", false},
+		{"Unknown secret #3 (bad)", "other", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			userID, err := auth.CheckCookie(request(tc.secret))
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user", userID)
+		})
+	}
+	assert.Equal(t, []byte("new"), auth.secret())
+
+	// предыдущий секрет удален из конфигурации
+	next = config.NewDefaultConfig()
+	next.JWTSecret = "new"
+	cfg.Apply(next)
+	_, err = auth.CheckCookie(request("old"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/denmor86/go-url-shortener/internal/config"
+)
+
+// bodyLimitContextKey - тип ключа ограничения размера тела запроса в контексте
+type bodyLimitContextKey struct{}
+
+// BodyLimit - модель middleware ограничения размера тела запроса
+type BodyLimit struct {
+	limits map[string]int64 // максимальный размер тела запроса по группам маршрутов, байт
+}
+
+// NewBodyLimit - метод формирования объекта middleware ограничения размера тела запроса по конфигурации,
+// некорректная конфигурация приводит к панике
+func NewBodyLimit(cfg *config.Config) *BodyLimit {
+	limits, err := ParseBodyLimits(cfg.BodyLimits)
+	if err != nil {
+		panic(fmt.Sprintf("can't create body limits: %s ", err.Error()))
+	}
+	return &BodyLimit{limits: limits}
+}
+
+// ParseBodyLimits - метод разбора ограничений размера тела запроса в формате "группа=байт,..."
+func ParseBodyLimits(s string) (map[string]int64, error) {
+	limits := make(map[string]int64)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+		group, value, ok := strings.Cut(item, "=")
+		if !ok || len(strings.TrimSpace(group)) == 0 {
+			return nil, fmt.Errorf("invalid body limit: %s", item)
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("invalid body limit size: %s", item)
+		}
+		limits[strings.TrimSpace(group)] = size
+	}
+	return limits, nil
+}
+
+// Limit — middleware ограничения размера тела запроса группы group
+func (b *BodyLimit) Limit(group string) func(http.Handler) http.Handler {
+	return b.LimitFunc(func(*http.Request) string { return group })
+}
+
+// LimitFunc — middleware ограничения размера тела запроса группы, определяемой по запросу.
+// Запрос с большим Content-Length отклоняется сразу с 413 Request Entity Too Large, иначе чтение
+// тела прерывается ошибкой *http.MaxBytesError. Ограничение распространяется и на распакованное тело (Compression)
+func (b *BodyLimit) LimitFunc(group func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		if b == nil || len(b.limits) == 0 {
+			return h
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit, ok := b.limits[group(r)]
+			if !ok {
+				h.ServeHTTP(w, r)
+				return
+			}
+			if r.ContentLength > limit {
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), bodyLimitContextKey{}, limit)))
+		})
+	}
+}
+
+// bodyLimitFromContext - метод получения ограничения размера тела запроса из контекста
+func bodyLimitFromContext(ctx context.Context) (int64, bool) {
+	limit, ok := ctx.Value(bodyLimitContextKey{}).(int64)
+	return limit, ok
+}
+
+// IsBodyTooLarge - метод проверки, что чтение тела запроса прервано из-за превышения размера
+func IsBodyTooLarge(err error) bool {
+	var maxErr *http.MaxBytesError
+	return errors.As(err, &maxErr)
+}
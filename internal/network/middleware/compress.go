@@ -0,0 +1,309 @@
+// Package middleware предоставляет впомогательные middleware методы для поддержки сетевого взаимодействия
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/denmor86/go-url-shortener/internal/config"
+	"github.com/denmor86/go-url-shortener/internal/logger"
+)
+
+// Encoder - интерфейс алгоритма сжатия HTTP сообщений (Content-Encoding)
+type Encoder interface {
+	// Name - метод получения имени алгоритма в заголовках Content-Encoding и Accept-Encoding
+	Name() string
+	// NewWriter - метод создания потока сжатия данных, записываемых в w
+	NewWriter(w io.Writer) io.WriteCloser
+	// NewReader - метод создания потока распаковки данных из r
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+var (
+	// encodersMu - защита реестра алгоритмов сжатия
+	encodersMu sync.RWMutex
+	// encoders - реестр алгоритмов сжатия по имени
+	encoders = make(map[string]Encoder)
+)
+
+// RegisterEncoder - метод регистрации алгоритма сжатия (например, br или zstd).
+// Вызывается при инициализации приложения, до создания middleware; алгоритм с тем же именем заменяется.
+// Для сжатия ответов алгоритм должен быть указан в конфигурации (compress_encodings)
+func RegisterEncoder(encoder Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[strings.ToLower(encoder.Name())] = encoder
+}
+
+// lookupEncoder - метод получения зарегистрированного алгоритма сжатия по имени
+func lookupEncoder(name string) (Encoder, bool) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	encoder, ok := encoders[strings.ToLower(strings.TrimSpace(name))]
+	return encoder, ok
+}
+
+// compressibleTypes типы контента с поддержкой сжатия
+var compressibleTypes = []string{
+	"application/json",
+	"text/html",
+	"text/plain",
+}
+
+// shouldCompress - проверка необходимости сжатия контента
+func shouldCompress(contentType string) bool {
+	for _, t := range compressibleTypes {
+		if strings.Contains(contentType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compression - модель middleware сжатия ответов и распаковки запросов
+type Compression struct {
+	encoders []Encoder // алгоритмы сжатия ответов в порядке предпочтения сервера
+	minSize  int       // минимальный размер сжимаемого ответа, байт
+}
+
+// NewCompression - метод формирования объекта middleware сжатия по конфигурации,
+// некорректная конфигурация (незарегистрированный алгоритм) приводит к панике
+func NewCompression(cfg *config.Config) *Compression {
+	encoders, err := ParseEncodings(cfg.CompressEncodings)
+	if err != nil {
+		panic(fmt.Sprintf("can't create compression: %s ", err.Error()))
+	}
+	return &Compression{encoders: encoders, minSize: cfg.CompressMinSize}
+}
+
+// ParseEncodings - метод разбора списка алгоритмов сжатия через запятую (алгоритмы должны быть зарегистрированы)
+func ParseEncodings(s string) ([]Encoder, error) {
+	var result []Encoder
+	for _, name := range strings.Split(s, ",") {
+		if len(strings.TrimSpace(name)) == 0 {
+			continue
+		}
+		encoder, ok := lookupEncoder(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown encoding %s", strings.TrimSpace(name))
+		}
+		result = append(result, encoder)
+	}
+	return result, nil
+}
+
+// Handle — middleware сжатия HTTP-ответов и распаковки HTTP-запросов.
+// Алгоритм ответа выбирается по Accept-Encoding с учетом q-значений (при равных значениях - по порядку
+// предпочтения сервера); ответы меньше минимального размера не сжимаются. Тело запроса распаковывается
+// любым зарегистрированным алгоритмом, размер распакованного тела ограничивается так же, как и исходного (BodyLimit)
+func (c *Compression) Handle(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		ow := w
+		if encoder := c.negotiate(r.Header.Values("Accept-Encoding")); encoder != nil {
+			cw := newCompressWriter(w, encoder, c.minSize)
+			ow = cw
+			defer func() {
+				if err := cw.Close(); err != nil {
+					logger.WarnCtx(r.Context(), "Failed to compress response:", err)
+				}
+			}()
+		}
+
+		if encoding := strings.TrimSpace(r.Header.Get("Content-Encoding")); len(encoding) > 0 && !strings.EqualFold(encoding, "identity") {
+			encoder, ok := lookupEncoder(encoding)
+			if !ok {
+				http.Error(w, "unsupported content encoding", http.StatusUnsupportedMediaType)
+				return
+			}
+			cr, err := encoder.NewReader(r.Body)
+			if err != nil {
+				logger.WarnCtx(r.Context(), "Failed to create decompression reader:", err)
+				http.Error(w, "invalid compressed body", http.StatusBadRequest)
+				return
+			}
+			defer cr.Close()
+			r.Body = cr
+			if limit, ok := bodyLimitFromContext(r.Context()); ok {
+				r.Body = http.MaxBytesReader(w, cr, limit)
+			}
+			r.Header.Del("Content-Encoding")
+			r.ContentLength = -1
+		}
+
+		h.ServeHTTP(ow, r)
+	})
+}
+
+// negotiate - метод выбора алгоритма сжатия ответа по значениям Accept-Encoding (nil - без сжатия)
+func (c *Compression) negotiate(values []string) Encoder {
+	accepted := parseAcceptEncoding(values)
+	var best Encoder
+	var bestQ float64
+	for _, encoder := range c.encoders {
+		q, ok := accepted[encoder.Name()]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = encoder, q
+		}
+	}
+	return best
+}
+
+// parseAcceptEncoding - метод разбора Accept-Encoding в соответствие алгоритм -> q-значение.
+// Элементы с некорректным q-значением пропускаются
+func parseAcceptEncoding(values []string) map[string]float64 {
+	accepted := make(map[string]float64)
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			name, params, _ := strings.Cut(item, ";")
+			name = strings.ToLower(strings.TrimSpace(name))
+			if len(name) == 0 {
+				continue
+			}
+			q := 1.0
+			if key, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.EqualFold(strings.TrimSpace(key), "q") {
+				parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+				if err != nil || parsed < 0 || parsed > 1 {
+					continue
+				}
+				q = parsed
+			}
+			accepted[name] = q
+		}
+	}
+	return accepted
+}
+
+// compressWriter реализует интерфейс http.ResponseWriter и позволяет прозрачно для сервера
+// сжимать передаваемые данные и выставлять правильные HTTP-заголовки.
+// Тело ответа накапливается до минимального размера, после чего принимается решение о сжатии
+type compressWriter struct {
+	http.ResponseWriter
+	encoder     Encoder        // алгоритм сжатия
+	minSize     int            // минимальный размер сжимаемого ответа
+	buf         []byte         // накопленное тело ответа (до принятия решения)
+	zw          io.WriteCloser // поток сжатия (nil - ответ не сжимается)
+	status      int            // HTTP статус ответа
+	wroteHeader bool           // признак записи заголовка обработчиком
+	decided     bool           // признак передачи заголовка (решение о сжатии принято)
+}
+
+// newCompressWriter - метод создания http.ResponseWriter со сжатием ответа алгоритмом encoder
+func newCompressWriter(w http.ResponseWriter, encoder Encoder, minSize int) *compressWriter {
+	return &compressWriter{ResponseWriter: w, encoder: encoder, minSize: minSize}
+}
+
+// WriteHeader — запись заголовка. Для сжимаемых ответов передача заголовка откладывается до принятия решения о сжатии
+func (c *compressWriter) WriteHeader(statusCode int) {
+	if c.wroteHeader {
+		return
+	}
+	c.wroteHeader = true
+	c.status = statusCode
+	if !c.compressible() {
+		c.decided = true
+		c.ResponseWriter.WriteHeader(statusCode)
+	}
+}
+
+// Write — запись тела ответа
+func (c *compressWriter) Write(p []byte) (int, error) {
+	if !c.wroteHeader {
+		// заголовок не был записан явно, как и net/http считаем ответ успешным
+		c.WriteHeader(http.StatusOK)
+	}
+	if c.decided {
+		if c.zw != nil {
+			return c.zw.Write(p)
+		}
+		return c.ResponseWriter.Write(p)
+	}
+	c.buf = append(c.buf, p...)
+	if len(c.buf) >= c.minSize {
+		if err := c.start(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close — завершение ответа: передача накопленного тела без сжатия, либо завершение потока сжатия
+func (c *compressWriter) Close() error {
+	if c.wroteHeader && !c.decided {
+		c.decided = true
+		c.ResponseWriter.WriteHeader(c.status)
+		if _, err := c.ResponseWriter.Write(c.buf); err != nil {
+			return err
+		}
+	}
+	if c.zw != nil {
+		return c.zw.Close()
+	}
+	return nil
+}
+
+// flusher - поток сжатия, поддерживающий передачу сжатых данных без завершения сжатия
+type flusher interface {
+	Flush() error
+}
+
+// Flush — отправка клиенту записанных данных (потоковые ответы): решение о сжатии принимается
+// без ожидания минимального размера, сжатые данные передаются без завершения потока сжатия
+func (c *compressWriter) Flush() {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+	if !c.decided {
+		if err := c.start(); err != nil {
+			return
+		}
+	}
+	if f, ok := c.zw.(flusher); ok {
+		if err := f.Flush(); err != nil {
+			return
+		}
+	}
+	if f, ok := c.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap - метод получения оригинального http.ResponseWriter (для http.ResponseController)
+func (c *compressWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+// compressible - метод проверки возможности сжатия ответа (успешный ответ сжимаемого типа, еще не сжатый и не меньше минимального размера)
+func (c *compressWriter) compressible() bool {
+	if c.status < http.StatusOK || c.status >= http.StatusMultipleChoices || c.status == http.StatusNoContent {
+		return false
+	}
+	header := c.Header()
+	if len(header.Get("Content-Encoding")) != 0 || !shouldCompress(header.Get("Content-Type")) {
+		return false
+	}
+	if length, err := strconv.Atoi(header.Get("Content-Length")); err == nil && length < c.minSize {
+		return false
+	}
+	return true
+}
+
+// start - метод начала сжатия ответа: передача заголовка и накопленного тела в поток сжатия
+func (c *compressWriter) start() error {
+	c.decided = true
+	c.Header().Set("Content-Encoding", c.encoder.Name())
+	c.Header().Del("Content-Length")
+	c.ResponseWriter.WriteHeader(c.status)
+	c.zw = c.encoder.NewWriter(c.ResponseWriter)
+	_, err := c.zw.Write(c.buf)
+	c.buf = nil
+	return err
+}
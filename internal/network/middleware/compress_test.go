@@ -0,0 +1,276 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/denmor86/go-url-shortener/internal/config"
+	"github.com/denmor86/go-url-shortener/internal/logger"
+)
+
+// testEncoder - алгоритм сжатия без изменения данных (проверка регистрации алгоритмов)
+type testEncoder struct{}
+
+func (testEncoder) Name() string { return "test" }
+func (testEncoder) NewWriter(w io.Writer) io.WriteCloser {
+	return nopWriteCloser{Writer: w}
+}
+func (testEncoder) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(r), nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func newTestCompression(t testing.TB, encodings string, minSize int) *Compression {
+	t.Helper()
+	cfg := config.NewDefaultConfig()
+	cfg.CompressEncodings = encodings
+	cfg.CompressMinSize = minSize
+	return NewCompression(cfg)
+}
+
+func TestCompression_Negotiate(t *testing.T) {
+	RegisterEncoder(testEncoder{})
+	c := newTestCompression(t, "gzip,deflate,test", 0)
+
+	testCases := []struct {
+		name     string
+		accept   []string
+		expected string
+	}{
+		{"Gzip #1 (good)", []string{"gzip"}, "gzip"},
+		{"Server preference #2 (good)", []string{"deflate, gzip"}, "gzip"},
+		{"Q-values #3 (good)", []string{"gzip;q=0.5, deflate;q=0.8"}, "deflate"},
+		{"Several headers #4 (good)", []string{"gzip;q=0.1", "test"}, "test"},
+		{"Wildcard #5 (good)", []string{"br, *;q=0.5"}, "gzip"},
+		{"Gzip disabled #6 (good)", []string{"gzip;q=0, *"}, "deflate"},
+		{"Registered encoder #7 (good)", []string{"test, gzip;q=0.9"}, "test"},
+		{"All disabled #8 (bad)", []string{"gzip;q=0, deflate;q=0, test;q=0"}, ""},
+		{"Identity only #9 (bad)", []string{"identity"}, ""},
+		{"Wildcard disabled #10 (bad)", []string{"*;q=0"}, ""},
+		{"Invalid q-value #11 (bad)", []string{"gzip;q=2, deflate;q=abc"}, ""},
+		{"Without header #12 (bad)", nil, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encoder := c.negotiate(tc.accept)
+			if len(tc.expected) == 0 {
+				assert.Nil(t, encoder)
+				return
+			}
+			require.NotNil(t, encoder)
+			assert.Equal(t, tc.expected, encoder.Name())
+		})
+	}
+}
+
+func TestNewCompression_UnknownEncoding(t *testing.T) {
+	assert.Panics(t, func() { newTestCompression(t, "gzip,unknown", 0) })
+}
+
+func TestCompression_Handle(t *testing.T) {
+	c := newTestCompression(t, "gzip,deflate", 32)
+	small := `{"result":"ok"}`
+	large := `{"result":"` + strings.Repeat("a", 64) + `"}`
+
+	testCases := []struct {
+		name        string
+		accept      string
+		contentType string
+		status      int
+		body        string
+		encoding    string
+	}{
+		{"Gzip #1 (good)", "gzip", "application/json", http.StatusOK, large, "gzip"},
+		{"Deflate #2 (good)", "deflate", "application/json", http.StatusCreated, large, "deflate"},
+		{"Small response #3 (good)", "gzip", "application/json", http.StatusOK, small, ""},
+		{"Not compressible type #4 (good)", "gzip", "image/png", http.StatusOK, large, ""},
+		{"Error response #5 (good)", "gzip", "application/json", http.StatusBadRequest, large, ""},
+		{"Encoding disabled #6 (good)", "gzip;q=0", "application/json", http.StatusOK, large, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := c.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tc.contentType)
+				w.WriteHeader(tc.status)
+				// тело записывается частями, чтобы проверить накопление до минимального размера
+				for _, part := range []string{tc.body[:8], tc.body[8:]} {
+					_, err := w.Write([]byte(part))
+					require.NoError(t, err)
+				}
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Encoding", tc.accept)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.status, w.Code)
+			assert.Equal(t, tc.encoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			assert.Equal(t, tc.body, decode(t, tc.encoding, w.Body.Bytes()))
+		})
+	}
+}
+
+func TestCompression_HandleFlush(t *testing.T) {
+	c := newTestCompression(t, "gzip,deflate", 1024)
+	body := `{"result":"ok"}` + "\n"
+
+	testCases := []struct {
+		name     string
+		accept   string
+		encoding string
+	}{
+		{"Gzip stream #1 (good)", "gzip", "gzip"},
+		{"Deflate stream #2 (good)", "deflate", "deflate"},
+		{"Without compression #3 (good)", "", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler := c.Handle(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				for i := 0; i < 2; i++ {
+					_, err := rw.Write([]byte(body))
+					require.NoError(t, err)
+					// данные меньше минимального размера передаются клиенту сразу после Flush
+					require.NoError(t, http.NewResponseController(rw).Flush())
+					assert.True(t, w.Flushed)
+					assert.NotZero(t, w.Body.Len())
+				}
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Encoding", tc.accept)
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tc.encoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, body+body, decode(t, tc.encoding, w.Body.Bytes()))
+		})
+	}
+}
+
+func TestCompression_HandleRequest(t *testing.T) {
+	require.NoError(t, logger.Initialize("info"))
+	c := newTestCompression(t, "gzip,deflate", 0)
+	handler := c.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write(body)
+	}))
+
+	var deflated bytes.Buffer
+	zw := zlib.NewWriter(&deflated)
+	_, err := zw.Write([]byte("https://ya.ru"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	testCases := []struct {
+		name     string
+		encoding string
+		body     []byte
+		status   int
+		expected string
+	}{
+		{"Deflate body #1 (good)", "deflate", deflated.Bytes(), http.StatusOK, "https://ya.ru"},
+		{"Identity body #2 (good)", "identity", []byte("https://ya.ru"), http.StatusOK, "https://ya.ru"},
+		{"Invalid gzip body #3 (bad)", "gzip", []byte("https://ya.ru"), http.StatusBadRequest, ""},
+		{"Unknown encoding #4 (bad)", "compress", []byte("https://ya.ru"), http.StatusUnsupportedMediaType, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tc.body))
+			r.Header.Set("Content-Encoding", tc.encoding)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.status, w.Code)
+			if tc.status == http.StatusOK {
+				assert.Equal(t, tc.expected, w.Body.String())
+			}
+		})
+	}
+}
+
+// decode - метод распаковки тела ответа
+func decode(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+	var r io.Reader = bytes.NewReader(body)
+	switch encoding {
+	case "gzip":
+		zr, err := gzip.NewReader(r)
+		require.NoError(t, err)
+		r = zr
+	case "deflate":
+		zr, err := zlib.NewReader(r)
+		require.NoError(t, err)
+		r = zr
+	}
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// batchResponse - метод формирования типичного ответа на пакетный запрос сокращения ссылок
+func batchResponse(b *testing.B, items int) []byte {
+	b.Helper()
+	type item struct {
+		ID  string `json:"correlation_id"`
+		URL string `json:"short_url"`
+	}
+	response := make([]item, 0, items)
+	for i := 0; i < items; i++ {
+		response = append(response, item{ID: fmt.Sprintf("%08d-7d5c-4f59-9d5c-0f0e3c1d2b7a", i), URL: fmt.Sprintf("http://localhost:8080/%08X", i*2654435761)})
+	}
+	data, err := json.Marshal(response)
+	require.NoError(b, err)
+	return data
+}
+
+// BenchmarkCompression - сравнение затрат на сжатие типичных ответов на пакетный запрос
+func BenchmarkCompression(b *testing.B) {
+	c := newTestCompression(b, "gzip,deflate", config.DefaultCompressMinSize)
+	for _, items := range []int{10, 100, 1000} {
+		body := batchResponse(b, items)
+		handler := c.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			w.Write(body)
+		}))
+		for _, encoding := range []string{"identity", "gzip", "deflate"} {
+			b.Run(fmt.Sprintf("%s/%d", encoding, items), func(b *testing.B) {
+				r := httptest.NewRequest(http.MethodPost, "/api/shorten/batch", nil)
+				r.Header.Set("Accept-Encoding", encoding)
+				var size int
+				b.SetBytes(int64(len(body)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					w := httptest.NewRecorder()
+					handler.ServeHTTP(w, r)
+					size = w.Body.Len()
+				}
+				b.ReportMetric(float64(size)/float64(len(body)), "ratio")
+			})
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"sync"
+)
+
+func init() {
+	RegisterEncoder(newGzipEncoder())
+	RegisterEncoder(newDeflateEncoder())
+}
+
+// pooledWriter - поток сжатия, возвращаемый в пул после закрытия
+type pooledWriter struct {
+	io.WriteCloser
+	release func() // возврат потока в пул
+}
+
+// Close - метод завершения сжатия и возврата потока в пул
+func (w *pooledWriter) Close() error {
+	err := w.WriteCloser.Close()
+	w.release()
+	return err
+}
+
+// Flush - метод передачи сжатых данных в базовый поток без завершения сжатия
+func (w *pooledWriter) Flush() error {
+	if f, ok := w.WriteCloser.(flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// gzipEncoder - алгоритм сжатия gzip (RFC 1952)
+type gzipEncoder struct {
+	pool sync.Pool // пул потоков сжатия
+}
+
+// newGzipEncoder - метод создания алгоритма сжатия gzip
+func newGzipEncoder() *gzipEncoder {
+	return &gzipEncoder{pool: sync.Pool{
+		New: func() any {
+			return gzip.NewWriter(io.Discard)
+		},
+	}}
+}
+
+// Name - метод получения имени алгоритма
+func (e *gzipEncoder) Name() string {
+	return "gzip"
+}
+
+// NewWriter - метод создания потока сжатия (поток берется из пула)
+func (e *gzipEncoder) NewWriter(w io.Writer) io.WriteCloser {
+	zw := e.pool.Get().(*gzip.Writer)
+	zw.Reset(w)
+	return &pooledWriter{WriteCloser: zw, release: func() { e.pool.Put(zw) }}
+}
+
+// NewReader - метод создания потока распаковки
+func (e *gzipEncoder) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// deflateEncoder - алгоритм сжатия deflate (в HTTP - формат zlib, RFC 1950)
+type deflateEncoder struct {
+	pool sync.Pool // пул потоков сжатия
+}
+
+// newDeflateEncoder - метод создания алгоритма сжатия deflate
+func newDeflateEncoder() *deflateEncoder {
+	return &deflateEncoder{pool: sync.Pool{
+		New: func() any {
+			return zlib.NewWriter(io.Discard)
+		},
+	}}
+}
+
+// Name - метод получения имени алгоритма
+func (e *deflateEncoder) Name() string {
+	return "deflate"
+}
+
+// NewWriter - метод создания потока сжатия (поток берется из пула)
+func (e *deflateEncoder) NewWriter(w io.Writer) io.WriteCloser {
+	zw := e.pool.Get().(*zlib.Writer)
+	zw.Reset(w)
+	return &pooledWriter{WriteCloser: zw, release: func() { e.pool.Put(zw) }}
+}
+
+// NewReader - метод создания потока распаковки
+func (e *deflateEncoder) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/denmor86/go-url-shortener/internal/idempotency"
+	"github.com/denmor86/go-url-shortener/internal/logger"
+	"github.com/denmor86/go-url-shortener/internal/usecase"
+)
+
+// skipReplayHeaders - заголовки, которые не сохраняются для повторного ответа
+// (относятся к конкретному запросу или формируются внешними middleware)
+var skipReplayHeaders = []string{"Set-Cookie", "X-Request-Id", "Content-Encoding", "Content-Length", "Date", "Traceparent", "Tracestate"}
+
+// Idempotency - модель middleware повторного ответа по ключу идемпотентности
+type Idempotency struct {
+	keeper *idempotency.Keeper // хранение результатов (nil - ключи идемпотентности не поддерживаются)
+}
+
+// NewIdempotency - метод формирования объекта middleware повторного ответа по ключу идемпотентности
+func NewIdempotency(keeper *idempotency.Keeper) *Idempotency {
+	return &Idempotency{keeper: keeper}
+}
+
+// Handle — middleware обработки заголовка Idempotency-Key в POST запросах.
+// Первый ответ сохраняется и повторно отправляется без изменений на запросы с тем же ключом;
+// ключ, использованный с другим запросом, отклоняется с 422, запрос с ключом, который еще выполняется, - с 409.
+// Ключи пользователей (cookie) не пересекаются. Результат запроса без cookie сохраняется и для выданного пользователя,
+// и без пользователя: повтор без cookie проверяется только по ключу и отпечатку запроса и не восстанавливает cookie
+func (i *Idempotency) Handle(h http.Handler) http.Handler {
+	if i == nil || i.keeper == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotency.HeaderName)
+		if r.Method != http.MethodPost || len(key) == 0 {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if !idempotency.ValidKey(key) {
+			http.Error(w, "invalid idempotency key", http.StatusBadRequest)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if IsBodyTooLarge(err) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, "error read from body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		userID, _ := r.Context().Value(usecase.UserIDContextKey).(string)
+		userKey := "http|" + userID + "|" + key
+		newUser, _ := r.Context().Value(newUserContextKey).(bool)
+		if newUser {
+			// запрос без cookie получает нового пользователя, поэтому его повтор без cookie ищется только по ключу
+			key = "http||" + key
+		} else {
+			key = userKey
+		}
+		// результат сохраняется и при отмене запроса клиентом
+		ctx := context.WithoutCancel(r.Context())
+
+		fingerprint := idempotency.Fingerprint([]byte(r.Method), []byte(r.URL.Path), body)
+		record, err := i.keeper.Begin(ctx, key, fingerprint)
+		switch {
+		case errors.Is(err, idempotency.ErrKeyMismatch):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		case errors.Is(err, idempotency.ErrInProgress):
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		case err != nil:
+			// при недоступности хранилища запрос выполняется без сохранения результата
+			logger.WarnCtx(r.Context(), "Idempotency key check failed:", err)
+			h.ServeHTTP(w, r)
+			return
+		case record != nil:
+			replay(w, record)
+			return
+		}
+
+		rw := &recordingResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		completed := false
+		defer func() {
+			if !completed {
+				if err := i.keeper.Abort(ctx, key); err != nil {
+					logger.WarnCtx(ctx, "Idempotency key abort failed:", err)
+				}
+			}
+		}()
+		h.ServeHTTP(rw, r)
+
+		// ошибки сервера и превышение лимитов не сохраняются, чтобы запрос можно было повторить
+		if rw.status >= http.StatusInternalServerError || rw.status == http.StatusTooManyRequests {
+			return
+		}
+		record = &idempotency.Record{Fingerprint: fingerprint, Status: rw.status, Header: rw.header, Body: rw.body.Bytes()}
+		if err := i.keeper.Complete(ctx, key, *record); err != nil {
+			logger.WarnCtx(ctx, "Idempotency key save failed:", err)
+			return
+		}
+		completed = true
+		if newUser {
+			// повтор с выданной в ответе cookie ищется по ключу нового пользователя
+			i.completeUserKey(ctx, userKey, *record)
+		}
+	})
+}
+
+// completeUserKey - метод сохранения результата запроса без cookie по ключу выданного пользователя
+func (i *Idempotency) completeUserKey(ctx context.Context, key string, record idempotency.Record) {
+	if _, err := i.keeper.Begin(ctx, key, record.Fingerprint); err != nil {
+		logger.WarnCtx(ctx, "Idempotency key check failed:", err)
+		return
+	}
+	if err := i.keeper.Complete(ctx, key, record); err != nil {
+		logger.WarnCtx(ctx, "Idempotency key save failed:", err)
+	}
+}
+
+// replay - метод повторной отправки сохраненного ответа
+func replay(w http.ResponseWriter, record *idempotency.Record) {
+	for key, value := range record.Header {
+		w.Header().Set(key, value)
+	}
+	w.Header().Set(idempotency.ReplayedHeader, "true")
+	w.WriteHeader(record.Status)
+	w.Write(record.Body)
+}
+
+// recordingResponseWriter - http.ResponseWriter, сохраняющий копию ответа
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	status      int               // HTTP статус ответа
+	header      map[string]string // заголовки ответа
+	body        bytes.Buffer      // тело ответа
+	wroteHeader bool              // признак записи заголовка
+}
+
+// WriteHeader - метод записи заголовка ответа
+func (w *recordingResponseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = statusCode
+	w.header = make(map[string]string)
+	for key := range w.ResponseWriter.Header() {
+		w.header[key] = w.ResponseWriter.Header().Get(key)
+	}
+	for _, key := range skipReplayHeaders {
+		delete(w.header, key)
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write - метод записи тела ответа
+func (w *recordingResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush - метод отправки записанных данных клиенту (потоковые ответы)
+func (w *recordingResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap - метод получения оригинального http.ResponseWriter (для http.ResponseController)
+func (w *recordingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -37,6 +37,10 @@ func (r *loggingResponseWriter) Write(b []byte) (int, error) {
 	// записываем ответ, используя оригинальный http.ResponseWriter
 	size, err := r.ResponseWriter.Write(b)
 	r.responseData.size += size // захватываем размер
+	if r.responseData.status == 0 {
+		// как и net/http, запись тела без заголовка считается успешным ответом
+		r.responseData.status = http.StatusOK
+	}
 	return size, err
 }
 
@@ -47,6 +51,22 @@ func (r *loggingResponseWriter) WriteHeader(statusCode int) {
 	r.responseData.status = statusCode // захватываем код статуса
 }
 
+// Flush - метод отправки буферизированных данных клиенту (потоковые ответы)
+func (r *loggingResponseWriter) Flush() {
+	if r.responseData.status == 0 {
+		// как и net/http, отправка данных без заголовка считается успешным ответом
+		r.responseData.status = http.StatusOK
+	}
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap - метод получения оригинального http.ResponseWriter (для http.ResponseController)
+func (r *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // LogHandle — middleware-логер для входящих HTTP-запросов.
 func LogHandle(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -66,9 +86,10 @@ func LogHandle(h http.Handler) http.Handler {
 
 		duration := time.Since(start)
 
-		logger.Info("got incoming HTTP request",
+		logger.InfoCtx(r.Context(), "got incoming HTTP request",
 			"uri", r.RequestURI,
 			"method", r.Method,
+			"client_ip", clientIP(r),
 			"status", responseData.status,
 			"duration", duration,
 			"size", responseData.size,
@@ -0,0 +1,70 @@
+// Package middleware предоставляет впомогательные middleware методы для поддержки сетевого взаимодействия
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/denmor86/go-url-shortener/internal/metrics"
+)
+
+// unmatchedRoute - значение метки route для запросов, не сопоставленных ни одному маршруту
+const unmatchedRoute = "unmatched"
+
+// statusResponseWriter - реализация http.ResponseWriter, сохраняющая код ответа
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader - метод записи кода ответа
+func (w *statusResponseWriter) WriteHeader(statusCode int) {
+	if w.status == 0 {
+		w.status = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write - метод записи тела ответа
+func (w *statusResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush - метод отправки буферизированных данных клиенту (потоковые ответы)
+func (w *statusResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap - метод получения оригинального http.ResponseWriter (для http.ResponseController)
+func (w *statusResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// MetricsHandle — middleware учёта метрик входящих HTTP-запросов.
+// Запросы группируются по шаблону маршрута chi (например, /{id}), а не по фактическому пути,
+// чтобы количество временных рядов не зависело от количества коротких ссылок
+func MetricsHandle(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusResponseWriter{ResponseWriter: w}
+
+		h.ServeHTTP(sw, r)
+
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && len(rctx.RoutePattern()) > 0 {
+			route = rctx.RoutePattern()
+		}
+		if sw.status == 0 {
+			sw.status = http.StatusOK
+		}
+		metrics.ObserveHTTP(route, r.Method, strconv.Itoa(sw.status), time.Since(start))
+	})
+}
@@ -0,0 +1,70 @@
+// Package middleware предоставляет впомогательные middleware методы для поддержки сетевого взаимодействия
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/denmor86/go-url-shortener/internal/logger"
+	"github.com/denmor86/go-url-shortener/internal/ratelimit"
+)
+
+// APIKeyHeader - HTTP заголовок с ключом API клиента
+const APIKeyHeader = "X-API-Key"
+
+// RateLimit - модель middleware ограничения частоты запросов
+type RateLimit struct {
+	limiter *ratelimit.Limiter // ограничитель (nil - ограничение отключено)
+}
+
+// NewRateLimit - метод формирования объекта middleware ограничения частоты запросов
+func NewRateLimit(limiter *ratelimit.Limiter) *RateLimit {
+	return &RateLimit{limiter: limiter}
+}
+
+// Limit — middleware ограничения частоты запросов группы group.
+func (rl *RateLimit) Limit(group string) func(http.Handler) http.Handler {
+	return rl.LimitFunc(func(*http.Request) string { return group })
+}
+
+// LimitFunc — middleware ограничения частоты запросов группы, определяемой по запросу.
+// При превышении лимита возвращается 429 Too Many Requests с заголовком Retry-After
+func (rl *RateLimit) LimitFunc(group func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		if rl == nil || rl.limiter == nil {
+			return h
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result, err := rl.limiter.Allow(r.Context(), group(r), rl.clientKey(r))
+			if err != nil {
+				// при недоступности хранилища ограничителей запросы не блокируются
+				logger.WarnCtx(r.Context(), "Rate limit check failed:", err)
+				h.ServeHTTP(w, r)
+				return
+			}
+			if !result.Allowed {
+				w.Header().Set("Retry-After", RetryAfterSeconds(result.RetryAfter))
+				http.Error(w, "too many requests", http.StatusTooManyRequests)
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientKey - метод определения ключа клиента: зарегистрированный ключ API, либо IP адрес.
+// Пользователь из cookie не учитывается: cookie выдается любому клиенту без проверки,
+// и каждый новый пользователь получал бы отдельный лимит
+func (rl *RateLimit) clientKey(r *http.Request) string {
+	if key, ok := rl.limiter.APIKeyClient(r.Header.Get(APIKeyHeader)); ok {
+		return key
+	}
+	return "ip:" + clientIP(r)
+}
+
+// RetryAfterSeconds - метод формирования значения заголовка Retry-After (целое число секунд, не менее 1)
+func RetryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Max(1, math.Ceil(d.Seconds()))))
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/denmor86/go-url-shortener/internal/config"
+	"github.com/denmor86/go-url-shortener/internal/helpers"
+	"github.com/denmor86/go-url-shortener/internal/ratelimit"
+)
+
+func TestRateLimit_LimitFunc(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), map[string]ratelimit.Policy{
+		ratelimit.GroupBatch: {Rate: 0.01, Burst: 1},
+	})
+	limiter.SetAPIKeys([]string{"partner-key"})
+	auth := NewAuthorization(cfg)
+	handler := NewRateLimit(limiter).Limit(ratelimit.GroupBatch)(auth.CookieHandle(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusCreated) })))
+
+	// новый пользователь с действительным cookie, выданным сервисом
+	newCookie := func() *http.Cookie {
+		token, err := helpers.BuildJWT(uuid.New().String(), []byte(cfg.JWTSecret))
+		require.NoError(t, err)
+		return &http.Cookie{Name: tokenCookie, Value: token}
+	}
+
+	testCases := []struct {
+		name   string
+		ip     string
+		apiKey string
+		cookie *http.Cookie
+		status int
+	}{
+		{"First request #1 (good)", "10.0.0.1", "", nil, http.StatusCreated},
+		{"Limit exceeded #2 (bad)", "10.0.0.1", "", nil, http.StatusTooManyRequests},
+		{"New cookie #3 (bad)", "10.0.0.1", "", newCookie(), http.StatusTooManyRequests},
+		{"Other new cookie #4 (bad)", "10.0.0.1", "", newCookie(), http.StatusTooManyRequests},
+		{"Random API key #5 (bad)", "10.0.0.1", uuid.New().String(), nil, http.StatusTooManyRequests},
+		{"Other random API key #6 (bad)", "10.0.0.1", uuid.New().String(), nil, http.StatusTooManyRequests},
+		{"Registered API key #7 (good)", "10.0.0.1", "partner-key", nil, http.StatusCreated},
+		{"Registered API key exceeded #8 (bad)", "10.0.0.2", "partner-key", nil, http.StatusTooManyRequests},
+		{"Other client #9 (good)", "10.0.0.3", "", newCookie(), http.StatusCreated},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/shorten/batch", nil)
+			r.RemoteAddr = tc.ip + ":5000"
+			if len(tc.apiKey) > 0 {
+				r.Header.Set(APIKeyHeader, tc.apiKey)
+			}
+			if tc.cookie != nil {
+				r.AddCookie(tc.cookie)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			assert.Equal(t, tc.status, w.Code)
+			if tc.status == http.StatusTooManyRequests {
+				assert.NotEmpty(t, w.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func TestRateLimit_DefaultConfig(t *testing.T) {
+	// по-умолчанию ограничение частоты запросов отключено
+	cfg := config.NewDefaultConfig()
+	policies, err := ratelimit.ParsePolicies(cfg.RateLimits)
+	require.NoError(t, err)
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), policies)
+	rl := NewRateLimit(limiter)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusCreated) })
+
+	for _, group := range []string{ratelimit.GroupShorten, ratelimit.GroupBatch, ratelimit.GroupRedirect, ratelimit.GroupUser} {
+		t.Run(group, func(t *testing.T) {
+			limited := rl.Limit(group)(handler)
+			for i := 0; i < 100; i++ {
+				r := httptest.NewRequest(http.MethodPost, "/", nil)
+				r.RemoteAddr = "10.0.0.1:5000"
+				w := httptest.NewRecorder()
+				limited.ServeHTTP(w, r)
+				require.Equal(t, http.StatusCreated, w.Code, "request %d", i+1)
+			}
+		})
+	}
+}
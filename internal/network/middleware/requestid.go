@@ -0,0 +1,20 @@
+// Package middleware предоставляет впомогательные middleware методы для поддержки сетевого взаимодействия
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/denmor86/go-url-shortener/internal/helpers"
+	"github.com/denmor86/go-url-shortener/internal/usecase"
+)
+
+// RequestIDHandle — middleware идентификации входящих HTTP-запросов.
+// Идентификатор принимается из заголовка X-Request-ID (либо формируется новый), возвращается в ответе
+// и добавляется в контекст запроса и ко всем записям журнала, сделанным при его обработке
+func RequestIDHandle(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := helpers.RequestIDOrNew(r.Header.Get(helpers.RequestIDHeader))
+		w.Header().Set(helpers.RequestIDHeader, id)
+		h.ServeHTTP(w, r.WithContext(usecase.WithRequestID(r.Context(), id)))
+	})
+}
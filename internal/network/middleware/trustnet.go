@@ -4,42 +4,71 @@ package middleware
 import (
 	"net"
 	"net/http"
-)
+	"sync/atomic"
 
-// Внутренние константы middelware
-const (
-	// tokenIpHeaderKey имя ключа для IP адреса в заголовке запроса
-	tokenIPHeaderKey = "X-Real-IP"
+	"github.com/denmor86/go-url-shortener/internal/config"
+	"github.com/denmor86/go-url-shortener/internal/helpers"
+	"github.com/denmor86/go-url-shortener/internal/logger"
+	"github.com/denmor86/go-url-shortener/internal/usecase"
 )
 
-// Authorization - модель middelware для авторизации пользователя
+// TrustNet - модель middelware для проверки доверенной подсети
 type TrustNet struct {
-	subnet *net.IPNet // секрет для JWT
+	subnets atomic.Pointer[[]*net.IPNet] // доверенные подсети
+}
+
+// NewTrustNet - метод формирования объекта middelware для проверки доверенных подсетей
+func NewTrustNet(subnets []*net.IPNet) *TrustNet {
+	guard := &TrustNet{}
+	guard.SetSubnets(subnets)
+	return guard
 }
 
-// NewAuthorization - метод формирования объекта middelware для проверки доверенной подсети
-func NewTrustNet(subnet *net.IPNet) *TrustNet {
-	return &TrustNet{subnet: subnet}
+// SetSubnets - метод замены доверенных подсетей (пустой список запрещает доступ)
+func (guard *TrustNet) SetSubnets(subnets []*net.IPNet) {
+	guard.subnets.Store(&subnets)
 }
 
 // TrustGuard — middleware-проверка доверенной подсети для входящих HTTP-запросов.
+// Адрес клиента определяется ClientIP.Handle (заголовки учитываются только от доверенных прокси)
 func (guard *TrustNet) TrustGuard(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		realIP := r.Header.Get(tokenIPHeaderKey)
-		if len(realIP) == 0 {
-			w.WriteHeader(http.StatusForbidden)
-			return
-		}
-		ip := net.ParseIP(realIP)
-		if ip == nil {
-			w.WriteHeader(http.StatusForbidden)
-			return
-		}
-
-		if ok := guard.subnet.Contains(ip); ok {
+		if helpers.SubnetsContain(*guard.subnets.Load(), clientIP(r)) {
 			h.ServeHTTP(w, r)
 		} else {
 			w.WriteHeader(http.StatusForbidden)
 		}
 	})
 }
+
+// ClientIP - модель middleware определения IP адреса клиента
+type ClientIP struct {
+	resolver *helpers.ClientIPResolver // определение адреса с учетом доверенных прокси
+}
+
+// NewClientIP - метод формирования объекта middleware определения IP адреса клиента по конфигурации
+func NewClientIP(cfg *config.Config) *ClientIP {
+	proxies, err := helpers.ParseSubnets(cfg.TrustedProxies)
+	if err != nil {
+		// без доверенных прокси используется адрес соединения, подмена адреса заголовками невозможна
+		logger.Warn("Invalid trusted proxies:", err)
+	}
+	return &ClientIP{resolver: helpers.NewClientIPResolver(proxies)}
+}
+
+// Handle — middleware определения IP адреса клиента. Адрес добавляется в контекст запроса
+// и используется журналом, ограничением частоты запросов и проверкой доверенной подсети
+func (c *ClientIP) Handle(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := c.resolver.Resolve(r.RemoteAddr, r.Header)
+		h.ServeHTTP(w, r.WithContext(usecase.WithClientIP(r.Context(), ip)))
+	})
+}
+
+// clientIP - метод получения IP адреса клиента (из контекста, либо из адреса соединения)
+func clientIP(r *http.Request) string {
+	if ip := usecase.ClientIPFromContext(r.Context()); len(ip) > 0 {
+		return ip
+	}
+	return helpers.RemoteIP(r.RemoteAddr)
+}
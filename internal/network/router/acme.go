@@ -0,0 +1,51 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/denmor86/go-url-shortener/internal/config"
+	"github.com/denmor86/go-url-shortener/internal/network/middleware"
+)
+
+// ACMEChallengePath - префикс маршрутов проверки владения доменом ACME (HTTP-01)
+const ACMEChallengePath = "/.well-known/acme-challenge/"
+
+// HandleACMEChallenge - метод подключения обработчика проверки владения доменом ACME (HTTP-01)
+func HandleACMEChallenge(r chi.Router, challenge http.Handler) {
+	r.With(middleware.LogHandle).Handle(ACMEChallengePath+"*", challenge)
+}
+
+// ChallengeRouter - метод формирования обработки запросов HTTP сервера проверки владения доменом ACME.
+// Остальные запросы перенаправляются на HTTPS адрес сервиса
+func ChallengeRouter(cfg *config.Config, challenge http.Handler) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middleware.RequestIDHandle)
+	r.Use(middleware.NewClientIP(cfg).Handle)
+	HandleACMEChallenge(r, challenge)
+	r.NotFound(redirectHTTPS(cfg.BaseURL))
+	return r
+}
+
+// redirectHTTPS - метод формирования обработчика перенаправления GET и HEAD запросов на HTTPS адрес сервиса
+func redirectHTTPS(baseURL string) http.HandlerFunc {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		base = &url.URL{}
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "use HTTPS", http.StatusBadRequest)
+			return
+		}
+		host := base.Host
+		if len(host) == 0 {
+			host = r.Host
+		}
+		target := url.URL{Scheme: "https", Host: host, Path: strings.TrimSuffix(base.Path, "/") + r.URL.Path, RawQuery: r.URL.RawQuery}
+		http.Redirect(w, r, target.String(), http.StatusMovedPermanently)
+	}
+}
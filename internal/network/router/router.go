@@ -3,54 +3,77 @@ package router
 
 import (
 	"net"
+	"net/http"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
 
 	"github.com/denmor86/go-url-shortener/internal/config"
+	"github.com/denmor86/go-url-shortener/internal/helpers"
+	"github.com/denmor86/go-url-shortener/internal/idempotency"
 	"github.com/denmor86/go-url-shortener/internal/logger"
+	"github.com/denmor86/go-url-shortener/internal/metrics"
 	"github.com/denmor86/go-url-shortener/internal/network/handlers"
 	"github.com/denmor86/go-url-shortener/internal/network/middleware"
+	"github.com/denmor86/go-url-shortener/internal/ratelimit"
 	"github.com/denmor86/go-url-shortener/internal/usecase"
 )
 
-// HandleRouter - метод формирования обработки запросов из внешнего API
-func HandleRouter(cfg *config.Config, use *usecase.UsecaseHTTP) chi.Router {
+// HandleRouter - метод формирования обработки запросов из внешнего API.
+// Если передан ограничитель, к группам маршрутов применяются политики ограничения частоты запросов.
+// Если передано хранение результатов, запросы на сокращение ссылок поддерживают заголовок Idempotency-Key
+func HandleRouter(cfg *config.Config, use *usecase.UsecaseHTTP, limiter *ratelimit.Limiter, keeper *idempotency.Keeper) chi.Router {
 	auth := middleware.NewAuthorization(cfg)
+	limit := middleware.NewRateLimit(limiter)
+	idem := middleware.NewIdempotency(keeper)
+	body := middleware.NewBodyLimit(cfg)
+	compress := middleware.NewCompression(cfg)
 	r := chi.NewRouter()
+	r.Use(middleware.RequestIDHandle)
+	r.Use(middleware.NewClientIP(cfg).Handle)
+	r.Use(middleware.TraceHandle)
+	r.Use(middleware.MetricsHandle)
 	r.Route("/", func(r chi.Router) {
 		r.Use(middleware.LogHandle)
-		r.Get("/{id}", handlers.DecodeURL(use))
-		r.With(middleware.GzipHandle).With(auth.CookieHandle).
-			Post("/", handlers.EncodeURL(use))
+		r.With(limit.Limit(ratelimit.GroupRedirect)).Get("/{id}", handlers.DecodeURL(use))
+		r.With(limit.Limit(ratelimit.GroupShorten)).With(body.Limit(ratelimit.GroupShorten)).With(compress.Handle).
+			With(auth.CookieHandle).With(idem.Handle).Post("/", handlers.EncodeURL(use))
 		r.Route("/api", func(r chi.Router) {
-			r.Use(middleware.GzipHandle)
+			// ограничение размера тела устанавливается до распаковки, чтобы распространяться и на распакованные данные
+			r.Use(body.LimitFunc(apiGroup))
+			r.Use(compress.Handle)
 			r.Route("/shorten", func(r chi.Router) {
 				r.Use(auth.CookieHandle)
-				r.Post("/", handlers.EncodeURLJson(use))
-				r.Post("/batch", handlers.EncodeURLJsonBatch(use))
+				r.With(limit.Limit(ratelimit.GroupShorten)).With(idem.Handle).Post("/", handlers.EncodeURLJson(use))
+				r.With(limit.Limit(ratelimit.GroupBatch)).With(idem.Handle).Post("/batch", handlers.EncodeURLJsonBatch(use))
 			})
 			r.Route("/user", func(r chi.Router) {
 				r.Route("/urls", func(r chi.Router) {
+					r.Use(limit.Limit(ratelimit.GroupUser))
 					r.Use(auth.AuthHandle)
 					r.Get("/", handlers.GetURLs(use))
 					r.Delete("/", handlers.DeleteURLs(use))
 				})
+				r.Route("/quota", func(r chi.Router) {
+					r.Use(limit.Limit(ratelimit.GroupUser))
+					r.Use(auth.CookieHandle)
+					r.Get("/", handlers.GetQuota(use))
+				})
 			})
-			if len(cfg.TrustedSubnet) != 0 {
-				_, trustedSubnet, err := net.ParseCIDR(strings.TrimSpace(cfg.TrustedSubnet))
-				if err != nil {
-					logger.Warn(err)
-				}
-				trust := middleware.NewTrustNet(trustedSubnet)
-				r.Route("/internal", func(r chi.Router) {
-					r.Route("/stats", func(r chi.Router) {
-						r.Use(trust.TrustGuard)
-						r.Get("/", handlers.GetStats(use))
-					})
+			// маршрут доступен и без доверенных подсетей: они могут быть заданы при перечитывании конфигурации
+			trust := newTrustNet(cfg)
+			r.Route("/internal", func(r chi.Router) {
+				r.Route("/stats", func(r chi.Router) {
+					r.Use(trust.TrustGuard)
+					r.Get("/", handlers.GetStats(use))
+				})
+				// метрики раскрывают внутреннее устройство сервиса и доступны только из доверенных подсетей
+				r.Route("/metrics", func(r chi.Router) {
+					r.Use(trust.TrustGuard)
+					r.Handle("/", metrics.Handler())
 				})
-			}
+			})
 		})
 		r.Route("/ping", func(r chi.Router) {
 			r.Get("/", handlers.PingStorage(use)) // GET /ping
@@ -62,3 +85,80 @@ func HandleRouter(cfg *config.Config, use *usecase.UsecaseHTTP) chi.Router {
 	}
 	return r
 }
+
+// HandleGateway - метод подключения REST шлюза (HTTP/JSON трансляция GRPC API) по префиксу /api/v2.
+// Пользователь определяется по cookie, ограничение частоты запросов и ключи идемпотентности POST запросов
+// обрабатываются так же, как и в HTTP API
+func HandleGateway(r chi.Router, cfg *config.Config, gateway http.Handler, limiter *ratelimit.Limiter, keeper *idempotency.Keeper) {
+	auth := middleware.NewAuthorization(cfg)
+	limit := middleware.NewRateLimit(limiter)
+	idem := middleware.NewIdempotency(keeper)
+	body := middleware.NewBodyLimit(cfg)
+	compress := middleware.NewCompression(cfg)
+	r.Route("/api/v2", func(r chi.Router) {
+		r.Use(middleware.LogHandle)
+		r.Use(limit.LimitFunc(gatewayGroup))
+		r.Use(body.LimitFunc(gatewayGroup))
+		r.Use(compress.Handle)
+		r.Use(auth.CookieHandle)
+		r.Use(idem.Handle)
+		r.Handle("/*", gateway)
+	})
+}
+
+// HandleAdmin - метод подключения административного API (доступно только из доверенных подсетей):
+// POST /api/internal/reload - перечитывание конфигурации без перезапуска приложения
+func HandleAdmin(r chi.Router, cfg *config.Config, reload func() (config.ReloadResult, error)) {
+	trust := newTrustNet(cfg)
+	r.Route("/api/internal/reload", func(r chi.Router) {
+		r.Use(middleware.LogHandle)
+		r.Use(trust.TrustGuard)
+		r.Post("/", handlers.ReloadConfig(reload))
+	})
+}
+
+// newTrustNet - метод формирования проверки доверенных подсетей, обновляемых при перечитывании конфигурации
+func newTrustNet(cfg *config.Config) *middleware.TrustNet {
+	trust := middleware.NewTrustNet(parseSubnets(cfg.TrustedSubnet))
+	cfg.OnReload(func(cfg *config.Config) { trust.SetSubnets(parseSubnets(cfg.TrustedSubnet)) })
+	return trust
+}
+
+// parseSubnets - метод разбора доверенных подсетей (при ошибке доступ запрещается)
+func parseSubnets(subnets string) []*net.IPNet {
+	result, err := helpers.ParseSubnets(subnets)
+	if err != nil {
+		logger.Warn("Invalid trusted subnet:", err)
+		return nil
+	}
+	return result
+}
+
+// apiGroup - метод определения группы ограничения размера тела запроса HTTP API по маршруту
+func apiGroup(r *http.Request) string {
+	switch strings.TrimSuffix(r.URL.Path, "/") {
+	case "/api/shorten":
+		return ratelimit.GroupShorten
+	case "/api/shorten/batch":
+		return ratelimit.GroupBatch
+	case "/api/user/urls", "/api/user/quota":
+		return ratelimit.GroupUser
+	default:
+		return ""
+	}
+}
+
+// gatewayGroup - метод определения группы ограничения частоты запросов REST шлюза по маршруту
+func gatewayGroup(r *http.Request) string {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case r.Method == http.MethodPost && path == "/api/v2/urls/batch":
+		return ratelimit.GroupBatch
+	case r.Method == http.MethodPost && strings.HasPrefix(path, "/api/v2/urls"):
+		return ratelimit.GroupShorten
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/api/v2/urls/"):
+		return ratelimit.GroupRedirect
+	default:
+		return ratelimit.GroupUser
+	}
+}
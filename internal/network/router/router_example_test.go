@@ -23,7 +23,7 @@ func Example_decodeURL() {
 	// Конфигурация
 	cfg := config.NewDefaultConfig()
 	// Инициализация роутера
-	r := HandleRouter(cfg, newTestUsecase(cfg))
+	r := HandleRouter(cfg, newTestUsecase(cfg), nil, nil)
 
 	req := httptest.NewRequest("GET", "/iFBc_bhG", nil)
 	w := httptest.NewRecorder()
@@ -39,7 +39,7 @@ func Example_encodeURL() {
 	// Конфигурация
 	cfg := config.NewDefaultConfig()
 	// Инициализация роутера
-	r := HandleRouter(cfg, newTestUsecase(cfg))
+	r := HandleRouter(cfg, newTestUsecase(cfg), nil, nil)
 
 	// Подготовка формы
 	form := url.Values{}
@@ -61,7 +61,7 @@ func Example_encondeURLJson() {
 	// Конфигурация
 	cfg := config.NewDefaultConfig()
 	// Инициализация роутера
-	r := HandleRouter(cfg, newTestUsecase(cfg))
+	r := HandleRouter(cfg, newTestUsecase(cfg), nil, nil)
 
 	// Подготовка запроса
 	jsonBody := []byte(`{"url":"https://google.com"}`)
@@ -87,7 +87,7 @@ func Example_encodeBatchURLs() {
 	// Конфигурация
 	cfg := config.NewDefaultConfig()
 	// Инициализация роутера
-	r := HandleRouter(cfg, newTestUsecase(cfg))
+	r := HandleRouter(cfg, newTestUsecase(cfg), nil, nil)
 
 	batchData := `[
         {"correlation_id": "1", "original_url": "https://test.com/batch1"},
@@ -109,7 +109,7 @@ func Example_getUserURLs() {
 	// Конфигурация
 	cfg := config.NewDefaultConfig()
 	// Инициализация роутера
-	r := HandleRouter(cfg, newTestUsecase(cfg))
+	r := HandleRouter(cfg, newTestUsecase(cfg), nil, nil)
 	req := httptest.NewRequest("GET", "/api/user/urls", nil)
 	if token, err := helpers.BuildJWT("mda", []byte("secret")); err == nil {
 		req.AddCookie(&http.Cookie{Name: "user-token", Value: token})
@@ -128,7 +128,7 @@ func Example_deleteUserURLs() {
 	// Конфигурация
 	cfg := config.NewDefaultConfig()
 	// Инициализация роутера
-	r := HandleRouter(cfg, newTestUsecase(cfg))
+	r := HandleRouter(cfg, newTestUsecase(cfg), nil, nil)
 	req := httptest.NewRequest("DELETE", "/api/user/urls", strings.NewReader(`["iFBc_bhG"]`))
 	req.Header.Set("Content-Type", "application/json")
 
@@ -148,7 +148,7 @@ func Example_deleteUserURLs() {
 func Example_ping() {
 	// Конфигурация
 	cfg := config.NewDefaultConfig()
-	r := HandleRouter(cfg, newTestUsecase(cfg))
+	r := HandleRouter(cfg, newTestUsecase(cfg), nil, nil)
 
 	req := httptest.NewRequest("GET", "/ping", nil)
 	w := httptest.NewRecorder()
@@ -1,19 +1,25 @@
 package router
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/denmor86/go-url-shortener/internal/config"
+	"github.com/denmor86/go-url-shortener/internal/idempotency"
 	"github.com/denmor86/go-url-shortener/internal/logger"
+	"github.com/denmor86/go-url-shortener/internal/ratelimit"
 	"github.com/denmor86/go-url-shortener/internal/storage"
 	"github.com/denmor86/go-url-shortener/internal/usecase"
 	"github.com/denmor86/go-url-shortener/internal/workerpool"
@@ -43,7 +49,7 @@ func TestHandleRouter(t *testing.T) {
 	usecase := usecase.NewUsecaseHTTP(cfg, store, worker)
 	worker.Run()
 
-	ts := httptest.NewServer(HandleRouter(cfg, usecase))
+	ts := httptest.NewServer(HandleRouter(cfg, usecase, nil, nil))
 	defer ts.Close()
 
 	var testTable = []struct {
@@ -80,3 +86,245 @@ func TestHandleRouter(t *testing.T) {
 		defer resp.Body.Close()
 	}
 }
+
+func TestHandleRouter_RateLimit(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	if err := logger.Initialize(cfg.LogLevel); err != nil {
+		logger.Panic(err)
+	}
+	defer logger.Sync()
+	store := storage.NewStorage(cfg)
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), map[string]ratelimit.Policy{
+		ratelimit.GroupShorten: {Rate: 0.01, Burst: 1},
+	})
+
+	ts := httptest.NewServer(HandleRouter(cfg, usecase.NewUsecaseHTTP(cfg, store, nil), limiter, nil))
+	defer ts.Close()
+
+	var testTable = []struct {
+		url    string
+		metod  string
+		body   io.Reader
+		status int
+	}{
+		{"/", "POST", strings.NewReader("https://practicum.yandex.ru/"), http.StatusCreated},
+		{"/api/shorten", "POST", strings.NewReader("{\"url\": \"https://google.com\"}"), http.StatusTooManyRequests},
+		{"/ping", "GET", nil, http.StatusOK},
+	}
+	for _, v := range testTable {
+		resp := testRequest(t, ts, v.metod, v.url, v.body)
+		assert.Equal(t, v.status, resp.StatusCode)
+		if v.status == http.StatusTooManyRequests {
+			assert.Equal(t, "100", resp.Header.Get("Retry-After"))
+		}
+	}
+}
+
+func TestHandleRouter_Idempotency(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	if err := logger.Initialize(cfg.LogLevel); err != nil {
+		logger.Panic(err)
+	}
+	defer logger.Sync()
+	store := storage.NewMemStorage()
+	keeper := idempotency.NewKeeper(idempotency.NewMemoryStore(), time.Hour)
+
+	ts := httptest.NewServer(HandleRouter(cfg, usecase.NewUsecaseHTTP(cfg, store, nil), nil, keeper))
+	defer ts.Close()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := &http.Client{Jar: jar}
+
+	post := func(path, key, body string) (*http.Response, string) {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set(idempotency.HeaderName, key)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(data)
+	}
+
+	batch := `[{"correlation_id":"1","original_url":"https://ya.ru"},{"correlation_id":"2","original_url":"https://google.com"}]`
+	first, firstBody := post("/api/shorten/batch", "batch-1", batch)
+	require.Equal(t, http.StatusCreated, first.StatusCode)
+
+	// повтор возвращает тот же ответ без повторного создания ссылок
+	replayed, replayedBody := post("/api/shorten/batch", "batch-1", batch)
+	assert.Equal(t, http.StatusCreated, replayed.StatusCode)
+	assert.Equal(t, firstBody, replayedBody)
+	assert.Equal(t, "true", replayed.Header.Get(idempotency.ReplayedHeader))
+	assert.Equal(t, first.Header.Get("Content-Type"), replayed.Header.Get("Content-Type"))
+	assert.Equal(t, 2, store.GetStat(context.Background()).URLs)
+
+	// ключ с другим телом запроса отклоняется
+	mismatch, _ := post("/api/shorten/batch", "batch-1", `[{"correlation_id":"1","original_url":"https://ya.ru"}]`)
+	assert.Equal(t, http.StatusUnprocessableEntity, mismatch.StatusCode)
+
+	// ключ с другим путем отклоняется
+	mismatch, _ = post("/api/shorten", "batch-1", `{"url":"https://ya.ru"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, mismatch.StatusCode)
+
+	// повтор запроса без cookie возвращает тот же ответ, хотя каждому запросу выдается новый пользователь
+	client = &http.Client{}
+	first, firstBody = post("/api/shorten", "anonymous-1", `{"url":"https://example.com"}`)
+	require.Equal(t, http.StatusCreated, first.StatusCode)
+	replayed, replayedBody = post("/api/shorten", "anonymous-1", `{"url":"https://example.com"}`)
+	assert.Equal(t, http.StatusCreated, replayed.StatusCode)
+	assert.Equal(t, firstBody, replayedBody)
+	assert.Equal(t, "true", replayed.Header.Get(idempotency.ReplayedHeader))
+	assert.Equal(t, 3, store.GetStat(context.Background()).URLs)
+
+	// ключ запроса без cookie не совпадает с ключом пользователя с cookie
+	client = &http.Client{Jar: jar}
+	own, _ := post("/api/shorten", "anonymous-1", `{"url":"https://example.org"}`)
+	assert.Equal(t, http.StatusCreated, own.StatusCode)
+	assert.Empty(t, own.Header.Get(idempotency.ReplayedHeader))
+}
+
+func TestHandleRouter_BodyLimit(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.BodyLimits = "shorten=64,batch=4096"
+	cfg.MaxBatchSize = 2
+	if err := logger.Initialize(cfg.LogLevel); err != nil {
+		logger.Panic(err)
+	}
+	defer logger.Sync()
+
+	ts := httptest.NewServer(HandleRouter(cfg, usecase.NewUsecaseHTTP(cfg, storage.NewMemStorage(), nil), nil, nil))
+	defer ts.Close()
+
+	// gzip бомба: небольшое сжатое тело, распаковывающееся в тело больше ограничения
+	var bomb bytes.Buffer
+	zw := gzip.NewWriter(&bomb)
+	_, err := zw.Write([]byte(`["` + strings.Repeat("a", 1<<20) + `"]`))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	require.Less(t, bomb.Len(), 4096)
+
+	item := `{"correlation_id":"1","original_url":"https://ya.ru"}`
+	testCases := []struct {
+		name   string
+		path   string
+		body   io.Reader
+		gzip   bool
+		status int
+	}{
+		{"Short URL #1 (good)", "/", strings.NewReader("https://ya.ru"), false, http.StatusCreated},
+		{"Large body #2 (bad)", "/", strings.NewReader("https://ya.ru/" + strings.Repeat("a", 64)), false, http.StatusRequestEntityTooLarge},
+		{"Large body without length #3 (bad)", "/api/shorten", io.MultiReader(strings.NewReader(`{"url":"https://ya.ru/`), strings.NewReader(strings.Repeat("a", 64)+`"}`)), false, http.StatusRequestEntityTooLarge},
+		{"Gzip bomb #4 (bad)", "/api/shorten/batch", bytes.NewReader(bomb.Bytes()), true, http.StatusRequestEntityTooLarge},
+		{"Batch #5 (good)", "/api/shorten/batch", strings.NewReader("[" + item + "]"), false, http.StatusCreated},
+		{"Too many batch items #6 (bad)", "/api/shorten/batch", strings.NewReader("[" + strings.Repeat(item+",", 2) + item + "]"), false, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, ts.URL+tc.path, tc.body)
+			require.NoError(t, err)
+			if tc.gzip {
+				req.Header.Set("Content-Encoding", "gzip")
+			}
+			resp, err := ts.Client().Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tc.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestChallengeRouter(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.BaseURL = "https://short.example:8443/s"
+	if err := logger.Initialize(cfg.LogLevel); err != nil {
+		logger.Panic(err)
+	}
+	defer logger.Sync()
+
+	challenge := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, strings.TrimPrefix(r.URL.Path, ACMEChallengePath))
+	})
+	ts := httptest.NewServer(ChallengeRouter(cfg, challenge))
+	defer ts.Close()
+	client := ts.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		status   int
+		expected string
+	}{
+		{"Challenge #1 (good)", http.MethodGet, ACMEChallengePath + "token", http.StatusOK, "token"},
+		{"Redirect #2 (good)", http.MethodGet, "/abc?x=1", http.StatusMovedPermanently, "https://short.example:8443/s/abc?x=1"},
+		{"Post #3 (bad)", http.MethodPost, "/", http.StatusBadRequest, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, ts.URL+tc.path, nil)
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tc.status, resp.StatusCode)
+			switch tc.status {
+			case http.StatusOK:
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, string(body))
+			case http.StatusMovedPermanently:
+				assert.Equal(t, tc.expected, resp.Header.Get("Location"))
+			}
+		})
+	}
+}
+
+func TestHandleAdmin(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.TrustedSubnet = "127.0.0.0/8"
+	if err := logger.Initialize(cfg.LogLevel); err != nil {
+		logger.Panic(err)
+	}
+	defer logger.Sync()
+
+	// перечитанная конфигурация закрывает доступ к статистике и административному API
+	next := config.NewDefaultConfig()
+	next.TrustedSubnet = "10.0.0.0/8"
+	next.ListenAddr = ":9090"
+	reload := func() (config.ReloadResult, error) { return cfg.Apply(next), nil }
+
+	r := HandleRouter(cfg, usecase.NewUsecaseHTTP(cfg, storage.NewMemStorage(), nil), nil, nil)
+	HandleAdmin(r, cfg, reload)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	resp := testRequest(t, ts, http.MethodGet, "/api/internal/stats", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = testRequest(t, ts, http.MethodGet, "/api/internal/metrics", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	// короткая ссылка metrics не перекрывается выгрузкой метрик
+	resp = testRequest(t, ts, http.MethodGet, "/metrics", nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/api/internal/reload", nil)
+	require.NoError(t, err)
+	resp, err = ts.Client().Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"applied":["trusted_subnet"],"restart_required":["server_address"]}`, string(body))
+
+	resp = testRequest(t, ts, http.MethodGet, "/api/internal/stats", nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp = testRequest(t, ts, http.MethodGet, "/api/internal/metrics", nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp = testRequest(t, ts, http.MethodPost, "/api/internal/reload", nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
@@ -0,0 +1,196 @@
+package profiling
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+)
+
+// Виды профилей и значения, по которым они сравниваются
+const (
+	// KindCPU - процессорное время (/debug/pprof/profile)
+	KindCPU = "cpu"
+	// KindHeap - занятая память на момент снятия профиля (/debug/pprof/heap)
+	KindHeap = "heap"
+	// KindAllocs - выделенная за время нагрузки память (/debug/pprof/allocs)
+	KindAllocs = "allocs"
+)
+
+// SampleTypes - виды значений, по которым сравниваются профили каждого вида
+var SampleTypes = map[string]string{
+	KindCPU:    "cpu",
+	KindHeap:   "inuse_space",
+	KindAllocs: "alloc_space",
+}
+
+// Thresholds - допустимое ухудшение профиля относительно предыдущего запуска (0 - не проверяется)
+type Thresholds struct {
+	MaxTotalIncrease    float64 // рост общих затрат на запрос (или общих затрат, если количество запросов неизвестно), %
+	MaxFunctionIncrease float64 // рост доли собственных затрат функции, процентных пунктов
+}
+
+// FunctionDelta - изменение собственных затрат функции
+type FunctionDelta struct {
+	Name    string  // имя функции
+	Base    float64 // доля затрат в предыдущем профиле, %
+	Current float64 // доля затрат в текущем профиле, %
+	Delta   float64 // изменение доли, процентных пунктов
+}
+
+// Comparison - результат сравнения профиля с профилем предыдущего запуска
+type Comparison struct {
+	Kind         string          // вид профиля
+	SampleType   string          // вид сравниваемых значений
+	Unit         string          // единица измерения значений
+	BaseTotal    float64         // общие затраты предыдущего профиля (на запрос, если PerRequest)
+	CurrentTotal float64         // общие затраты текущего профиля (на запрос, если PerRequest)
+	PerRequest   bool            // признак приведения общих затрат к одному запросу
+	TotalChange  float64         // изменение общих затрат, %
+	Functions    []FunctionDelta // изменения затрат функций, по убыванию доли в текущем профиле
+	top          int             // количество выводимых функций
+}
+
+// Compare - метод сравнения профиля current с профилем предыдущего запуска base по виду профиля kind.
+// Затраты функций сравниваются долями от общих затрат, общие затраты - в расчете на запрос,
+// если известно количество запросов обоих запусков (baseRequests, currentRequests).
+// В результат включаются top функций с наибольшей долей в каждом из профилей
+func Compare(kind string, base, current *Profile, baseRequests, currentRequests int64, top int) (*Comparison, error) {
+	sampleType := SampleTypes[kind]
+	baseFlat, baseTotal, err := base.Flat(sampleType)
+	if err != nil {
+		return nil, fmt.Errorf("baseline %s profile: %w", kind, err)
+	}
+	currentFlat, currentTotal, err := current.Flat(sampleType)
+	if err != nil {
+		return nil, fmt.Errorf("%s profile: %w", kind, err)
+	}
+
+	c := &Comparison{
+		Kind:         kind,
+		SampleType:   sampleType,
+		Unit:         current.Unit(sampleType),
+		BaseTotal:    float64(baseTotal),
+		CurrentTotal: float64(currentTotal),
+		top:          top,
+	}
+	if baseRequests > 0 && currentRequests > 0 {
+		c.PerRequest = true
+		c.BaseTotal /= float64(baseRequests)
+		c.CurrentTotal /= float64(currentRequests)
+	}
+	if c.BaseTotal != 0 {
+		c.TotalChange = (c.CurrentTotal - c.BaseTotal) / c.BaseTotal * 100
+	}
+
+	names := map[string]struct{}{}
+	for name := range baseFlat {
+		names[name] = struct{}{}
+	}
+	for name := range currentFlat {
+		names[name] = struct{}{}
+	}
+	for name := range names {
+		c.Functions = append(c.Functions, FunctionDelta{
+			Name:    name,
+			Base:    share(baseFlat[name], baseTotal),
+			Current: share(currentFlat[name], currentTotal),
+		})
+	}
+	for i := range c.Functions {
+		c.Functions[i].Delta = c.Functions[i].Current - c.Functions[i].Base
+	}
+	sort.Slice(c.Functions, func(i, j int) bool {
+		if c.Functions[i].Current != c.Functions[j].Current {
+			return c.Functions[i].Current > c.Functions[j].Current
+		}
+		return c.Functions[i].Name < c.Functions[j].Name
+	})
+	return c, nil
+}
+
+// share - метод расчета доли значения value от общей суммы total, %
+func share(value, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(value) / float64(total) * 100
+}
+
+// Check - метод проверки допустимого ухудшения, возвращает описания превышений
+func (c *Comparison) Check(t Thresholds) []string {
+	var violations []string
+	if t.MaxTotalIncrease > 0 && c.TotalChange > t.MaxTotalIncrease {
+		violations = append(violations, fmt.Sprintf("%s: total %s increased by %.1f%% (max %.1f%%)",
+			c.Kind, c.SampleType, c.TotalChange, t.MaxTotalIncrease))
+	}
+	if t.MaxFunctionIncrease > 0 {
+		for _, f := range c.Functions {
+			if f.Delta > t.MaxFunctionIncrease {
+				violations = append(violations, fmt.Sprintf("%s: %s share increased by %.1f points (%.1f%% -> %.1f%%, max %.1f)",
+					c.Kind, f.Name, f.Delta, f.Base, f.Current, t.MaxFunctionIncrease))
+			}
+		}
+	}
+	return violations
+}
+
+// Write - метод вывода сравнения: общие затраты и функции с наибольшей долей затрат в каждом из профилей
+func (c *Comparison) Write(w io.Writer) error {
+	per := ""
+	if c.PerRequest {
+		per = " per request"
+	}
+	fmt.Fprintf(w, "%s (%s%s): base %s, current %s, change %+.1f%%\n", c.Kind, c.SampleType, per,
+		formatValue(c.BaseTotal, c.Unit), formatValue(c.CurrentTotal, c.Unit), c.TotalChange)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  BASE%\tCURRENT%\tDELTA\tFUNCTION")
+	for _, f := range c.topFunctions() {
+		fmt.Fprintf(tw, "  %6.2f\t%8.2f\t%+6.2f\t%s\n", f.Base, f.Current, f.Delta, f.Name)
+	}
+	return tw.Flush()
+}
+
+// topFunctions - метод выбора функций с наибольшей долей затрат в текущем или предыдущем профиле
+func (c *Comparison) topFunctions() []FunctionDelta {
+	if c.top <= 0 || len(c.Functions) <= c.top {
+		return c.Functions
+	}
+	byBase := make([]FunctionDelta, len(c.Functions))
+	copy(byBase, c.Functions)
+	sort.SliceStable(byBase, func(i, j int) bool { return byBase[i].Base > byBase[j].Base })
+	selected := map[string]bool{}
+	for i := 0; i < c.top; i++ {
+		selected[c.Functions[i].Name] = true
+		selected[byBase[i].Name] = true
+	}
+	result := make([]FunctionDelta, 0, len(selected))
+	for _, f := range c.Functions {
+		if selected[f.Name] {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// formatValue - метод форматирования значения в единицах unit (nanoseconds, bytes)
+func formatValue(value float64, unit string) string {
+	var units []string
+	var step float64
+	switch unit {
+	case "nanoseconds":
+		units, step = []string{"ns", "µs", "ms", "s"}, 1000
+	case "bytes":
+		units, step = []string{"B", "kB", "MB", "GB"}, 1024
+	default:
+		return fmt.Sprintf("%.0f %s", value, unit)
+	}
+	i := 0
+	for i < len(units)-1 && math.Abs(value) >= step {
+		value /= step
+		i++
+	}
+	return fmt.Sprintf("%.2f%s", value, units[i])
+}
@@ -0,0 +1,93 @@
+package profiling
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newProfile - метод формирования профиля CPU с собственными затратами функций
+func newProfile(flat map[string]int64) *Profile {
+	profile := &Profile{SampleTypes: []ValueType{{Type: "samples", Unit: "count"}, {Type: "cpu", Unit: "nanoseconds"}}}
+	for name, value := range flat {
+		profile.samples = append(profile.samples, sample{stack: []string{name, "main.main"}, values: []int64{1, value}})
+	}
+	return profile
+}
+
+func TestCompare(t *testing.T) {
+	base := newProfile(map[string]int64{"a": 600, "b": 400})
+	current := newProfile(map[string]int64{"a": 500, "b": 400, "c": 300})
+
+	comparison, err := Compare(KindCPU, base, current, 10, 10, 2)
+	require.NoError(t, err)
+	assert.True(t, comparison.PerRequest)
+	assert.InDelta(t, 100, comparison.BaseTotal, 0.001)
+	assert.InDelta(t, 120, comparison.CurrentTotal, 0.001)
+	assert.InDelta(t, 20, comparison.TotalChange, 0.001)
+	require.Len(t, comparison.Functions, 3)
+	assert.Equal(t, "a", comparison.Functions[0].Name)
+	assert.InDelta(t, 60, comparison.Functions[0].Base, 0.001)
+	assert.InDelta(t, 41.667, comparison.Functions[0].Current, 0.001)
+	assert.Equal(t, "c", comparison.Functions[2].Name)
+	assert.InDelta(t, 25, comparison.Functions[2].Delta, 0.001)
+
+	var buf bytes.Buffer
+	require.NoError(t, comparison.Write(&buf))
+	assert.Contains(t, buf.String(), "cpu (cpu per request): base 100.00ns, current 120.00ns, change +20.0%")
+	// выводятся функции из top каждого профиля
+	assert.NotContains(t, buf.String(), " c\n")
+
+	// без количества запросов сравниваются общие затраты
+	comparison, err = Compare(KindCPU, base, current, 0, 10, 2)
+	require.NoError(t, err)
+	assert.False(t, comparison.PerRequest)
+	assert.InDelta(t, 1000, comparison.BaseTotal, 0.001)
+
+	_, err = Compare(KindHeap, base, current, 10, 10, 2)
+	require.Error(t, err)
+}
+
+func TestComparison_Check(t *testing.T) {
+	comparison, err := Compare(KindCPU,
+		newProfile(map[string]int64{"a": 600, "b": 400}),
+		newProfile(map[string]int64{"a": 500, "b": 400, "c": 300}), 10, 10, 0)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		thresholds Thresholds
+		violations int
+	}{
+		{"Thresholds disabled #1 (good)", Thresholds{}, 0},
+		{"Within thresholds #2 (good)", Thresholds{MaxTotalIncrease: 25, MaxFunctionIncrease: 30}, 0},
+		{"Total increase exceeded #3 (bad)", Thresholds{MaxTotalIncrease: 10}, 1},
+		{"Function increase exceeded #4 (bad)", Thresholds{MaxFunctionIncrease: 5}, 1},
+		{"Both exceeded #5 (bad)", Thresholds{MaxTotalIncrease: 10, MaxFunctionIncrease: 5}, 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Len(t, comparison.Check(tc.thresholds), tc.violations)
+		})
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value float64
+		unit  string
+		want  string
+	}{
+		{"Nanoseconds #1 (good)", 1500000, "nanoseconds", "1.50ms"},
+		{"Bytes #2 (good)", 2048, "bytes", "2.00kB"},
+		{"Unknown unit #3 (good)", 42, "count", "42 count"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, formatValue(tc.value, tc.unit))
+		})
+	}
+}
@@ -0,0 +1,312 @@
+// Package profiling предоставляет чтение профилей pprof и сравнение затрат функций с профилем предыдущего запуска.
+// Профиль разбирается напрямую из формата protobuf (perftools.profiles.Profile), сжатого gzip или без сжатия
+package profiling
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Поля сообщений профиля (profile.proto)
+const (
+	profileSampleType        = 1
+	profileSample            = 2
+	profileLocation          = 4
+	profileFunction          = 5
+	profileStringTable       = 6
+	profileDefaultSampleType = 14
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+
+	functionID   = 1
+	functionName = 2
+)
+
+// ValueType - вид значений профиля
+type ValueType struct {
+	Type string // вид значения, например cpu, alloc_space, inuse_space
+	Unit string // единица измерения, например nanoseconds, bytes
+}
+
+// Profile - профиль pprof: виды значений и стеки вызовов функций со значениями
+type Profile struct {
+	SampleTypes       []ValueType // виды значений измерений
+	DefaultSampleType string      // вид значения по-умолчанию
+	samples           []sample    // измерения
+}
+
+// sample - измерение: стек функций (первой идет функция, в которой выполнялся код) и значения по видам
+type sample struct {
+	stack  []string
+	values []int64
+}
+
+// rawValueType - вид значений с индексами в таблице строк
+type rawValueType struct {
+	typ, unit int64
+}
+
+// rawSample - измерение с идентификаторами адресов
+type rawSample struct {
+	locations []uint64
+	values    []int64
+}
+
+// ParseFile - метод чтения профиля из файла
+func ParseFile(path string) (*Profile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read profile: %w", err)
+	}
+	defer file.Close()
+	profile, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse profile %s: %w", path, err)
+	}
+	return profile, nil
+}
+
+// Parse - метод чтения профиля в формате protobuf, сжатого gzip или без сжатия
+func Parse(r io.Reader) (*Profile, error) {
+	reader := bufio.NewReader(r)
+	if magic, err := reader.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = reader
+	}
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return decode(buf)
+}
+
+// decode - метод разбора сообщения профиля. Строки и функции разрешаются после чтения всего сообщения,
+// так как таблица строк может следовать за ссылающимися на нее полями
+func decode(buf []byte) (*Profile, error) {
+	var (
+		sampleTypes       []rawValueType
+		samples           []rawSample
+		strings           []string
+		defaultSampleType int64
+		locations         = map[uint64][]uint64{} // функции адреса, первой идет встроенная (inline) функция
+		functions         = map[uint64]int64{}    // индекс имени функции в таблице строк
+	)
+	err := walk(buf, func(num protowire.Number, typ protowire.Type, value []byte, v uint64) error {
+		switch num {
+		case profileSampleType:
+			var vt rawValueType
+			err := walk(value, func(num protowire.Number, _ protowire.Type, _ []byte, v uint64) error {
+				switch num {
+				case valueTypeType:
+					vt.typ = int64(v)
+				case valueTypeUnit:
+					vt.unit = int64(v)
+				}
+				return nil
+			})
+			sampleTypes = append(sampleTypes, vt)
+			return err
+		case profileSample:
+			var s rawSample
+			err := walk(value, func(num protowire.Number, typ protowire.Type, value []byte, v uint64) error {
+				switch num {
+				case sampleLocationID:
+					return repeated(typ, value, v, func(v uint64) { s.locations = append(s.locations, v) })
+				case sampleValue:
+					return repeated(typ, value, v, func(v uint64) { s.values = append(s.values, int64(v)) })
+				}
+				return nil
+			})
+			samples = append(samples, s)
+			return err
+		case profileLocation:
+			var id uint64
+			var funcs []uint64
+			err := walk(value, func(num protowire.Number, _ protowire.Type, value []byte, v uint64) error {
+				switch num {
+				case locationID:
+					id = v
+				case locationLine:
+					return walk(value, func(num protowire.Number, _ protowire.Type, _ []byte, v uint64) error {
+						if num == lineFunctionID {
+							funcs = append(funcs, v)
+						}
+						return nil
+					})
+				}
+				return nil
+			})
+			locations[id] = funcs
+			return err
+		case profileFunction:
+			var id uint64
+			var name int64
+			err := walk(value, func(num protowire.Number, _ protowire.Type, _ []byte, v uint64) error {
+				switch num {
+				case functionID:
+					id = v
+				case functionName:
+					name = int64(v)
+				}
+				return nil
+			})
+			functions[id] = name
+			return err
+		case profileStringTable:
+			strings = append(strings, string(value))
+		case profileDefaultSampleType:
+			defaultSampleType = int64(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	str := func(i int64) string {
+		if i < 0 || i >= int64(len(strings)) {
+			return ""
+		}
+		return strings[i]
+	}
+	profile := &Profile{DefaultSampleType: str(defaultSampleType)}
+	for _, vt := range sampleTypes {
+		profile.SampleTypes = append(profile.SampleTypes, ValueType{Type: str(vt.typ), Unit: str(vt.unit)})
+	}
+	for _, s := range samples {
+		if len(s.values) != len(sampleTypes) {
+			return nil, fmt.Errorf("sample has %d values, expected %d", len(s.values), len(sampleTypes))
+		}
+		var stack []string
+		for _, location := range s.locations {
+			for _, function := range locations[location] {
+				stack = append(stack, str(functions[function]))
+			}
+		}
+		profile.samples = append(profile.samples, sample{stack: stack, values: s.values})
+	}
+	return profile, nil
+}
+
+// walk - метод перебора полей сообщения protobuf. Для полей varint передается значение v,
+// для полей с длиной - содержимое value
+func walk(buf []byte, field func(num protowire.Number, typ protowire.Type, value []byte, v uint64) error) error {
+	for len(buf) > 0 {
+		num, typ, n := protowire.ConsumeTag(buf)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		buf = buf[n:]
+		var value []byte
+		var v uint64
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(buf)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(buf)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, buf)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		buf = buf[n:]
+		if err := field(num, typ, value, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// repeated - метод чтения повторяющегося поля varint в упакованной (packed) или обычной форме
+func repeated(typ protowire.Type, value []byte, v uint64, add func(uint64)) error {
+	if typ == protowire.VarintType {
+		add(v)
+		return nil
+	}
+	for len(value) > 0 {
+		v, n := protowire.ConsumeVarint(value)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		add(v)
+		value = value[n:]
+	}
+	return nil
+}
+
+// valueIndex - метод получения индекса значений вида sampleType (пустое значение - вид по-умолчанию,
+// а если он не задан - последний вид, как в pprof)
+func (p *Profile) valueIndex(sampleType string) (int, error) {
+	if len(p.SampleTypes) == 0 {
+		return 0, fmt.Errorf("profile has no sample types")
+	}
+	if len(sampleType) == 0 {
+		sampleType = p.DefaultSampleType
+	}
+	if len(sampleType) == 0 {
+		return len(p.SampleTypes) - 1, nil
+	}
+	for i, vt := range p.SampleTypes {
+		if vt.Type == sampleType {
+			return i, nil
+		}
+	}
+	var names bytes.Buffer
+	for i, vt := range p.SampleTypes {
+		if i > 0 {
+			names.WriteString(", ")
+		}
+		names.WriteString(vt.Type)
+	}
+	return 0, fmt.Errorf("profile has no sample type %q (%s)", sampleType, names.String())
+}
+
+// Flat - метод получения собственных затрат функций (значения измерений, в которых функция выполнялась)
+// по виду значений sampleType и общей суммы значений
+func (p *Profile) Flat(sampleType string) (map[string]int64, int64, error) {
+	i, err := p.valueIndex(sampleType)
+	if err != nil {
+		return nil, 0, err
+	}
+	flat := map[string]int64{}
+	var total int64
+	for _, s := range p.samples {
+		value := s.values[i]
+		total += value
+		if len(s.stack) > 0 && value != 0 {
+			flat[s.stack[0]] += value
+		}
+	}
+	return flat, total, nil
+}
+
+// Unit - метод получения единицы измерения вида значений sampleType
+func (p *Profile) Unit(sampleType string) string {
+	i, err := p.valueIndex(sampleType)
+	if err != nil {
+		return ""
+	}
+	return p.SampleTypes[i].Unit
+}
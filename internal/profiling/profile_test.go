@@ -0,0 +1,50 @@
+package profiling
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFile(t *testing.T) {
+	profile, err := ParseFile("../../profiles/base.pprof")
+	require.NoError(t, err)
+	assert.Equal(t, []ValueType{
+		{Type: "alloc_objects", Unit: "count"},
+		{Type: "alloc_space", Unit: "bytes"},
+		{Type: "inuse_objects", Unit: "count"},
+		{Type: "inuse_space", Unit: "bytes"},
+	}, profile.SampleTypes)
+
+	flat, total, err := profile.Flat(SampleTypes[KindHeap])
+	require.NoError(t, err)
+	assert.Equal(t, int64(2626562), flat["runtime.allocm"])
+	var sum int64
+	for _, value := range flat {
+		sum += value
+	}
+	assert.Equal(t, total, sum)
+	assert.Equal(t, "bytes", profile.Unit(SampleTypes[KindHeap]))
+
+	_, err = ParseFile("../../profiles/missing.pprof")
+	require.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, pprof.Lookup("allocs").WriteTo(&buf, 0))
+	profile, err := Parse(&buf)
+	require.NoError(t, err)
+	_, total, err := profile.Flat(SampleTypes[KindAllocs])
+	require.NoError(t, err)
+	assert.Positive(t, total)
+
+	_, _, err = profile.Flat("cpu")
+	require.Error(t, err)
+
+	_, err = Parse(bytes.NewReader([]byte{0x0a, 0xff}))
+	require.Error(t, err)
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Внутренние константы хранилища в памяти
+const (
+	// sweepInterval - количество запросов между очистками заполненных корзин
+	sweepInterval = 1024
+)
+
+// bucket - корзина токенов клиента
+type bucket struct {
+	tokens  float64   // количество токенов
+	updated time.Time // время последнего пополнения
+	policy  Policy    // политика корзины
+}
+
+// MemoryStore - хранилище состояния ограничителей в памяти процесса
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	takes   int              // количество запросов с последней очистки
+	now     func() time.Time // источник времени
+}
+
+// NewMemoryStore - метод создания хранилища состояния ограничителей в памяти процесса
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Take - метод получения одного токена из корзины с ключом key
+func (s *MemoryStore) Take(ctx context.Context, key string, policy Policy) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.takes++
+	if s.takes >= sweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(policy.Burst), updated: now, policy: policy}
+		s.buckets[key] = b
+	}
+	b.tokens = refill(b, now, policy)
+	b.updated = now
+	b.policy = policy
+
+	if b.tokens < 1 {
+		return Result{Allowed: false, RetryAfter: retryAfter(b.tokens, policy)}, nil
+	}
+	b.tokens--
+	return Result{Allowed: true, Remaining: int(b.tokens)}, nil
+}
+
+// sweep - метод удаления корзин, которые уже полностью пополнились (клиент неактивен)
+func (s *MemoryStore) sweep(now time.Time) {
+	s.takes = 0
+	for key, b := range s.buckets {
+		if refill(b, now, b.policy) >= float64(b.policy.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// refill - метод расчёта количества токенов с учётом пополнения
+func refill(b *bucket, now time.Time, policy Policy) float64 {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(policy.Burst), b.tokens+elapsed*policy.Rate)
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Используемые SQL запросы
+const (
+	// TakeToken - SQL запрос атомарного пополнения корзины и получения токена.
+	// Таблица rate_limits создается миграциями хранилища (internal/storage/migrations)
+	TakeToken = `INSERT INTO rate_limits AS r (key, tokens, allowed, updated_at)
+					VALUES ($1, $3::double precision - 1, TRUE, now())
+					ON CONFLICT (key) DO UPDATE SET
+						tokens = CASE
+							WHEN LEAST($3::double precision, r.tokens + EXTRACT(EPOCH FROM now() - r.updated_at)::double precision * $2::double precision) >= 1
+							THEN LEAST($3::double precision, r.tokens + EXTRACT(EPOCH FROM now() - r.updated_at)::double precision * $2::double precision) - 1
+							ELSE LEAST($3::double precision, r.tokens + EXTRACT(EPOCH FROM now() - r.updated_at)::double precision * $2::double precision)
+						END,
+						allowed = LEAST($3::double precision, r.tokens + EXTRACT(EPOCH FROM now() - r.updated_at)::double precision * $2::double precision) >= 1,
+						updated_at = now()
+					RETURNING tokens, allowed;`
+	// DeleteIdle - SQL запрос удаления неактивных корзин
+	DeleteIdle = `DELETE FROM rate_limits WHERE updated_at < $1;`
+)
+
+// PostgresStore - хранилище состояния ограничителей в PostgreSQL (общее для нескольких экземпляров сервиса)
+type PostgresStore struct {
+	Pool *pgxpool.Pool // пул подключений
+}
+
+// NewPostgresStore - метод создания хранилища состояния ограничителей в PostgreSQL
+func NewPostgresStore(ctx context.Context, dsn string) (*PostgresStore, error) {
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create connection pool: %w", err)
+	}
+	return &PostgresStore{Pool: pool}, nil
+}
+
+// Take - метод получения одного токена из корзины с ключом key
+func (s *PostgresStore) Take(ctx context.Context, key string, policy Policy) (Result, error) {
+	var tokens float64
+	var allowed bool
+	if err := s.Pool.QueryRow(ctx, TakeToken, key, policy.Rate, float64(policy.Burst)).Scan(&tokens, &allowed); err != nil {
+		return Result{}, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+	if !allowed {
+		return Result{Allowed: false, RetryAfter: retryAfter(tokens, policy)}, nil
+	}
+	return Result{Allowed: true, Remaining: int(tokens)}, nil
+}
+
+// DeleteIdle - метод удаления корзин, не использовавшихся дольше idle
+func (s *PostgresStore) DeleteIdle(ctx context.Context, idle time.Duration) error {
+	if _, err := s.Pool.Exec(ctx, DeleteIdle, time.Now().Add(-idle)); err != nil {
+		return fmt.Errorf("failed to delete idle rate limits: %w", err)
+	}
+	return nil
+}
+
+// Close - метод закрытия пула подключений
+func (s *PostgresStore) Close() {
+	s.Pool.Close()
+}
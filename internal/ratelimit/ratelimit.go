@@ -0,0 +1,164 @@
+// Package ratelimit предоставляет ограничение частоты запросов по алгоритму token bucket.
+// Состояние ограничителей хранится в памяти процесса либо в PostgreSQL (для нескольких экземпляров сервиса)
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Группы запросов с отдельными политиками ограничения
+const (
+	// GroupShorten - сокращение одиночных ссылок
+	GroupShorten = "shorten"
+	// GroupBatch - пакетное сокращение ссылок
+	GroupBatch = "batch"
+	// GroupRedirect - переход по короткой ссылке
+	GroupRedirect = "redirect"
+	// GroupUser - работа со ссылками пользователя
+	GroupUser = "user"
+)
+
+// Хранилища состояния ограничителей
+const (
+	// StoreMemory - хранение в памяти процесса
+	StoreMemory = "memory"
+	// StorePostgres - хранение в PostgreSQL
+	StorePostgres = "postgres"
+)
+
+// Policy - политика ограничения: пополнение Rate токенов в секунду, не более Burst токенов
+type Policy struct {
+	Rate  float64 // скорость пополнения, токенов в секунду
+	Burst int     // емкость (допустимый всплеск запросов)
+}
+
+// Result - результат запроса токена
+type Result struct {
+	Allowed    bool          // признак разрешения запроса
+	Remaining  int           // количество оставшихся токенов
+	RetryAfter time.Duration // время до появления токена (если запрос отклонен)
+}
+
+// Store - интерфейс хранилища состояния ограничителей
+type Store interface {
+	// Take - метод получения одного токена из корзины с ключом key
+	Take(ctx context.Context, key string, policy Policy) (Result, error)
+}
+
+// Limiter - ограничитель частоты запросов с политиками по группам запросов
+type Limiter struct {
+	store    Store                               // хранилище состояния
+	policies atomic.Pointer[map[string]Policy]   // политики по группам
+	apiKeys  atomic.Pointer[map[string]struct{}] // хэши зарегистрированных ключей API
+}
+
+// NewLimiter - метод создания ограничителя. Ключи API клиентов задаются через SetAPIKeys
+func NewLimiter(store Store, policies map[string]Policy) *Limiter {
+	l := &Limiter{store: store}
+	l.SetPolicies(policies)
+	l.SetAPIKeys(nil)
+	return l
+}
+
+// SetPolicies - метод замены политик (состояние клиентов в хранилище сохраняется)
+func (l *Limiter) SetPolicies(policies map[string]Policy) {
+	l.policies.Store(&policies)
+}
+
+// SetAPIKeys - метод замены ключей API клиентов, ограничиваемых отдельно от IP адреса
+func (l *Limiter) SetAPIKeys(keys []string) {
+	hashes := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		hashes[hashAPIKey(key)] = struct{}{}
+	}
+	l.apiKeys.Store(&hashes)
+}
+
+// APIKeyClient - метод получения ключа клиента по ключу API. Незарегистрированный ключ не учитывается (false):
+// иначе новый ключ в каждом запросе получал бы новую корзину. В хранилище ограничителей ключ API не сохраняется
+func (l *Limiter) APIKeyClient(apiKey string) (string, bool) {
+	if l == nil || len(apiKey) == 0 {
+		return "", false
+	}
+	hash := hashAPIKey(apiKey)
+	if _, ok := (*l.apiKeys.Load())[hash]; !ok {
+		return "", false
+	}
+	return "api:" + hash, true
+}
+
+// hashAPIKey - метод получения хэша ключа API
+func hashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:16])
+}
+
+// ParseAPIKeys - метод разбора списка ключей API через запятую
+func ParseAPIKeys(keys string) []string {
+	var result []string
+	for _, key := range strings.Split(keys, ",") {
+		if key = strings.TrimSpace(key); len(key) > 0 {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+// Allow - метод проверки запроса группы group от клиента key.
+// Если для группы нет политики, запрос разрешается
+func (l *Limiter) Allow(ctx context.Context, group string, key string) (Result, error) {
+	if l == nil {
+		return Result{Allowed: true}, nil
+	}
+	policy, ok := (*l.policies.Load())[group]
+	if !ok {
+		return Result{Allowed: true}, nil
+	}
+	return l.store.Take(ctx, group+"|"+key, policy)
+}
+
+// ParsePolicies - метод разбора политик в формате "группа=скорость:емкость,..." (например, "shorten=10:20,batch=0.5:5").
+// Пустая строка отключает ограничение
+func ParsePolicies(spec string) (map[string]Policy, error) {
+	policies := make(map[string]Policy)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+		group, value, ok := strings.Cut(item, "=")
+		if !ok || len(group) == 0 {
+			return nil, fmt.Errorf("invalid rate limit policy %q: expected group=rate:burst", item)
+		}
+		rateValue, burstValue, ok := strings.Cut(value, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit policy %q: expected group=rate:burst", item)
+		}
+		rate, err := strconv.ParseFloat(rateValue, 64)
+		if err != nil || rate <= 0 || math.IsInf(rate, 0) {
+			return nil, fmt.Errorf("invalid rate in policy %q: must be a positive number", item)
+		}
+		burst, err := strconv.Atoi(burstValue)
+		if err != nil || burst < 1 {
+			return nil, fmt.Errorf("invalid burst in policy %q: must be a positive integer", item)
+		}
+		policies[strings.TrimSpace(group)] = Policy{Rate: rate, Burst: burst}
+	}
+	return policies, nil
+}
+
+// retryAfter - метод расчёта времени до появления целого токена
+func retryAfter(tokens float64, policy Policy) time.Duration {
+	if tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - tokens) / policy.Rate * float64(time.Second))
+}
@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicies(t *testing.T) {
+	testCases := []struct {
+		name     string
+		spec     string
+		policies map[string]Policy
+		wantErr  bool
+	}{
+		{"Several groups #1 (good)", "shorten=10:20, batch=0.5:5", map[string]Policy{
+			GroupShorten: {Rate: 10, Burst: 20},
+			GroupBatch:   {Rate: 0.5, Burst: 5},
+		}, false},
+		{"Empty spec #2 (good)", "", map[string]Policy{}, false},
+		{"Without burst #3 (bad)", "shorten=10", nil, true},
+		{"Zero rate #4 (bad)", "shorten=0:10", nil, true},
+		{"Zero burst #5 (bad)", "shorten=1:0", nil, true},
+		{"Without group #6 (bad)", "=1:1", nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policies, err := ParsePolicies(tc.spec)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.policies, policies)
+		})
+	}
+}
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Date(2025, 10, 19, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	policy := Policy{Rate: 2, Burst: 3}
+	ctx := context.Background()
+
+	for i := 2; i >= 0; i-- {
+		result, err := store.Take(ctx, "client", policy)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, i, result.Remaining)
+	}
+
+	result, err := store.Take(ctx, "client", policy)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+
+	// другой клиент имеет собственную корзину
+	result, err = store.Take(ctx, "other", policy)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// через 0.5 секунды появляется один токен
+	now = now.Add(500 * time.Millisecond)
+	result, err = store.Take(ctx, "client", policy)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	// корзина не пополняется выше емкости
+	now = now.Add(time.Hour)
+	result, err = store.Take(ctx, "client", policy)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Remaining)
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(), map[string]Policy{GroupBatch: {Rate: 1, Burst: 1}})
+	ctx := context.Background()
+
+	result, err := limiter.Allow(ctx, GroupBatch, "client")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	result, err = limiter.Allow(ctx, GroupBatch, "client")
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+
+	// группы без политики и отключенный ограничитель запросы не ограничивают
+	result, err = limiter.Allow(ctx, GroupRedirect, "client")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	result, err = (*Limiter)(nil).Allow(ctx, GroupBatch, "client")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+func TestLimiter_APIKeyClient(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(), nil)
+	limiter.SetAPIKeys(ParseAPIKeys(" partner-key , ,other-key"))
+
+	testCases := []struct {
+		name   string
+		apiKey string
+		ok     bool
+	}{
+		{"Registered key #1 (good)", "partner-key", true},
+		{"Other registered key #2 (good)", "other-key", true},
+		{"Unknown key #3 (bad)", "random-key", false},
+		{"Empty key #4 (bad)", "", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, ok := limiter.APIKeyClient(tc.apiKey)
+			assert.Equal(t, tc.ok, ok)
+			if tc.ok {
+				assert.NotContains(t, key, tc.apiKey)
+			}
+		})
+	}
+
+	limiter.SetAPIKeys(nil)
+	_, ok := limiter.APIKeyClient("partner-key")
+	assert.False(t, ok)
+}
@@ -1,16 +1,111 @@
 package server
 
 import (
+	"crypto/tls"
+	"net"
+
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
 
+	"github.com/denmor86/go-url-shortener/internal/config"
 	pb "github.com/denmor86/go-url-shortener/internal/gen"
+	"github.com/denmor86/go-url-shortener/internal/helpers"
+	"github.com/denmor86/go-url-shortener/internal/logger"
+	"github.com/denmor86/go-url-shortener/internal/network/interceptors"
 	"github.com/denmor86/go-url-shortener/internal/usecase"
 )
 
-// NewServer - метод создаёт новый GRPC сервер
-func NewServer(use *usecase.UsecaseGRPC) *grpc.Server {
+// NewServer - метод создаёт новый GRPC сервер.
+// Регистрирует сервис сокращения ссылок, сервис grpc.health.v1 (если передан) и reflection (в отладочном режиме).
+// Если передана TLS конфигурация, соединения принимаются только по TLS.
+// Дополнительные параметры (например, interceptor ограничения частоты запросов) добавляются после стандартных
+func NewServer(cfg *config.Config, use *usecase.UsecaseGRPC, health *HealthChecker, tlsConfig *tls.Config, extra ...grpc.ServerOption) *grpc.Server {
 
-	s := grpc.NewServer()
+	opts := append(serverOptions(cfg), extra...)
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	s := grpc.NewServer(opts...)
 	pb.RegisterShortenerServer(s, use)
+
+	if health != nil {
+		health.Register(s)
+	}
+	// reflection для grpcurl и подобных утилит доступен только в отладочном режиме
+	if cfg.DebugEnable {
+		reflection.Register(s)
+	}
 	return s
 }
+
+// serverOptions - метод формирования параметров GRPC сервера из конфигурации
+func serverOptions(cfg *config.Config) []grpc.ServerOption {
+	trust := interceptors.NewTrustNet(parseSubnets(cfg.TrustedSubnet), parseMethods(cfg.TrustedMethods)...)
+	trust.SetClients(parseClients(cfg.TrustedClients))
+	cfg.OnReload(func(cfg *config.Config) {
+		trust.SetSubnets(parseSubnets(cfg.TrustedSubnet))
+		trust.SetMethods(parseMethods(cfg.TrustedMethods))
+		trust.SetClients(parseClients(cfg.TrustedClients))
+	})
+	clientIP := interceptors.NewClientIP(helpers.NewClientIPResolver(parseSubnets(cfg.TrustedProxies)))
+
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(interceptors.RequestID, clientIP.Unary, interceptors.Tracing, interceptors.Metrics,
+			interceptors.ClientIdentity, trust.TrustGuard),
+		grpc.ChainStreamInterceptor(interceptors.RequestIDStream, clientIP.Stream, interceptors.TracingStream, interceptors.MetricsStream,
+			interceptors.ClientIdentityStream, trust.TrustGuardStream),
+		// ограничение частоты проверок активности соединения со стороны клиента
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.GRPCKeepaliveMinTime,
+			PermitWithoutStream: true,
+		}),
+		// проверка активности соединения со стороны сервера
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    cfg.GRPCKeepaliveTime,
+			Timeout: cfg.GRPCKeepaliveTimeout,
+		}),
+	}
+	if cfg.GRPCMaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(cfg.GRPCMaxRecvMsgSize))
+	}
+	if cfg.GRPCMaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(cfg.GRPCMaxSendMsgSize))
+	}
+	if cfg.GRPCMaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(cfg.GRPCMaxConcurrentStreams))
+	}
+	return opts
+}
+
+// parseSubnets - метод разбора списка подсетей из конфигурации (при ошибке - пустой список)
+func parseSubnets(subnets string) []*net.IPNet {
+	result, err := helpers.ParseSubnets(subnets)
+	if err != nil {
+		logger.Warn(err)
+		return nil
+	}
+	return result
+}
+
+// parseMethods - метод разбора списка защищаемых методов из конфигурации
+// (при ошибке защищается только получение статистики)
+func parseMethods(methods string) []string {
+	result, err := helpers.ParseGRPCMethods(methods, &pb.Shortener_ServiceDesc)
+	if err != nil {
+		logger.Warn(err)
+		return []string{pb.Shortener_GetStatistic_FullMethodName}
+	}
+	return result
+}
+
+// parseClients - метод разбора списка доверенных клиентов из конфигурации (при ошибке - пустой список)
+func parseClients(clients string) []string {
+	result, err := helpers.ParseClientSubjects(clients)
+	if err != nil {
+		logger.Warn(err)
+		return nil
+	}
+	return result
+}
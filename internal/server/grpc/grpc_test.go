@@ -0,0 +1,237 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/denmor86/go-url-shortener/internal/config"
+	pb "github.com/denmor86/go-url-shortener/internal/gen"
+	"github.com/denmor86/go-url-shortener/internal/helpers"
+	"github.com/denmor86/go-url-shortener/internal/logger"
+	"github.com/denmor86/go-url-shortener/internal/storage"
+	"github.com/denmor86/go-url-shortener/internal/usecase"
+)
+
+// mockPinger - mock-модель проверки соединения с хранилищем
+type mockPinger struct {
+	down atomic.Bool
+}
+
+func (p *mockPinger) PingStorage(ctx context.Context) error {
+	if p.down.Load() {
+		return errors.New("database is down")
+	}
+	return nil
+}
+
+// newTestConn - запуск GRPC сервера в памяти и создание клиентского соединения
+func newTestConn(t *testing.T, s *grpc.Server) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1024 * 1024)
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestHealthChecker(t *testing.T) {
+	require.NoError(t, logger.Initialize("info"))
+
+	pinger := &mockPinger{}
+	health := NewHealthChecker(pinger, DefaultHealthCheckInterval)
+	cfg := config.NewDefaultConfig()
+	use := usecase.NewUsecaseGRPC(cfg, storage.NewMemStorage(), nil)
+	client := healthpb.NewHealthClient(newTestConn(t, NewServer(cfg, use, health, nil)))
+
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		return resp.GetStatus()
+	}
+
+	health.Check()
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(pb.Shortener_ServiceDesc.ServiceName))
+
+	pinger.down.Store(true)
+	health.Check()
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(pb.Shortener_ServiceDesc.ServiceName))
+
+	pinger.down.Store(false)
+	health.Check()
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(""))
+
+	health.Shutdown()
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(""))
+}
+
+func TestNewServer_Reflection(t *testing.T) {
+	const reflectionService = "grpc.reflection.v1.ServerReflection"
+
+	testCases := []struct {
+		name  string
+		debug bool
+	}{
+		{"Debug mode", true},
+		{"Production mode", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			cfg.DebugEnable = tc.debug
+			s := NewServer(cfg, usecase.NewUsecaseGRPC(cfg, storage.NewMemStorage(), nil), nil, nil)
+
+			_, ok := s.GetServiceInfo()[reflectionService]
+			assert.Equal(t, tc.debug, ok)
+			_, ok = s.GetServiceInfo()[pb.Shortener_ServiceDesc.ServiceName]
+			assert.True(t, ok)
+		})
+	}
+}
+
+func TestNewServer_MutualTLS(t *testing.T) {
+	require.NoError(t, logger.Initialize("info"))
+
+	// сертификаты формируются в процессе теста
+	caPEM, caKeyPEM, err := helpers.GenerateCACert("test-ca")
+	require.NoError(t, err)
+	serverPEM, serverKeyPEM, err := helpers.GenerateSignedCert("server", caPEM, caKeyPEM)
+	require.NoError(t, err)
+	clientPEM, clientKeyPEM, err := helpers.GenerateSignedCert("client", caPEM, caKeyPEM)
+	require.NoError(t, err)
+	otherCAPEM, otherCAKeyPEM, err := helpers.GenerateCACert("other-ca")
+	require.NoError(t, err)
+	otherPEM, otherKeyPEM, err := helpers.GenerateSignedCert("client", otherCAPEM, otherCAKeyPEM)
+	require.NoError(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0600))
+
+	serverCert, err := tls.X509KeyPair(serverPEM, serverKeyPEM)
+	require.NoError(t, err)
+	tlsConfig, err := helpers.NewServerTLSConfig(helpers.NewStaticCertReloader(serverCert).GetCertificate, tls.VersionTLS12, caFile)
+	require.NoError(t, err)
+
+	cfg := config.NewDefaultConfig()
+	health := NewHealthChecker(&mockPinger{}, DefaultHealthCheckInterval)
+	s := NewServer(cfg, usecase.NewUsecaseGRPC(cfg, storage.NewMemStorage(), nil), health, tlsConfig)
+	health.Check()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(caPEM))
+
+	check := func(t *testing.T, certs []tls.Certificate) error {
+		creds := credentials.NewTLS(&tls.Config{RootCAs: roots, Certificates: certs, ServerName: "localhost"})
+		conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(creds))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		return err
+	}
+
+	t.Run("Trusted client certificate", func(t *testing.T) {
+		clientCert, err := tls.X509KeyPair(clientPEM, clientKeyPEM)
+		require.NoError(t, err)
+		assert.NoError(t, check(t, []tls.Certificate{clientCert}))
+	})
+
+	t.Run("Without client certificate", func(t *testing.T) {
+		assert.Error(t, check(t, nil))
+	})
+
+	t.Run("Untrusted client certificate", func(t *testing.T) {
+		otherCert, err := tls.X509KeyPair(otherPEM, otherKeyPEM)
+		require.NoError(t, err)
+		assert.Error(t, check(t, []tls.Certificate{otherCert}))
+	})
+
+	t.Run("Plaintext client", func(t *testing.T) {
+		conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		assert.Error(t, err)
+	})
+}
+
+func TestNewServer_TrustedStreamMethods(t *testing.T) {
+	require.NoError(t, logger.Initialize("info"))
+
+	testCases := []struct {
+		name    string
+		methods string
+		code    codes.Code
+	}{
+		{"Open streams #1 (good)", "GetStatistic", codes.OK},
+		{"Guarded streams #2 (bad)", "GetStatistic,ListURLs,EncodeURLStream", codes.PermissionDenied},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			cfg.TrustedSubnet = "192.168.1.0/24"
+			cfg.TrustedMethods = tc.methods
+			s := NewServer(cfg, usecase.NewUsecaseGRPC(cfg, storage.NewMemStorage(), nil), nil, nil)
+			// соединение в памяти не имеет IP адреса и не входит в доверенную подсеть
+			client := pb.NewShortenerClient(newTestConn(t, s))
+
+			list, err := client.ListURLs(context.Background(), &pb.ListURLsRequest{UserId: "user"})
+			require.NoError(t, err)
+			_, err = list.Recv()
+			if tc.code == codes.OK {
+				assert.ErrorIs(t, err, io.EOF)
+			} else {
+				assert.Equal(t, tc.code, status.Code(err))
+			}
+
+			encode, err := client.EncodeURLStream(context.Background())
+			require.NoError(t, err)
+			require.NoError(t, encode.CloseSend())
+			_, err = encode.Recv()
+			if tc.code == codes.OK {
+				assert.ErrorIs(t, err, io.EOF)
+			} else {
+				assert.Equal(t, tc.code, status.Code(err))
+			}
+		})
+	}
+}
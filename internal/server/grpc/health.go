@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	pb "github.com/denmor86/go-url-shortener/internal/gen"
+	"github.com/denmor86/go-url-shortener/internal/logger"
+)
+
+// Параметры проверки состояния сервиса
+const (
+	// DefaultHealthCheckInterval - период проверки состояния хранилища
+	DefaultHealthCheckInterval = 5 * time.Second
+	// healthCheckTimeout - таймаут проверки соединения с хранилищем
+	healthCheckTimeout = time.Second
+)
+
+// Pinger - интерфейс проверки соединения с хранилищем
+type Pinger interface {
+	PingStorage(ctx context.Context) error
+}
+
+// HealthChecker - модель GRPC сервиса grpc.health.v1, состояние которого определяется доступностью хранилища
+type HealthChecker struct {
+	server   *health.Server // сервис grpc.health.v1
+	pinger   Pinger         // проверка соединения с хранилищем
+	interval time.Duration  // период проверки
+	done     chan struct{}  // канал остановки проверки
+	stopOnce sync.Once
+}
+
+// NewHealthChecker - метод создания объекта проверки состояния сервиса
+func NewHealthChecker(pinger Pinger, interval time.Duration) *HealthChecker {
+	if interval <= 0 {
+		interval = DefaultHealthCheckInterval
+	}
+	return &HealthChecker{
+		server:   health.NewServer(),
+		pinger:   pinger,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+// Register - метод регистрации сервиса grpc.health.v1 на GRPC сервере
+func (h *HealthChecker) Register(s *grpc.Server) {
+	healthpb.RegisterHealthServer(s, h.server)
+}
+
+// Run - метод периодической проверки состояния хранилища (блокирующий, до вызова Shutdown)
+func (h *HealthChecker) Run() {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		h.Check()
+		select {
+		case <-h.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check - метод проверки состояния хранилища и обновления статуса сервисов
+func (h *HealthChecker) Check() {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	status := healthpb.HealthCheckResponse_SERVING
+	if err := h.pinger.PingStorage(ctx); err != nil {
+		logger.Warn("Health check failed:", err.Error())
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	// пустое имя - состояние сервера в целом
+	h.server.SetServingStatus("", status)
+	h.server.SetServingStatus(pb.Shortener_ServiceDesc.ServiceName, status)
+}
+
+// Shutdown - метод остановки проверки, все сервисы переводятся в состояние NOT_SERVING
+func (h *HealthChecker) Shutdown() {
+	h.stopOnce.Do(func() {
+		close(h.done)
+		h.server.Shutdown()
+	})
+}
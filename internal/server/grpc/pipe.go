@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/denmor86/go-url-shortener/internal/network/interceptors"
+)
+
+// errPipeClosed - ошибка обращения к закрытому listener
+var errPipeClosed = errors.New("pipe listener closed")
+
+// PipeListener - реализация net.Listener в памяти процесса.
+// Используется для подключения к GRPC серверу внутри приложения (REST шлюз) без открытия сетевого порта
+type PipeListener struct {
+	conns     chan net.Conn // входящие соединения
+	done      chan struct{} // канал закрытия
+	closeOnce sync.Once
+}
+
+// NewPipeListener - метод создания listener в памяти процесса
+func NewPipeListener() *PipeListener {
+	return &PipeListener{
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+// Accept - метод ожидания входящего соединения
+func (l *PipeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, errPipeClosed
+	}
+}
+
+// Close - метод закрытия listener
+func (l *PipeListener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.done)
+	})
+	return nil
+}
+
+// Addr - метод получения адреса listener
+func (l *PipeListener) Addr() net.Addr {
+	return pipeAddr{}
+}
+
+// DialContext - метод установки соединения с listener (используется как grpc.WithContextDialer)
+func (l *PipeListener) DialContext(ctx context.Context, _ string) (net.Conn, error) {
+	server, client := net.Pipe()
+	select {
+	case l.conns <- server:
+		return client, nil
+	case <-l.done:
+		server.Close()
+		client.Close()
+		return nil, errPipeClosed
+	case <-ctx.Done():
+		server.Close()
+		client.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// pipeAddr - адрес listener в памяти процесса
+type pipeAddr struct{}
+
+// Network - метод получения имени сети
+func (pipeAddr) Network() string {
+	return interceptors.PipeNetwork
+}
+
+// String - метод получения адреса
+func (pipeAddr) String() string {
+	return "pipe"
+}
@@ -5,9 +5,9 @@ import (
 	"net/http"
 
 	"github.com/denmor86/go-url-shortener/internal/config"
-	"github.com/denmor86/go-url-shortener/internal/helpers"
-	"github.com/denmor86/go-url-shortener/internal/logger"
+	"github.com/denmor86/go-url-shortener/internal/idempotency"
 	"github.com/denmor86/go-url-shortener/internal/network/router"
+	"github.com/denmor86/go-url-shortener/internal/ratelimit"
 	"github.com/denmor86/go-url-shortener/internal/usecase"
 )
 
@@ -19,27 +19,43 @@ func StartServer(server *http.Server, https bool) error {
 	return server.ListenAndServe()
 }
 
-// NewServer - метод создаёт новый HTTP сервер
-func NewServer(cfg *config.Config, use *usecase.UsecaseHTTP) *http.Server {
-	// Генерируем самоподписанный сертификат
-	cert, key, err := helpers.GenerateSelfSignedCert()
-	if err != nil {
-		logger.Error("error generate certificate", err.Error())
+// NewServer - метод создаёт новый HTTP сервер (TLS конфигурация используется при запуске в режиме https).
+// Таймауты чтения и записи задаются конфигурацией.
+// Если передан обработчик REST шлюза, он подключается по префиксу /api/v2.
+// Если передан обработчик проверки владения доменом ACME, он подключается по префиксу /.well-known/acme-challenge/.
+// Если передан метод перечитывания конфигурации, подключается административное API
+func NewServer(cfg *config.Config, use *usecase.UsecaseHTTP, gateway http.Handler, limiter *ratelimit.Limiter, keeper *idempotency.Keeper, challenge http.Handler,
+	reload func() (config.ReloadResult, error), tlsConfig *tls.Config) *http.Server {
+	r := router.HandleRouter(cfg, use, limiter, keeper)
+	if gateway != nil {
+		router.HandleGateway(r, cfg, gateway, limiter, keeper)
 	}
-
-	// Создаем TLS конфигурацию
-	tlsConfig := &tls.Config{
-		Certificates: []tls.Certificate{
-			{
-				Certificate: [][]byte{cert},
-				PrivateKey:  key,
-			},
-		},
+	if challenge != nil {
+		router.HandleACMEChallenge(r, challenge)
+	}
+	if reload != nil {
+		router.HandleAdmin(r, cfg, reload)
 	}
+	return &http.Server{
+		Addr:              cfg.ListenAddr,
+		Handler:           r,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+}
 
+// NewChallengeServer - метод создаёт HTTP сервер проверки владения доменом ACME (HTTP-01),
+// остальные запросы перенаправляются на HTTPS
+func NewChallengeServer(cfg *config.Config, challenge http.Handler) *http.Server {
 	return &http.Server{
-		Addr:      cfg.ListenAddr,
-		Handler:   router.HandleRouter(cfg, use),
-		TLSConfig: tlsConfig,
+		Addr:              cfg.ACMEHTTPAddr,
+		Handler:           router.ChallengeRouter(cfg, challenge),
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/denmor86/go-url-shortener/internal/logger"
 )
@@ -69,6 +70,14 @@ func (s *FileStorage) Initialize(filepath string) error {
 			UserID:      info.UserID,
 			IsDeleted:   info.IsDeleted})
 	}
+	// общий счетчик квоты восстанавливается по записям пользователя, суточный - не сохраняется между запусками
+	for _, record := range s.Cache.Urls {
+		if len(record.UserID) != 0 {
+			counter := s.Cache.quotas[record.UserID]
+			counter.total++
+			s.Cache.quotas[record.UserID] = counter
+		}
+	}
 	return nil
 }
 
@@ -86,15 +95,15 @@ func (s *FileStorage) AddRecord(ctx context.Context, record TableRecord) error {
 		IsDeleted:   record.IsDeleted}
 	data, err := json.Marshal(&info)
 	if err != nil {
-		logger.Warn("Can't marchal value:", err)
+		logger.WarnCtx(ctx, "Can't marchal value:", err)
 	}
 	// записываем значение
 	if _, err := s.Writer.Write(data); err != nil {
-		logger.Warn("Can't write cache value:", err)
+		logger.WarnCtx(ctx, "Can't write cache value:", err)
 	}
 	// добавляем перенос строки
 	if err := s.Writer.WriteByte('\n'); err != nil {
-		logger.Warn("Invalid write separator:", err)
+		logger.WarnCtx(ctx, "Invalid write separator:", err)
 	}
 	// записываем буфер в файл
 	return s.Writer.Flush()
@@ -119,7 +128,7 @@ func (s *FileStorage) GetRecord(ctx context.Context, shortURL string) (string, e
 	if err == nil {
 		return longURL, nil
 	}
-	return "", fmt.Errorf("short url not found: %s", shortURL)
+	return "", fmt.Errorf("%w: %s", ErrNotFound, shortURL)
 }
 
 // GetUserRecords - метод получения массива записей пользователя из файлового кэша
@@ -136,6 +145,15 @@ func (s *FileStorage) GetUserRecords(ctx context.Context, userID string) ([]Tabl
 	return records, nil
 }
 
+// GetUserRecordsPage - метод получения страницы записей пользователя из файлового кэша.
+// Записи упорядочены по короткой ссылке, cursor - последняя короткая ссылка предыдущей страницы
+func (s *FileStorage) GetUserRecordsPage(ctx context.Context, userID string, cursor string, limit int) ([]TableRecord, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	return userRecordsPage(s.Cache.Urls, userID, cursor, limit), ctx.Err()
+}
+
 // DeleteURLs - метод отметки массива записей пользователя на удаление
 func (s *FileStorage) DeleteURLs(ctx context.Context, userID string, shortURLS []string) error {
 	s.Lock()
@@ -154,6 +172,21 @@ func (s *FileStorage) DeleteURLs(ctx context.Context, userID string, shortURLS [
 	return nil
 }
 
+// ReserveQuota - метод резервирования квоты пользователя на создание count ссылок
+func (s *FileStorage) ReserveQuota(ctx context.Context, userID string, day time.Time, count int, limit QuotaLimit) (QuotaUsage, error) {
+	return s.Cache.ReserveQuota(ctx, userID, day, count, limit)
+}
+
+// ReleaseQuota - метод возврата зарезервированной квоты (ссылки не были созданы)
+func (s *FileStorage) ReleaseQuota(ctx context.Context, userID string, day time.Time, count int) error {
+	return s.Cache.ReleaseQuota(ctx, userID, day, count)
+}
+
+// GetQuotaUsage - метод получения использования квоты пользователя
+func (s *FileStorage) GetQuotaUsage(ctx context.Context, userID string, day time.Time) (QuotaUsage, error) {
+	return s.Cache.GetQuotaUsage(ctx, userID, day)
+}
+
 // Ping - метод проверки наличия открытого файла с кэшем данных
 func (s *FileStorage) Ping(ctx context.Context) error {
 	if s.File != nil {
@@ -4,19 +4,38 @@ package storage
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 )
 
 // MemStorage - хранилище данных в кэше оперативной памяти
 type MemStorage struct {
-	Urls         map[string]TableRecord // записи
-	sync.RWMutex                        // мьютекс для синхронизации
+	Urls         map[string]TableRecord  // записи
+	quotas       map[string]quotaCounter // счетчики квот пользователей
+	sync.RWMutex                         // мьютекс для синхронизации
+}
+
+// quotaCounter - счетчики квоты пользователя
+type quotaCounter struct {
+	day   time.Time // сутки суточного счетчика
+	daily int       // количество ссылок за сутки
+	total int       // общее количество ссылок
+}
+
+// usage - метод получения использования квоты на сутки day
+func (c quotaCounter) usage(day time.Time) QuotaUsage {
+	if !c.day.Equal(day) {
+		return QuotaUsage{Total: c.total}
+	}
+	return QuotaUsage{Daily: c.daily, Total: c.total}
 }
 
 // NewMemStorage - метод создания хранилища данных в кэше из оперативной памяти
 func NewMemStorage() *MemStorage {
 	var s MemStorage
 	s.Urls = make(map[string]TableRecord)
+	s.quotas = make(map[string]quotaCounter)
 	return &s
 }
 
@@ -51,7 +70,7 @@ func (s *MemStorage) GetRecord(ctx context.Context, shortURL string) (string, er
 	if exist {
 		return record.OriginalURL, nil
 	}
-	return "", fmt.Errorf("short url not found: %s", shortURL)
+	return "", fmt.Errorf("%w: %s", ErrNotFound, shortURL)
 }
 
 // GetUserRecords - метод получения массива записей пользователя из кэша в оперативной памяти
@@ -67,6 +86,32 @@ func (s *MemStorage) GetUserRecords(ctx context.Context, userID string) ([]Table
 	return records, nil
 }
 
+// GetUserRecordsPage - метод получения страницы записей пользователя из кэша в оперативной памяти.
+// Записи упорядочены по короткой ссылке, cursor - последняя короткая ссылка предыдущей страницы
+func (s *MemStorage) GetUserRecordsPage(ctx context.Context, userID string, cursor string, limit int) ([]TableRecord, error) {
+	s.RLock()
+	records := userRecordsPage(s.Urls, userID, cursor, limit)
+	s.RUnlock()
+	return records, ctx.Err()
+}
+
+// userRecordsPage - метод формирования страницы не удаленных записей пользователя
+func userRecordsPage(urls map[string]TableRecord, userID string, cursor string, limit int) []TableRecord {
+	var records []TableRecord
+	for _, record := range urls {
+		if record.UserID == userID && !record.IsDeleted && record.ShortURL > cursor {
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].ShortURL < records[j].ShortURL
+	})
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return records
+}
+
 // DeleteURLs - метод отметки массива записей пользователя на удаление
 func (s *MemStorage) DeleteURLs(ctx context.Context, userID string, shortURLS []string) error {
 	s.Lock()
@@ -84,6 +129,47 @@ func (s *MemStorage) DeleteURLs(ctx context.Context, userID string, shortURLS []
 	return nil
 }
 
+// ReserveQuota - метод резервирования квоты пользователя на создание count ссылок.
+// При превышении лимита возвращает текущее использование квоты и ErrQuotaExceeded
+func (s *MemStorage) ReserveQuota(ctx context.Context, userID string, day time.Time, count int, limit QuotaLimit) (QuotaUsage, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	usage := s.quotas[userID].usage(day)
+	if !limit.Allows(usage, count) {
+		return usage, ErrQuotaExceeded
+	}
+	usage.Daily += count
+	usage.Total += count
+	s.quotas[userID] = quotaCounter{day: day, daily: usage.Daily, total: usage.Total}
+	return usage, nil
+}
+
+// ReleaseQuota - метод возврата зарезервированной квоты (ссылки не были созданы)
+func (s *MemStorage) ReleaseQuota(ctx context.Context, userID string, day time.Time, count int) error {
+	s.Lock()
+	defer s.Unlock()
+
+	counter, exist := s.quotas[userID]
+	if !exist {
+		return nil
+	}
+	if counter.day.Equal(day) {
+		counter.daily = max(counter.daily-count, 0)
+	}
+	counter.total = max(counter.total-count, 0)
+	s.quotas[userID] = counter
+	return nil
+}
+
+// GetQuotaUsage - метод получения использования квоты пользователя
+func (s *MemStorage) GetQuotaUsage(ctx context.Context, userID string, day time.Time) (QuotaUsage, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.quotas[userID].usage(day), nil
+}
+
 // Size - метод определения размера кэша
 func (s *MemStorage) Size() int {
 	return len(s.Urls)
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/denmor86/go-url-shortener/internal/metrics"
+)
+
+// MetricsStorage - декоратор хранилища, учитывающий длительность и результат каждой операции
+type MetricsStorage struct {
+	IStorage
+}
+
+// NewMetricsStorage - метод создания хранилища с учётом метрик операций
+func NewMetricsStorage(s IStorage) *MetricsStorage {
+	return &MetricsStorage{IStorage: s}
+}
+
+// observe - метод учёта операции с хранилищем
+func observe(operation string, start time.Time, err error) {
+	metrics.ObserveStorage(operation, err, time.Since(start))
+}
+
+// AddRecord - метод добавления записи в хранилище
+func (s *MetricsStorage) AddRecord(ctx context.Context, record TableRecord) error {
+	start := time.Now()
+	err := s.IStorage.AddRecord(ctx, record)
+	observe("AddRecord", start, err)
+	return err
+}
+
+// AddRecords - метод добавления массива записей в хранилище
+func (s *MetricsStorage) AddRecords(ctx context.Context, records []TableRecord) error {
+	start := time.Now()
+	err := s.IStorage.AddRecords(ctx, records)
+	observe("AddRecords", start, err)
+	return err
+}
+
+// GetRecord - метод получения оригинального URL по короткому
+func (s *MetricsStorage) GetRecord(ctx context.Context, shortURL string) (string, error) {
+	start := time.Now()
+	url, err := s.IStorage.GetRecord(ctx, shortURL)
+	observe("GetRecord", start, err)
+	return url, err
+}
+
+// GetUserRecords - метод получения записей пользователя
+func (s *MetricsStorage) GetUserRecords(ctx context.Context, userID string) ([]TableRecord, error) {
+	start := time.Now()
+	records, err := s.IStorage.GetUserRecords(ctx, userID)
+	observe("GetUserRecords", start, err)
+	return records, err
+}
+
+// GetUserRecordsPage - метод получения страницы записей пользователя
+func (s *MetricsStorage) GetUserRecordsPage(ctx context.Context, userID string, cursor string, limit int) ([]TableRecord, error) {
+	start := time.Now()
+	records, err := s.IStorage.GetUserRecordsPage(ctx, userID, cursor, limit)
+	observe("GetUserRecordsPage", start, err)
+	return records, err
+}
+
+// DeleteURLs - метод отметки массива записей пользователя на удаление
+func (s *MetricsStorage) DeleteURLs(ctx context.Context, userID string, shortURLs []string) error {
+	start := time.Now()
+	err := s.IStorage.DeleteURLs(ctx, userID, shortURLs)
+	observe("DeleteURLs", start, err)
+	return err
+}
+
+// Ping - метод проверки соединения с хранилищем
+func (s *MetricsStorage) Ping(ctx context.Context) error {
+	start := time.Now()
+	err := s.IStorage.Ping(ctx)
+	observe("Ping", start, err)
+	return err
+}
+
+// GetStat - метод получения статистики хранилища
+func (s *MetricsStorage) GetStat(ctx context.Context) RecordStatistic {
+	start := time.Now()
+	stat := s.IStorage.GetStat(ctx)
+	observe("GetStat", start, nil)
+	return stat
+}
+
+// ReserveQuota - метод резервирования квоты пользователя
+func (s *MetricsStorage) ReserveQuota(ctx context.Context, userID string, day time.Time, count int, limit QuotaLimit) (QuotaUsage, error) {
+	start := time.Now()
+	usage, err := s.IStorage.ReserveQuota(ctx, userID, day, count, limit)
+	// превышение квоты не является ошибкой хранилища
+	if errors.Is(err, ErrQuotaExceeded) {
+		observe("ReserveQuota", start, nil)
+	} else {
+		observe("ReserveQuota", start, err)
+	}
+	return usage, err
+}
+
+// ReleaseQuota - метод возврата зарезервированной квоты
+func (s *MetricsStorage) ReleaseQuota(ctx context.Context, userID string, day time.Time, count int) error {
+	start := time.Now()
+	err := s.IStorage.ReleaseQuota(ctx, userID, day, count)
+	observe("ReleaseQuota", start, err)
+	return err
+}
+
+// GetQuotaUsage - метод получения использования квоты пользователя
+func (s *MetricsStorage) GetQuotaUsage(ctx context.Context, userID string, day time.Time) (QuotaUsage, error) {
+	start := time.Now()
+	usage, err := s.IStorage.GetQuotaUsage(ctx, userID, day)
+	observe("GetQuotaUsage", start, err)
+	return usage, err
+}
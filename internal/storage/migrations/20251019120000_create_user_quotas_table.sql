@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS user_quotas (
+    user_uuid TEXT PRIMARY KEY,
+    day DATE NOT NULL,
+    daily INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL DEFAULT 0
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO user_quotas (user_uuid, day, daily, total)
+SELECT user_uuid, CURRENT_DATE, 0, count(*) FROM URLs
+WHERE user_uuid IS NOT NULL
+GROUP BY user_uuid
+ON CONFLICT (user_uuid) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_quotas;
+-- +goose StatementEnd
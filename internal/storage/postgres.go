@@ -7,8 +7,11 @@ import (
 	"embed"
 	"errors"
 	"fmt"
+	"net"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
@@ -37,6 +40,22 @@ func (e *DeletedViolation) Error() string {
 	return e.Message
 }
 
+// ErrNotFound - ошибка отсутствия записи по короткой ссылке
+var ErrNotFound = errors.New("short url not found")
+
+// IsUnavailable - метод определения ошибки недоступности хранилища (нет соединения, истек таймаут)
+func IsUnavailable(err error) bool {
+	var connectError *pgconn.ConnectError
+	if errors.As(err, &connectError) {
+		return true
+	}
+	var netError net.Error
+	if errors.As(err, &netError) {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
 // DatabaseStorage - хранилище данных в БД
 type DatabaseStorage struct {
 	Pool   *pgxpool.Pool   // пул подключений
@@ -61,12 +80,33 @@ const (
 	GetShortURL = `SELECT short_url FROM URLs WHERE original_url =$1;`
 	// GetUserlURL - SQL запрос записи по пользователю
 	GetUserlURL = `SELECT user_uuid, original_url, short_url FROM urls WHERE user_uuid=$1 AND NOT is_deleted;`
+	// GetUserURLPage - SQL запрос страницы записей по пользователю (упорядочены по короткой ссылке)
+	GetUserURLPage = `SELECT user_uuid, original_url, short_url FROM urls
+						WHERE user_uuid=$1 AND NOT is_deleted AND short_url > $2
+						ORDER BY short_url LIMIT $3;`
 	// DeleteUserURL - SQL запрос отметки записи для удаления по пользователю и короткой ссылке
 	DeleteUserURL = `UPDATE urls SET is_deleted=TRUE WHERE user_uuid=$1 AND short_url=$2`
 	// GetURLsCounts - SQL запрос c получением количества записей
 	GetURLsCounts = `SELECT count(*) FROM urls;`
 	// GetURLsCounts - SQL запрос c получением количества пользователей
 	GetUsersCounts = `SELECT count(DISTINCT user_uuid)	FROM urls WHERE user_uuid IS NOT NULL;`
+	// ReserveQuota - SQL запрос атомарного резервирования квоты пользователя (строка не изменяется при превышении лимита)
+	ReserveQuota = `INSERT INTO user_quotas AS q (user_uuid, day, daily, total)
+						VALUES ($1, $2, $3::integer, $3::integer)
+						ON CONFLICT (user_uuid) DO UPDATE SET
+							daily = CASE WHEN q.day = $2 THEN q.daily ELSE 0 END + $3::integer,
+							total = q.total + $3::integer,
+							day = $2
+						WHERE ($4::integer = 0 OR CASE WHEN q.day = $2 THEN q.daily ELSE 0 END + $3::integer <= $4::integer)
+							AND ($5::integer = 0 OR q.total + $3::integer <= $5::integer)
+						RETURNING daily, total;`
+	// ReleaseQuota - SQL запрос возврата зарезервированной квоты пользователя
+	ReleaseQuota = `UPDATE user_quotas SET
+						daily = CASE WHEN day = $2 THEN GREATEST(daily - $3::integer, 0) ELSE daily END,
+						total = GREATEST(total - $3::integer, 0)
+					WHERE user_uuid = $1;`
+	// GetQuotaUsage - SQL запрос использования квоты пользователя
+	GetQuotaUsage = `SELECT CASE WHEN day = $2 THEN daily ELSE 0 END, total FROM user_quotas WHERE user_uuid = $1;`
 )
 
 // NewDatabaseStorage - метод создания хранилища данных в БД
@@ -187,7 +227,11 @@ func (s *DatabaseStorage) AddRecords(ctx context.Context, records []TableRecord)
 	}()
 
 	for _, rec := range records {
-		_, err := s.Pool.Exec(ctx, InsertRecord, rec.ShortURL, rec.OriginalURL, rec.UserID)
+		// прерываем добавление при отмене запроса
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, InsertRecord, rec.ShortURL, rec.OriginalURL, rec.UserID)
 		if err != nil {
 			return err
 		}
@@ -204,7 +248,7 @@ func (s *DatabaseStorage) GetRecord(ctx context.Context, shortURL string) (strin
 	err := s.Pool.QueryRow(ctx, GetOriginalURL, shortURL).Scan(&originalURL, &isDeleted)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return "", fmt.Errorf("short url not found: %s", shortURL)
+			return "", fmt.Errorf("%w: %s", ErrNotFound, shortURL)
 		}
 		return "", fmt.Errorf("failed to get record: %w", err)
 	}
@@ -222,14 +266,35 @@ func (s *DatabaseStorage) GetUserRecords(ctx context.Context, userID string) ([]
 	if err != nil {
 		return records, fmt.Errorf("failed to get user record: %w", err)
 	}
+	return scanUserRecords(rows)
+}
+
+// GetUserRecordsPage - метод получения страницы записей пользователя из БД.
+// Записи упорядочены по короткой ссылке, cursor - последняя короткая ссылка предыдущей страницы
+func (s *DatabaseStorage) GetUserRecordsPage(ctx context.Context, userID string, cursor string, limit int) ([]TableRecord, error) {
+	rows, err := s.Pool.Query(ctx, GetUserURLPage, userID, cursor, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user records page: %w", err)
+	}
+	return scanUserRecords(rows)
+}
+
+// scanUserRecords - метод чтения записей пользователя из результата запроса
+func scanUserRecords(rows pgx.Rows) ([]TableRecord, error) {
+	defer rows.Close()
+
+	var records []TableRecord
 	for rows.Next() {
 		var record TableRecord
-		err = rows.Scan(&record.UserID, &record.OriginalURL, &record.ShortURL)
-		if err != nil {
+		if err := rows.Scan(&record.UserID, &record.OriginalURL, &record.ShortURL); err != nil {
 			return records, fmt.Errorf("failed scan  user record: %w", err)
 		}
 		records = append(records, record)
 	}
+	// ошибка чтения (в т.ч. отмена контекста запроса)
+	if err := rows.Err(); err != nil {
+		return records, fmt.Errorf("failed read user records: %w", err)
+	}
 	return records, nil
 }
 
@@ -266,6 +331,51 @@ func (s *DatabaseStorage) DeleteURLs(ctx context.Context, userID string, shortUR
 	return tx.Commit(ctx)
 }
 
+// ReserveQuota - метод резервирования квоты пользователя на создание count ссылок.
+// При превышении лимита возвращает текущее использование квоты и ErrQuotaExceeded
+func (s *DatabaseStorage) ReserveQuota(ctx context.Context, userID string, day time.Time, count int, limit QuotaLimit) (QuotaUsage, error) {
+	// новая строка квоты в запросе не проверяется, поэтому заведомое превышение отсекается заранее
+	if !limit.Allows(QuotaUsage{}, count) {
+		usage, err := s.GetQuotaUsage(ctx, userID, day)
+		if err != nil {
+			return usage, err
+		}
+		return usage, ErrQuotaExceeded
+	}
+	var usage QuotaUsage
+	err := s.Pool.QueryRow(ctx, ReserveQuota, userID, day, count, limit.Daily, limit.Total).Scan(&usage.Daily, &usage.Total)
+	if err == nil {
+		return usage, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return usage, fmt.Errorf("failed to reserve quota: %w", err)
+	}
+	// строка не изменена - лимит превышен
+	usage, err = s.GetQuotaUsage(ctx, userID, day)
+	if err != nil {
+		return usage, err
+	}
+	return usage, ErrQuotaExceeded
+}
+
+// ReleaseQuota - метод возврата зарезервированной квоты (ссылки не были созданы)
+func (s *DatabaseStorage) ReleaseQuota(ctx context.Context, userID string, day time.Time, count int) error {
+	if _, err := s.Pool.Exec(ctx, ReleaseQuota, userID, day, count); err != nil {
+		return fmt.Errorf("failed to release quota: %w", err)
+	}
+	return nil
+}
+
+// GetQuotaUsage - метод получения использования квоты пользователя
+func (s *DatabaseStorage) GetQuotaUsage(ctx context.Context, userID string, day time.Time) (QuotaUsage, error) {
+	var usage QuotaUsage
+	err := s.Pool.QueryRow(ctx, GetQuotaUsage, userID, day).Scan(&usage.Daily, &usage.Total)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return usage, fmt.Errorf("failed to get quota usage: %w", err)
+	}
+	return usage, nil
+}
+
 // Ping - метод проверки соединения с БД
 func (s *DatabaseStorage) Ping(ctx context.Context) error {
 	return s.Pool.Ping(ctx)
@@ -276,13 +386,13 @@ func (s *DatabaseStorage) GetStat(ctx context.Context) RecordStatistic {
 	var Urls int
 	err := s.Pool.QueryRow(ctx, GetURLsCounts).Scan(&Urls)
 	if err != nil {
-		logger.Warn("Error get URLs count")
+		logger.WarnCtx(ctx, "Error get URLs count:", err)
 		Urls = 0
 	}
 	var Users int
 	err = s.Pool.QueryRow(ctx, GetUsersCounts).Scan(&Users)
 	if err != nil {
-		logger.Warn("Error get Users count")
+		logger.WarnCtx(ctx, "Error get Users count:", err)
 		Users = 0
 	}
 	return RecordStatistic{URLs: Urls, Users: Users}
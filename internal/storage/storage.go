@@ -3,9 +3,11 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
-	"github.com/pkg/errors"
+	pkgerrors "github.com/pkg/errors"
 
 	"github.com/denmor86/go-url-shortener/internal/config"
 )
@@ -24,10 +26,34 @@ type RecordStatistic struct {
 	Users int // количество пользователей
 }
 
+// QuotaLimit - лимиты квоты пользователя на создание ссылок (0 - без ограничения)
+type QuotaLimit struct {
+	Daily int // количество ссылок в сутки (UTC)
+	Total int // общее количество ссылок
+}
+
+// Allows - метод проверки возможности создать count ссылок при текущем использовании квоты
+func (l QuotaLimit) Allows(usage QuotaUsage, count int) bool {
+	if l.Daily > 0 && usage.Daily+count > l.Daily {
+		return false
+	}
+	return l.Total == 0 || usage.Total+count <= l.Total
+}
+
+// QuotaUsage - использование квоты пользователя
+type QuotaUsage struct {
+	Daily int // количество созданных за сутки ссылок
+	Total int // общее количество созданных ссылок
+}
+
+// ErrQuotaExceeded - ошибка превышения квоты пользователя
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
 // ReadStorage интерфейс для работы с чтением данных из хранилища
 type ReadStorage interface {
 	GetRecord(context.Context, string) (string, error)
 	GetUserRecords(context.Context, string) ([]TableRecord, error)
+	GetUserRecordsPage(context.Context, string, string, int) ([]TableRecord, error)
 	Ping(ctx context.Context) error
 	GetStat(ctx context.Context) RecordStatistic
 }
@@ -40,10 +66,19 @@ type WriteStorage interface {
 	Close() error
 }
 
+// QuotaStorage интерфейс для атомарного учёта квот пользователей.
+// Сутки day задаются началом суток UTC, при смене суток суточный счетчик обнуляется
+type QuotaStorage interface {
+	ReserveQuota(ctx context.Context, userID string, day time.Time, count int, limit QuotaLimit) (QuotaUsage, error)
+	ReleaseQuota(ctx context.Context, userID string, day time.Time, count int) error
+	GetQuotaUsage(ctx context.Context, userID string, day time.Time) (QuotaUsage, error)
+}
+
 // IStorage полный интерфейс для работы с хранилищем данных
 type IStorage interface {
 	ReadStorage
 	WriteStorage
+	QuotaStorage
 }
 
 // NewStorage создание интерферса хранилища данных (поддерживает хранение в БД, оперативной памяти и текстовом файле)
@@ -52,17 +87,17 @@ func NewStorage(cfg *config.Config) IStorage {
 	if cfg.DatabaseDSN != "" {
 		storage, err := NewDatabaseStorage(cfg.DatabaseDSN)
 		if err != nil {
-			panic(fmt.Sprintf("can't create database storage: %s ", errors.Cause(err).Error()))
+			panic(fmt.Sprintf("can't create database storage: %s ", pkgerrors.Cause(err).Error()))
 		}
 		if err = storage.Initialize(); err != nil {
-			panic(fmt.Sprintf("can't initialize database storage: %s ", errors.Cause(err).Error()))
+			panic(fmt.Sprintf("can't initialize database storage: %s ", pkgerrors.Cause(err).Error()))
 		}
 		return storage
 	}
 	if cfg.FileStoragePath != "" {
 		storage := NewFileStorage()
 		if err := storage.Initialize(cfg.FileStoragePath); err != nil {
-			panic(fmt.Sprintf("can't initialize cache file storage: %s ", errors.Cause(err).Error()))
+			panic(fmt.Sprintf("can't initialize cache file storage: %s ", pkgerrors.Cause(err).Error()))
 		}
 		return storage
 	}
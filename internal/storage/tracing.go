@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/denmor86/go-url-shortener/internal/tracing"
+)
+
+// TracingStorage - декоратор хранилища, создающий span для каждой операции
+type TracingStorage struct {
+	IStorage
+}
+
+// NewTracingStorage - метод создания хранилища с трассировкой операций
+func NewTracingStorage(s IStorage) *TracingStorage {
+	return &TracingStorage{IStorage: s}
+}
+
+// startSpan - метод создания span операции с хранилищем.
+// Span создается только в рамках существующей трассы (запрос, задача), фоновые проверки не трассируются
+func startSpan(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return tracing.Start(ctx, "storage."+operation, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+}
+
+// AddRecord - метод добавления записи в хранилище
+func (s *TracingStorage) AddRecord(ctx context.Context, record TableRecord) error {
+	ctx, span := startSpan(ctx, "AddRecord", attribute.String("shortener.short_url", record.ShortURL))
+	err := s.IStorage.AddRecord(ctx, record)
+	tracing.End(span, err)
+	return err
+}
+
+// AddRecords - метод добавления массива записей в хранилище
+func (s *TracingStorage) AddRecords(ctx context.Context, records []TableRecord) error {
+	ctx, span := startSpan(ctx, "AddRecords", attribute.Int("shortener.records", len(records)))
+	err := s.IStorage.AddRecords(ctx, records)
+	tracing.End(span, err)
+	return err
+}
+
+// GetRecord - метод получения оригинального URL по короткому
+func (s *TracingStorage) GetRecord(ctx context.Context, shortURL string) (string, error) {
+	ctx, span := startSpan(ctx, "GetRecord", attribute.String("shortener.short_url", shortURL))
+	url, err := s.IStorage.GetRecord(ctx, shortURL)
+	tracing.End(span, err)
+	return url, err
+}
+
+// GetUserRecords - метод получения записей пользователя
+func (s *TracingStorage) GetUserRecords(ctx context.Context, userID string) ([]TableRecord, error) {
+	ctx, span := startSpan(ctx, "GetUserRecords")
+	records, err := s.IStorage.GetUserRecords(ctx, userID)
+	span.SetAttributes(attribute.Int("shortener.records", len(records)))
+	tracing.End(span, err)
+	return records, err
+}
+
+// GetUserRecordsPage - метод получения страницы записей пользователя
+func (s *TracingStorage) GetUserRecordsPage(ctx context.Context, userID string, cursor string, limit int) ([]TableRecord, error) {
+	ctx, span := startSpan(ctx, "GetUserRecordsPage", attribute.Int("shortener.limit", limit))
+	records, err := s.IStorage.GetUserRecordsPage(ctx, userID, cursor, limit)
+	span.SetAttributes(attribute.Int("shortener.records", len(records)))
+	tracing.End(span, err)
+	return records, err
+}
+
+// DeleteURLs - метод отметки массива записей пользователя на удаление
+func (s *TracingStorage) DeleteURLs(ctx context.Context, userID string, shortURLs []string) error {
+	ctx, span := startSpan(ctx, "DeleteURLs", attribute.Int("shortener.records", len(shortURLs)))
+	err := s.IStorage.DeleteURLs(ctx, userID, shortURLs)
+	tracing.End(span, err)
+	return err
+}
+
+// Ping - метод проверки соединения с хранилищем
+func (s *TracingStorage) Ping(ctx context.Context) error {
+	ctx, span := startSpan(ctx, "Ping")
+	err := s.IStorage.Ping(ctx)
+	tracing.End(span, err)
+	return err
+}
+
+// GetStat - метод получения статистики хранилища
+func (s *TracingStorage) GetStat(ctx context.Context) RecordStatistic {
+	ctx, span := startSpan(ctx, "GetStat")
+	stat := s.IStorage.GetStat(ctx)
+	span.End()
+	return stat
+}
+
+// ReserveQuota - метод резервирования квоты пользователя
+func (s *TracingStorage) ReserveQuota(ctx context.Context, userID string, day time.Time, count int, limit QuotaLimit) (QuotaUsage, error) {
+	ctx, span := startSpan(ctx, "ReserveQuota", attribute.Int("shortener.records", count))
+	usage, err := s.IStorage.ReserveQuota(ctx, userID, day, count, limit)
+	span.SetAttributes(attribute.Bool("shortener.quota_exceeded", errors.Is(err, ErrQuotaExceeded)))
+	if errors.Is(err, ErrQuotaExceeded) {
+		tracing.End(span, nil)
+	} else {
+		tracing.End(span, err)
+	}
+	return usage, err
+}
+
+// ReleaseQuota - метод возврата зарезервированной квоты
+func (s *TracingStorage) ReleaseQuota(ctx context.Context, userID string, day time.Time, count int) error {
+	ctx, span := startSpan(ctx, "ReleaseQuota", attribute.Int("shortener.records", count))
+	err := s.IStorage.ReleaseQuota(ctx, userID, day, count)
+	tracing.End(span, err)
+	return err
+}
+
+// GetQuotaUsage - метод получения использования квоты пользователя
+func (s *TracingStorage) GetQuotaUsage(ctx context.Context, userID string, day time.Time) (QuotaUsage, error) {
+	ctx, span := startSpan(ctx, "GetQuotaUsage")
+	usage, err := s.IStorage.GetQuotaUsage(ctx, userID, day)
+	tracing.End(span, err)
+	return usage, err
+}
@@ -0,0 +1,152 @@
+// Package tracing предоставляет инициализацию распределенной трассировки (OpenTelemetry)
+// и вспомогательные методы передачи контекста трассировки (W3C traceparent) между сервисами
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/denmor86/go-url-shortener/internal/config"
+)
+
+// Параметры трассировки
+const (
+	// ServiceName - имя сервиса в трассировке
+	ServiceName = "go-url-shortener"
+	// instrumentationName - имя библиотеки инструментирования
+	instrumentationName = "github.com/denmor86/go-url-shortener"
+
+	// ExporterNone - трассировка отключена (контекст трассировки продолжает передаваться)
+	ExporterNone = "none"
+	// ExporterStdout - вывод трассировки в стандартный поток вывода
+	ExporterStdout = "stdout"
+	// ExporterOTLP - отправка трассировки в OTLP коллектор по GRPC
+	ExporterOTLP = "otlp"
+)
+
+// ShutdownFunc - метод завершения трассировки с отправкой накопленных данных
+type ShutdownFunc func(ctx context.Context) error
+
+func init() {
+	// формат W3C traceparent/tracestate и baggage используется независимо от настроек экспорта
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Initialize - метод инициализации трассировки в соответствии с конфигурацией
+func Initialize(ctx context.Context, cfg *config.Config) (ShutdownFunc, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch cfg.TracingExporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.TracingEndpoint)}
+		if cfg.TracingInsecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter: %s", cfg.TracingExporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(),
+		resource.NewSchemaless(semconv.ServiceName(ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// решение о трассировке принимает вызывающая сторона (traceparent), для новых трасс - доля запросов
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.TracingSampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start - метод создания дочернего span в контексте
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// End - метод завершения span с учётом ошибки операции
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Extract - метод получения контекста трассировки из входящих заголовков (метаданных)
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+// Inject - метод записи контекста трассировки в исходящие заголовки (метаданные)
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+}
+
+// Link - метод формирования связи с span из контекста (для асинхронных задач)
+func Link(ctx context.Context, attrs ...attribute.KeyValue) trace.Link {
+	return trace.LinkFromContext(ctx, attrs...)
+}
+
+// MetadataCarrier - адаптер метаданных GRPC для передачи контекста трассировки
+type MetadataCarrier metadata.MD
+
+// Get - метод получения значения ключа
+func (c MetadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Set - метод установки значения ключа
+func (c MetadataCarrier) Set(key string, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// Keys - метод получения списка ключей
+func (c MetadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// InjectOutgoing - метод добавления контекста трассировки в исходящие метаданные GRPC вызова
+func InjectOutgoing(ctx context.Context) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	Inject(ctx, MetadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/denmor86/go-url-shortener/internal/config"
+)
+
+func TestInitialize(t *testing.T) {
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	testCases := []struct {
+		name     string
+		exporter string
+		wantErr  bool
+	}{
+		{"None exporter #1 (good)", ExporterNone, false},
+		{"Empty exporter #2 (good)", "", false},
+		{"Stdout exporter #3 (good)", ExporterStdout, false},
+		{"OTLP exporter #4 (good)", ExporterOTLP, false},
+		{"Unknown exporter #5 (bad)", "zipkin", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			cfg.TracingExporter = tc.exporter
+			cfg.TracingInsecure = true
+
+			shutdown, err := Initialize(context.Background(), cfg)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, shutdown(context.Background()))
+		})
+	}
+}
+
+func TestInjectOutgoing(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	ctx, span := Start(context.Background(), "parent")
+	defer span.End()
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-real-ip", "127.0.0.1")
+
+	ctx = InjectOutgoing(ctx)
+	md, ok := metadata.FromOutgoingContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, []string{"127.0.0.1"}, md.Get("x-real-ip"))
+	require.Len(t, md.Get("traceparent"), 1)
+
+	// принимающая сторона восстанавливает контекст трассировки из метаданных
+	remote := trace.SpanContextFromContext(Extract(context.Background(), MetadataCarrier(md)))
+	assert.Equal(t, span.SpanContext().TraceID(), remote.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), remote.SpanID())
+	assert.True(t, remote.IsRemote())
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/denmor86/go-url-shortener/internal/storage"
+)
+
+// ErrUniqueViolation - пользовательская ошибка "URL уже существует"
+var ErrUniqueViolation = errors.New("URL already exist")
+
+// ErrDeletedViolation - пользовательская ошибка "URL удален"
+var ErrDeletedViolation = errors.New("URL is deleted")
+
+// ErrNotFound - пользовательская ошибка "URL не найден"
+var ErrNotFound = errors.New("URL not found")
+
+// ErrInvalidArgument - пользовательская ошибка "некорректные данные запроса"
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// ErrTooLarge - пользовательская ошибка "слишком большой запрос"
+var ErrTooLarge = errors.New("request too large")
+
+// ErrUnavailable - пользовательская ошибка "хранилище недоступно"
+var ErrUnavailable = errors.New("storage unavailable")
+
+// ErrQuotaExceeded - пользовательская ошибка "превышена квота пользователя"
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// ErrInternal - пользовательская ошибка "внутренняя ошибка"
+var ErrInternal = errors.New("internal error")
+
+// Error - модель ошибки бизнес логики. Вид ошибки определяется через errors.Is(err, ErrNotFound) и т.д.
+type Error struct {
+	Kind     error  // вид ошибки (ErrNotFound, ErrUniqueViolation и т.д.)
+	Message  string // сообщение с ошибкой
+	ShortURL string // короткая ссылка, к которой относится ошибка
+	Err      error  // исходная ошибка
+}
+
+// Error - метод получения текста ошибки
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is - метод сравнения вида ошибки
+func (e *Error) Is(target error) bool {
+	return e.Kind == target
+}
+
+// Unwrap - метод получения исходной ошибки
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// newError - метод формирования ошибки бизнес логики
+func newError(kind error, shortURL string, err error, format string, args ...any) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...), ShortURL: shortURL, Err: err}
+}
+
+// storageError - метод формирования ошибки работы с хранилищем (недоступность или внутренняя ошибка)
+func storageError(err error, format string, args ...any) *Error {
+	kind := ErrInternal
+	if storage.IsUnavailable(err) {
+		kind = ErrUnavailable
+	}
+	return newError(kind, "", err, "%s: %s", fmt.Sprintf(format, args...), err.Error())
+}
@@ -2,8 +2,13 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"io"
+	"strconv"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	"github.com/denmor86/go-url-shortener/internal/config"
@@ -12,6 +17,9 @@ import (
 	"github.com/denmor86/go-url-shortener/internal/workerpool"
 )
 
+// streamWindowSize - количество принятых, но еще не обработанных сообщений потока
+const streamWindowSize = 64
+
 // UsecaseGRPC - модель основной бизнес логики для GRPC
 type UsecaseGRPC struct {
 	pb.UnimplementedShortenerServer
@@ -20,7 +28,7 @@ type UsecaseGRPC struct {
 
 // NewUsecaseGRPC - метод создания объекта бизнес логики для GRPC запросов
 func NewUsecaseGRPC(cfg *config.Config, storage storage.IStorage, workerpool *workerpool.WorkerPool) *UsecaseGRPC {
-	return &UsecaseGRPC{use: &Usecase{Config: cfg, Storage: storage, WorkerPool: workerpool}}
+	return &UsecaseGRPC{use: NewUsecase(cfg, storage, workerpool)}
 }
 
 // DecodeURL - метод получения оригинального URL по короткой ссылке на основе proto запроса
@@ -31,7 +39,7 @@ func (u *UsecaseGRPC) DecodeURL(ctx context.Context, in *pb.DecodeURLRequest) (*
 
 	URL, err := u.use.DecodeURL(ctx, in.GetUrl())
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "error decode url")
+		return nil, statusError(err)
 	}
 
 	response := &pb.DecodeURLResponse{
@@ -47,9 +55,11 @@ func (u *UsecaseGRPC) EncodeURL(ctx context.Context, in *pb.EncodeURLRequest) (*
 		return nil, status.Error(codes.InvalidArgument, "invalid url")
 	}
 
+	ctx, quota := WithQuotaReport(ctx)
 	shortURL, err := u.use.EncodeURL(ctx, in.GetUrl(), in.GetUserId())
+	setQuotaHeader(ctx, quota)
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "error encode url")
+		return nil, statusError(err)
 	}
 
 	response := &pb.EncodeURLResponse{
@@ -65,10 +75,16 @@ func (u *UsecaseGRPC) EncodeURLs(ctx context.Context, in *pb.EncodeURLsRequest)
 		return nil, status.Error(codes.InvalidArgument, "invalid urls")
 	}
 	requestItems := make([]RequestItem, 0, len(in.GetUrls()))
+	for i, url := range in.GetUrls() {
+		// идентификатор ссылки - порядковый номер в запросе
+		requestItems = append(requestItems, RequestItem{ID: strconv.Itoa(i), URL: url})
+	}
+	ctx, quota := WithQuotaReport(ctx)
 	responseItems, err := u.use.EncodeURLBatch(ctx, requestItems, in.GetUserId())
+	setQuotaHeader(ctx, quota)
 
 	if err != nil {
-		return nil, err
+		return nil, statusError(err)
 	}
 
 	results := make([]*pb.ShortURL, 0, len(responseItems))
@@ -91,7 +107,7 @@ func (u *UsecaseGRPC) GetURLs(ctx context.Context, in *pb.GetURLsRequest) (*pb.G
 	responseItems, err := u.use.GetURLs(ctx, in.GetUserId())
 
 	if err != nil {
-		return nil, err
+		return nil, statusError(err)
 	}
 
 	results := make([]*pb.URL, 0, len(responseItems))
@@ -127,3 +143,127 @@ func (u *UsecaseGRPC) GetStatistic(ctx context.Context, in *pb.StatisticRequest)
 	}
 	return response, nil
 }
+
+// EncodeURLStream - метод потокового формирования коротких ссылок (двунаправленный поток).
+// Ссылки сокращаются по мере поступления, ответ на каждую ссылку отправляется отдельным сообщением.
+// Ошибки отдельных ссылок (некорректный URL, URL уже существует) передаются в ответе, не прерывая поток.
+func (u *UsecaseGRPC) EncodeURLStream(stream pb.Shortener_EncodeURLStreamServer) error {
+	ctx := stream.Context()
+
+	// ограниченный буфер принятых запросов: при его заполнении чтение из потока приостанавливается,
+	// и клиент блокируется механизмом управления потоком GRPC (HTTP/2 flow control)
+	requests := make(chan *pb.EncodeURLStreamRequest, streamWindowSize)
+	recvErr := make(chan error, 1)
+	go func() {
+		defer close(requests)
+		for {
+			in, err := stream.Recv()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					recvErr <- err
+				}
+				return
+			}
+			select {
+			case requests <- in:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for in := range requests {
+		response := &pb.EncodeURLStreamResponse{Id: in.GetId()}
+		shortURL, err := u.use.EncodeURL(ctx, in.GetUrl(), in.GetUserId())
+		switch {
+		case err == nil:
+			response.Url = shortURL
+		case errors.Is(err, ErrUniqueViolation):
+			response.Url = shortURL
+			response.Error = err.Error()
+		case errors.Is(err, ErrInvalidArgument), errors.Is(err, ErrQuotaExceeded):
+			response.Error = err.Error()
+		case ctx.Err() != nil:
+			return status.FromContextError(ctx.Err()).Err()
+		default:
+			return statusError(err)
+		}
+		if err := stream.Send(response); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case err := <-recvErr:
+		return err
+	default:
+	}
+	if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return nil
+}
+
+// ListURLs - метод постраничной передачи записей URL пользователя (поток от сервера).
+// Каждое сообщение содержит страницу записей и курсор для продолжения чтения.
+func (u *UsecaseGRPC) ListURLs(in *pb.ListURLsRequest, stream pb.Shortener_ListURLsServer) error {
+	ctx := stream.Context()
+	cursor := in.GetCursor()
+	for {
+		responseItems, next, err := u.use.ListURLs(ctx, in.GetUserId(), cursor, int(in.GetPageSize()))
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		if err != nil {
+			return statusError(err)
+		}
+		if len(responseItems) == 0 {
+			return nil
+		}
+
+		results := make([]*pb.URL, 0, len(responseItems))
+		for _, url := range responseItems {
+			results = append(results, &pb.URL{
+				Shorten:  url.ShortURL,
+				Original: url.OriginalURL,
+			})
+		}
+		if err := stream.Send(&pb.ListURLsResponse{Results: results, Cursor: next}); err != nil {
+			return err
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// GetQuota - метод получения сведений о квоте пользователя на основе proto запроса
+func (u *UsecaseGRPC) GetQuota(ctx context.Context, in *pb.GetQuotaRequest) (*pb.GetQuotaResponse, error) {
+	quota, err := u.use.GetQuota(ctx, in.GetUserId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	response := &pb.GetQuotaResponse{
+		DailyLimit: int32(quota.DailyLimit),
+		DailyUsed:  int32(quota.DailyUsed),
+		TotalLimit: int32(quota.TotalLimit),
+		TotalUsed:  int32(quota.TotalUsed),
+	}
+	if !quota.ResetAt.IsZero() {
+		response.ResetAt = quota.ResetAt.Unix()
+	}
+	return response, nil
+}
+
+// setQuotaHeader - метод передачи сведений о квоте пользователя в заголовках GRPC ответа
+func setQuotaHeader(ctx context.Context, quota *Quota) {
+	if headers := quota.Headers(); len(headers) > 0 {
+		_ = grpc.SetHeader(ctx, metadata.New(headers))
+	}
+}
+
+// PingStorage - метод определения состояния соединения с хранилищем (БД, файл, ОП)
+func (u *UsecaseGRPC) PingStorage(ctx context.Context) error {
+	return u.use.PingStorage(ctx)
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Параметры детализации GRPC ошибок
+const (
+	// ErrorDomain - домен ошибок сервиса (errdetails.ErrorInfo)
+	ErrorDomain = "shortener"
+	// ShortURLMetadataKey - ключ короткой ссылки в метаданных ошибки (errdetails.ErrorInfo)
+	ShortURLMetadataKey = "short_url"
+	// resourceTypeURL - тип ресурса короткой ссылки (errdetails.ResourceInfo)
+	resourceTypeURL = "url"
+	// retryDelay - рекомендуемая задержка повтора запроса при недоступности хранилища
+	retryDelay = time.Second
+)
+
+// Причины ошибок (errdetails.ErrorInfo)
+const (
+	ReasonInvalidArgument = "INVALID_ARGUMENT"
+	ReasonNotFound        = "URL_NOT_FOUND"
+	ReasonDeleted         = "URL_DELETED"
+	ReasonAlreadyExists   = "URL_ALREADY_EXISTS"
+	ReasonQuotaExceeded   = "QUOTA_EXCEEDED"
+	ReasonTooLarge        = "REQUEST_TOO_LARGE"
+	ReasonUnavailable     = "STORAGE_UNAVAILABLE"
+	ReasonInternal        = "INTERNAL"
+)
+
+// statusError - метод преобразования ошибки бизнес логики в GRPC статус с детализацией
+func statusError(err error) error {
+	var useErr *Error
+	if !errors.As(err, &useErr) {
+		useErr = &Error{Kind: ErrInternal, Message: err.Error(), Err: err}
+	}
+
+	switch {
+	case errors.Is(useErr, ErrInvalidArgument):
+		return withDetails(codes.InvalidArgument, useErr.Message, errorInfo(ReasonInvalidArgument, ""),
+			&errdetails.BadRequest{
+				FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "url", Description: useErr.Message}},
+			})
+	case errors.Is(useErr, ErrNotFound):
+		return withDetails(codes.NotFound, useErr.Message, errorInfo(ReasonNotFound, useErr.ShortURL),
+			&errdetails.ResourceInfo{ResourceType: resourceTypeURL, ResourceName: useErr.ShortURL, Description: useErr.Message})
+	case errors.Is(useErr, ErrDeletedViolation):
+		return withDetails(codes.FailedPrecondition, useErr.Message, errorInfo(ReasonDeleted, useErr.ShortURL),
+			&errdetails.PreconditionFailure{
+				Violations: []*errdetails.PreconditionFailure_Violation{{Type: ReasonDeleted, Subject: useErr.ShortURL, Description: useErr.Message}},
+			})
+	case errors.Is(useErr, ErrUniqueViolation):
+		return withDetails(codes.AlreadyExists, useErr.Message, errorInfo(ReasonAlreadyExists, useErr.ShortURL),
+			&errdetails.ResourceInfo{ResourceType: resourceTypeURL, ResourceName: useErr.ShortURL, Description: useErr.Message})
+	case errors.Is(useErr, ErrQuotaExceeded):
+		return withDetails(codes.ResourceExhausted, useErr.Message, errorInfo(ReasonQuotaExceeded, ""),
+			&errdetails.QuotaFailure{
+				Violations: []*errdetails.QuotaFailure_Violation{{Subject: "user", Description: useErr.Message}},
+			})
+	case errors.Is(useErr, ErrTooLarge):
+		return withDetails(codes.InvalidArgument, useErr.Message, errorInfo(ReasonTooLarge, ""),
+			&errdetails.BadRequest{
+				FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "urls", Description: useErr.Message}},
+			})
+	case errors.Is(useErr, ErrUnavailable):
+		return withDetails(codes.Unavailable, ErrUnavailable.Error(), errorInfo(ReasonUnavailable, ""),
+			&errdetails.RetryInfo{RetryDelay: durationpb.New(retryDelay)})
+	default:
+		// подробности внутренней ошибки клиенту не передаются
+		return withDetails(codes.Internal, ErrInternal.Error(), errorInfo(ReasonInternal, ""))
+	}
+}
+
+// errorInfo - метод формирования общей детализации ошибки
+func errorInfo(reason string, shortURL string) *errdetails.ErrorInfo {
+	info := &errdetails.ErrorInfo{Reason: reason, Domain: ErrorDomain}
+	if len(shortURL) != 0 {
+		info.Metadata = map[string]string{ShortURLMetadataKey: shortURL}
+	}
+	return info
+}
+
+// withDetails - метод формирования GRPC статуса с детализацией
+func withDetails(code codes.Code, message string, details ...protoadapt.MessageV1) error {
+	st := status.New(code, message)
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -0,0 +1,346 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/denmor86/go-url-shortener/internal/config"
+	pb "github.com/denmor86/go-url-shortener/internal/gen"
+	"github.com/denmor86/go-url-shortener/internal/storage"
+)
+
+const (
+	testBaseURL  = "http://localhost:8080"
+	testShortURL = "iFBc_bhG"
+	testUserID   = "c9c5cb66-dbbc-4d57-8cb9-55f58096f79b"
+)
+
+// stubStorage - mock-модель хранилища, возвращающая заданную ошибку
+type stubStorage struct {
+	*storage.MemStorage
+	err error
+}
+
+func (s *stubStorage) AddRecord(ctx context.Context, record storage.TableRecord) error {
+	return s.err
+}
+
+func (s *stubStorage) AddRecords(ctx context.Context, records []storage.TableRecord) error {
+	return s.err
+}
+
+func (s *stubStorage) GetRecord(ctx context.Context, shortURL string) (string, error) {
+	return "", s.err
+}
+
+func (s *stubStorage) GetUserRecords(ctx context.Context, userID string) ([]storage.TableRecord, error) {
+	return nil, s.err
+}
+
+func newTestUsecaseGRPC(store storage.IStorage) *UsecaseGRPC {
+	return NewUsecaseGRPC(&config.Config{BaseURL: testBaseURL, ShortURLLen: 8}, store, nil)
+}
+
+// detail - поиск детализации ошибки заданного типа
+func detail[T any](t *testing.T, err error) T {
+	t.Helper()
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	for _, d := range st.Details() {
+		if v, ok := d.(T); ok {
+			return v
+		}
+	}
+	var zero T
+	require.Failf(t, "detail not found", "%T", zero)
+	return zero
+}
+
+func TestUsecaseGRPC_DecodeURL(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		store := storage.NewMemStorage()
+		require.NoError(t, store.AddRecord(context.Background(), storage.TableRecord{OriginalURL: "https://google.com", ShortURL: testShortURL}))
+
+		resp, err := newTestUsecaseGRPC(store).DecodeURL(context.Background(), &pb.DecodeURLRequest{Url: testShortURL})
+		require.NoError(t, err)
+		assert.Equal(t, "https://google.com", resp.GetResult())
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := newTestUsecaseGRPC(storage.NewMemStorage()).DecodeURL(context.Background(), &pb.DecodeURLRequest{Url: testShortURL})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		info := detail[*errdetails.ErrorInfo](t, err)
+		assert.Equal(t, ReasonNotFound, info.GetReason())
+		assert.Equal(t, ErrorDomain, info.GetDomain())
+		resource := detail[*errdetails.ResourceInfo](t, err)
+		assert.Equal(t, testShortURL, resource.GetResourceName())
+	})
+
+	t.Run("Deleted", func(t *testing.T) {
+		store := &stubStorage{MemStorage: storage.NewMemStorage(), err: &storage.DeletedViolation{Message: "URL is deleted"}}
+
+		_, err := newTestUsecaseGRPC(store).DecodeURL(context.Background(), &pb.DecodeURLRequest{Url: testShortURL})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		failure := detail[*errdetails.PreconditionFailure](t, err)
+		require.Len(t, failure.GetViolations(), 1)
+		assert.Equal(t, ReasonDeleted, failure.GetViolations()[0].GetType())
+		assert.Equal(t, testShortURL, failure.GetViolations()[0].GetSubject())
+	})
+
+	t.Run("Unavailable", func(t *testing.T) {
+		store := &stubStorage{MemStorage: storage.NewMemStorage(), err: fmt.Errorf("failed to get record: %w", context.DeadlineExceeded)}
+
+		_, err := newTestUsecaseGRPC(store).DecodeURL(context.Background(), &pb.DecodeURLRequest{Url: testShortURL})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+
+		retry := detail[*errdetails.RetryInfo](t, err)
+		assert.Equal(t, retryDelay, retry.GetRetryDelay().AsDuration())
+	})
+
+	t.Run("Internal", func(t *testing.T) {
+		store := &stubStorage{MemStorage: storage.NewMemStorage(), err: errors.New("connection password is wrong")}
+
+		_, err := newTestUsecaseGRPC(store).DecodeURL(context.Background(), &pb.DecodeURLRequest{Url: testShortURL})
+		assert.Equal(t, codes.Internal, status.Code(err))
+		// подробности внутренней ошибки не передаются клиенту
+		assert.Equal(t, ErrInternal.Error(), status.Convert(err).Message())
+		assert.Equal(t, ReasonInternal, detail[*errdetails.ErrorInfo](t, err).GetReason())
+	})
+
+	t.Run("InvalidArgument", func(t *testing.T) {
+		_, err := newTestUsecaseGRPC(storage.NewMemStorage()).DecodeURL(context.Background(), &pb.DecodeURLRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUsecaseGRPC_EncodeURL(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		resp, err := newTestUsecaseGRPC(storage.NewMemStorage()).EncodeURL(context.Background(), &pb.EncodeURLRequest{Url: "https://google.com", UserId: testUserID})
+		require.NoError(t, err)
+		assert.Len(t, resp.GetResult(), len(testBaseURL)+1+8)
+	})
+
+	t.Run("AlreadyExists", func(t *testing.T) {
+		store := &stubStorage{MemStorage: storage.NewMemStorage(), err: &storage.UniqueViolation{Message: "URL already exists", ShortURL: testShortURL}}
+
+		_, err := newTestUsecaseGRPC(store).EncodeURL(context.Background(), &pb.EncodeURLRequest{Url: "https://google.com", UserId: testUserID})
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+		existURL := testBaseURL + "/" + testShortURL
+		info := detail[*errdetails.ErrorInfo](t, err)
+		assert.Equal(t, ReasonAlreadyExists, info.GetReason())
+		assert.Equal(t, existURL, info.GetMetadata()[ShortURLMetadataKey])
+		assert.Equal(t, existURL, detail[*errdetails.ResourceInfo](t, err).GetResourceName())
+	})
+
+	t.Run("QuotaExceeded", func(t *testing.T) {
+		use := NewUsecaseGRPC(&config.Config{BaseURL: testBaseURL, ShortURLLen: 8, QuotaTotal: 1}, storage.NewMemStorage(), nil)
+		_, err := use.EncodeURL(context.Background(), &pb.EncodeURLRequest{Url: "https://google.com", UserId: testUserID})
+		require.NoError(t, err)
+
+		_, err = use.EncodeURL(context.Background(), &pb.EncodeURLRequest{Url: "https://ya.ru", UserId: testUserID})
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, ReasonQuotaExceeded, detail[*errdetails.ErrorInfo](t, err).GetReason())
+		require.Len(t, detail[*errdetails.QuotaFailure](t, err).GetViolations(), 1)
+
+		quota, err := use.GetQuota(context.Background(), &pb.GetQuotaRequest{UserId: testUserID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), quota.GetTotalLimit())
+		assert.Equal(t, int32(1), quota.GetTotalUsed())
+	})
+
+	t.Run("Internal", func(t *testing.T) {
+		store := &stubStorage{MemStorage: storage.NewMemStorage(), err: errors.New("disk is full")}
+
+		_, err := newTestUsecaseGRPC(store).EncodeURL(context.Background(), &pb.EncodeURLRequest{Url: "https://google.com", UserId: testUserID})
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+}
+
+func TestUsecaseGRPC_EncodeURLs(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		urls := []string{"https://google.com", "https://ya.ru"}
+		resp, err := newTestUsecaseGRPC(storage.NewMemStorage()).EncodeURLs(context.Background(), &pb.EncodeURLsRequest{Urls: urls, UserId: testUserID})
+		require.NoError(t, err)
+		require.Len(t, resp.GetResults(), len(urls))
+		assert.Equal(t, "0", resp.GetResults()[0].GetId())
+		assert.Equal(t, "1", resp.GetResults()[1].GetId())
+	})
+
+	t.Run("InvalidArgument", func(t *testing.T) {
+		_, err := newTestUsecaseGRPC(storage.NewMemStorage()).EncodeURLs(context.Background(), &pb.EncodeURLsRequest{Urls: []string{"https://google.com", ""}, UserId: testUserID})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		request := detail[*errdetails.BadRequest](t, err)
+		require.Len(t, request.GetFieldViolations(), 1)
+		assert.Equal(t, ReasonInvalidArgument, detail[*errdetails.ErrorInfo](t, err).GetReason())
+	})
+
+	t.Run("Unavailable", func(t *testing.T) {
+		store := &stubStorage{MemStorage: storage.NewMemStorage(), err: context.DeadlineExceeded}
+
+		_, err := newTestUsecaseGRPC(store).EncodeURLs(context.Background(), &pb.EncodeURLsRequest{Urls: []string{"https://google.com"}, UserId: testUserID})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
+
+func TestUsecaseGRPC_GetURLs(t *testing.T) {
+	t.Run("Internal", func(t *testing.T) {
+		store := &stubStorage{MemStorage: storage.NewMemStorage(), err: errors.New("broken rows")}
+
+		_, err := newTestUsecaseGRPC(store).GetURLs(context.Background(), &pb.GetURLsRequest{UserId: testUserID})
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+}
+
+// newTestClient - запуск GRPC сервера в памяти и создание клиента
+func newTestClient(t *testing.T, use *UsecaseGRPC) pb.ShortenerClient {
+	t.Helper()
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	pb.RegisterShortenerServer(server, use)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewShortenerClient(conn)
+}
+
+func TestUsecaseGRPC_EncodeURLStream(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		client := newTestClient(t, newTestUsecaseGRPC(storage.NewMemStorage()))
+		stream, err := client.EncodeURLStream(context.Background())
+		require.NoError(t, err)
+
+		const count = 200
+		go func() {
+			for i := range count {
+				stream.Send(&pb.EncodeURLStreamRequest{UserId: testUserID, Id: strconv.Itoa(i), Url: fmt.Sprintf("https://example.com/%d", i)})
+			}
+			// некорректный запрос не прерывает поток
+			stream.Send(&pb.EncodeURLStreamRequest{UserId: testUserID, Id: "empty"})
+			stream.CloseSend()
+		}()
+
+		var responses []*pb.EncodeURLStreamResponse
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			responses = append(responses, resp)
+		}
+		require.Len(t, responses, count+1)
+		for i, resp := range responses[:count] {
+			assert.Equal(t, strconv.Itoa(i), resp.GetId())
+			assert.NotEmpty(t, resp.GetUrl())
+			assert.Empty(t, resp.GetError())
+		}
+		assert.Equal(t, "empty", responses[count].GetId())
+		assert.NotEmpty(t, responses[count].GetError())
+	})
+
+	t.Run("AlreadyExists", func(t *testing.T) {
+		store := &stubStorage{MemStorage: storage.NewMemStorage(), err: &storage.UniqueViolation{Message: "URL already exists", ShortURL: testShortURL}}
+		stream, err := newTestClient(t, newTestUsecaseGRPC(store)).EncodeURLStream(context.Background())
+		require.NoError(t, err)
+
+		require.NoError(t, stream.Send(&pb.EncodeURLStreamRequest{UserId: testUserID, Id: "1", Url: "https://google.com"}))
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, testBaseURL+"/"+testShortURL, resp.GetUrl())
+		assert.Equal(t, ErrUniqueViolation.Error(), resp.GetError())
+	})
+
+	t.Run("Unavailable", func(t *testing.T) {
+		store := &stubStorage{MemStorage: storage.NewMemStorage(), err: context.DeadlineExceeded}
+		stream, err := newTestClient(t, newTestUsecaseGRPC(store)).EncodeURLStream(context.Background())
+		require.NoError(t, err)
+
+		require.NoError(t, stream.Send(&pb.EncodeURLStreamRequest{UserId: testUserID, Id: "1", Url: "https://google.com"}))
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
+
+func TestUsecaseGRPC_ListURLs(t *testing.T) {
+	store := storage.NewMemStorage()
+	const count = 25
+	for i := range count {
+		require.NoError(t, store.AddRecord(context.Background(), storage.TableRecord{
+			OriginalURL: fmt.Sprintf("https://example.com/%d", i),
+			ShortURL:    fmt.Sprintf("short%03d", i),
+			UserID:      testUserID,
+		}))
+	}
+	// запись другого пользователя и удаленная запись не передаются
+	require.NoError(t, store.AddRecord(context.Background(), storage.TableRecord{OriginalURL: "https://ya.ru", ShortURL: "other", UserID: "other"}))
+	require.NoError(t, store.AddRecord(context.Background(), storage.TableRecord{OriginalURL: "https://ya.ru/deleted", ShortURL: "deleted", UserID: testUserID, IsDeleted: true}))
+
+	client := newTestClient(t, newTestUsecaseGRPC(store))
+
+	t.Run("All pages", func(t *testing.T) {
+		stream, err := client.ListURLs(context.Background(), &pb.ListURLsRequest{UserId: testUserID, PageSize: 10})
+		require.NoError(t, err)
+
+		var pages []*pb.ListURLsResponse
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			pages = append(pages, resp)
+		}
+		require.Len(t, pages, 3)
+		assert.Len(t, pages[0].GetResults(), 10)
+		assert.Equal(t, "short009", pages[0].GetCursor())
+		assert.Len(t, pages[2].GetResults(), 5)
+		assert.Empty(t, pages[2].GetCursor())
+		assert.Equal(t, testBaseURL+"/short000", pages[0].GetResults()[0].GetShorten())
+	})
+
+	t.Run("From cursor", func(t *testing.T) {
+		stream, err := client.ListURLs(context.Background(), &pb.ListURLsRequest{UserId: testUserID, Cursor: "short019"})
+		require.NoError(t, err)
+
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Len(t, resp.GetResults(), 5)
+		_, err = stream.Recv()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		stream, err := client.ListURLs(ctx, &pb.ListURLsRequest{UserId: testUserID})
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		assert.Equal(t, codes.Canceled, status.Code(err))
+	})
+}
@@ -1,14 +1,15 @@
 package usecase
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 
 	"github.com/denmor86/go-url-shortener/internal/config"
+	"github.com/denmor86/go-url-shortener/internal/logger"
 	"github.com/denmor86/go-url-shortener/internal/storage"
 	"github.com/denmor86/go-url-shortener/internal/workerpool"
 )
@@ -58,17 +59,46 @@ type ContextKey string
 // UserIDContextKey - имя ключа пользователя в передаваемом контексте
 var UserIDContextKey ContextKey = "userID"
 
+// RequestIDContextKey - имя ключа идентификатора запроса в передаваемом контексте
+var RequestIDContextKey ContextKey = "requestID"
+
+// RequestIDFromContext - метод получения идентификатора запроса из контекста
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(RequestIDContextKey).(string)
+	return id
+}
+
+// WithRequestID - метод добавления идентификатора запроса в контекст (в том числе в поля журнала)
+func WithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, RequestIDContextKey, id)
+	return logger.WithFields(ctx, "request_id", id)
+}
+
+// ClientIPContextKey - имя ключа IP адреса клиента в передаваемом контексте
+var ClientIPContextKey ContextKey = "clientIP"
+
+// ClientIPFromContext - метод получения IP адреса клиента из контекста
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(ClientIPContextKey).(string)
+	return ip
+}
+
+// WithClientIP - метод добавления IP адреса клиента в контекст
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, ClientIPContextKey, ip)
+}
+
 // NewUsecaseHTTP - метод создания объекта бизнес логики для HTTP запросов
 func NewUsecaseHTTP(cfg *config.Config, storage storage.IStorage, workerpool *workerpool.WorkerPool) *UsecaseHTTP {
-	return &UsecaseHTTP{use: &Usecase{Config: cfg, Storage: storage, WorkerPool: workerpool}}
+	return &UsecaseHTTP{use: NewUsecase(cfg, storage, workerpool)}
 }
 
 // EncodeURL - метод формирования короткой ссылки на основе тела запроса в текстовом формате
 func (u *UsecaseHTTP) EncodeURL(ctx context.Context, reader io.Reader, userID string) ([]byte, error) {
 
-	data, err := io.ReadAll(reader)
+	data, err := readBody(reader)
 	if err != nil {
-		return nil, fmt.Errorf("error read from body: %w", err)
+		return nil, err
 	}
 
 	shortURL, err := u.use.EncodeURL(ctx, string(data), userID)
@@ -79,14 +109,12 @@ func (u *UsecaseHTTP) EncodeURL(ctx context.Context, reader io.Reader, userID st
 // EncodeURLJson - метод формирования короткой ссылки на основе тела запроса в JSON формате
 func (u *UsecaseHTTP) EncodeURLJson(ctx context.Context, reader io.Reader, userID string) ([]byte, error) {
 
-	var buf bytes.Buffer
-	// читаем тело запроса
-	_, err := buf.ReadFrom(reader)
+	data, err := readBody(reader)
 	if err != nil {
-		return nil, fmt.Errorf("error read from body: %w", err)
+		return nil, err
 	}
 	var request Request
-	if err = json.Unmarshal(buf.Bytes(), &request); err != nil {
+	if err = json.Unmarshal(data, &request); err != nil {
 		return nil, fmt.Errorf("error unmarshal body: %w", err)
 	}
 
@@ -116,14 +144,12 @@ func (u *UsecaseHTTP) EncodeURLJson(ctx context.Context, reader io.Reader, userI
 // EncodeURLJsonBatch - метод формирования массива коротких ссылок на основе тела запроса в JSON формате
 func (u *UsecaseHTTP) EncodeURLJsonBatch(ctx context.Context, reader io.Reader, userID string) ([]byte, error) {
 
-	var buf bytes.Buffer
-	// читаем тело запроса
-	_, err := buf.ReadFrom(reader)
+	data, err := readBody(reader)
 	if err != nil {
-		return nil, fmt.Errorf("error read from body: %w", err)
+		return nil, err
 	}
 	var requestItems []RequestItem
-	if err = json.Unmarshal(buf.Bytes(), &requestItems); err != nil {
+	if err = json.Unmarshal(data, &requestItems); err != nil {
 		return nil, fmt.Errorf("error unmarshal body: %w", err)
 	}
 
@@ -144,6 +170,20 @@ func (u *UsecaseHTTP) EncodeURLJsonBatch(ctx context.Context, reader io.Reader,
 	return resp, nil
 }
 
+// readBody - метод чтения тела запроса. Превышение допустимого размера тела (http.MaxBytesReader)
+// возвращается как ошибка ErrTooLarge
+func readBody(reader io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(reader)
+	if err == nil {
+		return data, nil
+	}
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return nil, newError(ErrTooLarge, "", err, "request body too large: limit %d bytes", maxErr.Limit)
+	}
+	return nil, fmt.Errorf("error read from body: %w", err)
+}
+
 // DecodeURL - метод получения оригинального URL по короткой ссылке
 func (u *UsecaseHTTP) DecodeURL(ctx context.Context, shortURL string) (string, error) {
 	return u.use.DecodeURL(ctx, shortURL)
@@ -164,14 +204,12 @@ func (u *UsecaseHTTP) GetURLs(ctx context.Context, userID string) ([]byte, error
 
 // DeleteURLs - метод запроса на удаление информации об имеющихся записях URL по пользователю
 func (u *UsecaseHTTP) DeleteURLs(ctx context.Context, reader io.Reader, userID string) error {
-	var buf bytes.Buffer
-	// читаем тело запроса
-	_, err := buf.ReadFrom(reader)
+	data, err := readBody(reader)
 	if err != nil {
-		return fmt.Errorf("error read from body: %w", err)
+		return err
 	}
 	var shortURLS []string
-	if err = json.Unmarshal(buf.Bytes(), &shortURLS); err != nil {
+	if err = json.Unmarshal(data, &shortURLS); err != nil {
 		return fmt.Errorf("error unmarshal body: %w", err)
 	}
 
@@ -190,6 +228,19 @@ func (u *UsecaseHTTP) GetStatistic(ctx context.Context) ([]byte, error) {
 	return resp, nil
 }
 
+// GetQuota - метод получения сведений о квоте пользователя в JSON формате
+func (u *UsecaseHTTP) GetQuota(ctx context.Context, userID string) ([]byte, error) {
+	quota, err := u.use.GetQuota(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := json.Marshal(quota)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling: %w", err)
+	}
+	return resp, nil
+}
+
 // PingStorage - метод определения состояния соединения с хранилищем (БД, файл, ОП)
 func (u *UsecaseHTTP) PingStorage(ctx context.Context) error {
 	return u.use.PingStorage(ctx)
@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/denmor86/go-url-shortener/internal/config"
+	"github.com/denmor86/go-url-shortener/internal/logger"
+	"github.com/denmor86/go-url-shortener/internal/storage"
+)
+
+// Заголовки ответа со сведениями о квоте пользователя
+const (
+	// QuotaDailyLimitHeader - суточный лимит ссылок
+	QuotaDailyLimitHeader = "X-Quota-Daily-Limit"
+	// QuotaDailyRemainingHeader - количество ссылок, которое можно создать до конца суток
+	QuotaDailyRemainingHeader = "X-Quota-Daily-Remaining"
+	// QuotaTotalLimitHeader - общий лимит ссылок
+	QuotaTotalLimitHeader = "X-Quota-Total-Limit"
+	// QuotaTotalRemainingHeader - количество ссылок, которое еще можно создать
+	QuotaTotalRemainingHeader = "X-Quota-Total-Remaining"
+	// QuotaResetHeader - время обнуления суточного счетчика (Unix time, секунд)
+	QuotaResetHeader = "X-Quota-Reset"
+	// RetryAfterHeader - время до возможности повторить запрос, секунд
+	RetryAfterHeader = "Retry-After"
+)
+
+// quotaContextKey - имя ключа получателя сведений о квоте в передаваемом контексте
+var quotaContextKey ContextKey = "quota"
+
+// Quota - модель сведений о квоте пользователя (лимиты 0 - без ограничения)
+type Quota struct {
+	DailyLimit int           `json:"daily_limit"` // суточный лимит ссылок
+	DailyUsed  int           `json:"daily_used"`  // количество созданных за сутки ссылок
+	TotalLimit int           `json:"total_limit"` // общий лимит ссылок
+	TotalUsed  int           `json:"total_used"`  // общее количество созданных ссылок
+	ResetAt    time.Time     `json:"reset_at"`    // время обнуления суточного счетчика
+	RetryAfter time.Duration `json:"-"`           // время до возможности повторить запрос (если квота превышена)
+}
+
+// Headers - метод формирования заголовков ответа со сведениями о квоте.
+// Заголовки формируются только для заданных лимитов
+func (q *Quota) Headers() map[string]string {
+	headers := make(map[string]string)
+	if q.DailyLimit > 0 {
+		headers[QuotaDailyLimitHeader] = strconv.Itoa(q.DailyLimit)
+		headers[QuotaDailyRemainingHeader] = strconv.Itoa(max(q.DailyLimit-q.DailyUsed, 0))
+		headers[QuotaResetHeader] = strconv.FormatInt(q.ResetAt.Unix(), 10)
+	}
+	if q.TotalLimit > 0 {
+		headers[QuotaTotalLimitHeader] = strconv.Itoa(q.TotalLimit)
+		headers[QuotaTotalRemainingHeader] = strconv.Itoa(max(q.TotalLimit-q.TotalUsed, 0))
+	}
+	if q.RetryAfter > 0 {
+		headers[RetryAfterHeader] = strconv.Itoa(int(math.Ceil(q.RetryAfter.Seconds())))
+	}
+	return headers
+}
+
+// WithQuotaReport - метод добавления в контекст получателя сведений о квоте пользователя.
+// Сведения заполняются при создании ссылок и используются для заголовков ответа
+func WithQuotaReport(ctx context.Context) (context.Context, *Quota) {
+	quota := &Quota{}
+	return context.WithValue(ctx, quotaContextKey, quota), quota
+}
+
+// reportQuota - метод передачи сведений о квоте получателю из контекста
+func reportQuota(ctx context.Context, quota Quota) {
+	if report, ok := ctx.Value(quotaContextKey).(*Quota); ok {
+		*report = quota
+	}
+}
+
+// Quotas - модель лимитов квот пользователей
+type Quotas struct {
+	Default   storage.QuotaLimit            // квота по-умолчанию
+	Overrides map[string]storage.QuotaLimit // индивидуальные квоты по UUID пользователя
+	now       func() time.Time              // источник времени
+}
+
+// NewQuotas - метод формирования лимитов квот пользователей по конфигурации
+func NewQuotas(cfg *config.Config) (*Quotas, error) {
+	if cfg.QuotaDaily < 0 || cfg.QuotaTotal < 0 {
+		return nil, fmt.Errorf("invalid quota: limits must not be negative")
+	}
+	overrides, err := ParseQuotaOverrides(cfg.QuotaOverrides)
+	if err != nil {
+		return nil, err
+	}
+	return &Quotas{
+		Default:   storage.QuotaLimit{Daily: cfg.QuotaDaily, Total: cfg.QuotaTotal},
+		Overrides: overrides,
+		now:       time.Now,
+	}, nil
+}
+
+// mustQuotas - метод формирования лимитов квот пользователей, некорректная конфигурация приводит к панике.
+// Без конфигурации квоты не учитываются
+func mustQuotas(cfg *config.Config) *Quotas {
+	if cfg == nil {
+		return nil
+	}
+	quotas, err := NewQuotas(cfg)
+	if err != nil {
+		panic(fmt.Sprintf("can't create user quotas: %s ", err.Error()))
+	}
+	return quotas
+}
+
+// ParseQuotaOverrides - метод разбора индивидуальных квот в формате "UUID=суточная:общая,..." (0 - без ограничения)
+func ParseQuotaOverrides(spec string) (map[string]storage.QuotaLimit, error) {
+	overrides := make(map[string]storage.QuotaLimit)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+		userID, value, ok := strings.Cut(item, "=")
+		if !ok || len(userID) == 0 {
+			return nil, fmt.Errorf("invalid quota override %q: expected user=daily:total", item)
+		}
+		dailyValue, totalValue, ok := strings.Cut(value, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid quota override %q: expected user=daily:total", item)
+		}
+		daily, err := strconv.Atoi(dailyValue)
+		if err != nil || daily < 0 {
+			return nil, fmt.Errorf("invalid daily quota in %q: must be a non-negative integer", item)
+		}
+		total, err := strconv.Atoi(totalValue)
+		if err != nil || total < 0 {
+			return nil, fmt.Errorf("invalid total quota in %q: must be a non-negative integer", item)
+		}
+		overrides[strings.TrimSpace(userID)] = storage.QuotaLimit{Daily: daily, Total: total}
+	}
+	return overrides, nil
+}
+
+// Enabled - метод определения необходимости учёта квот (задан хотя бы один лимит)
+func (q *Quotas) Enabled() bool {
+	if q == nil {
+		return false
+	}
+	return q.Default != storage.QuotaLimit{} || len(q.Overrides) != 0
+}
+
+// Limit - метод получения лимитов квоты пользователя
+func (q *Quotas) Limit(userID string) storage.QuotaLimit {
+	if limit, ok := q.Overrides[userID]; ok {
+		return limit
+	}
+	return q.Default
+}
+
+// day - метод получения начала текущих суток (UTC)
+func (q *Quotas) day() time.Time {
+	return q.now().UTC().Truncate(24 * time.Hour)
+}
+
+// newQuota - метод формирования сведений о квоте
+func newQuota(limit storage.QuotaLimit, usage storage.QuotaUsage, day time.Time) Quota {
+	return Quota{
+		DailyLimit: limit.Daily,
+		DailyUsed:  usage.Daily,
+		TotalLimit: limit.Total,
+		TotalUsed:  usage.Total,
+		ResetAt:    day.Add(24 * time.Hour),
+	}
+}
+
+// reserveQuota - метод резервирования квоты пользователя на создание count ссылок.
+// Возвращает метод возврата квоты, если ссылки не будут созданы. Ссылки без пользователя квотой не учитываются
+func (u *Usecase) reserveQuota(ctx context.Context, userID string, count int) (func(), error) {
+	if !u.Quotas.Enabled() || len(userID) == 0 {
+		return func() {}, nil
+	}
+	limit := u.Quotas.Limit(userID)
+	day := u.Quotas.day()
+	usage, err := u.Storage.ReserveQuota(ctx, userID, day, count, limit)
+	quota := newQuota(limit, usage, day)
+
+	if errors.Is(err, storage.ErrQuotaExceeded) {
+		message := fmt.Sprintf("total quota exceeded: %d links", limit.Total)
+		// при превышении только суточной квоты запрос можно повторить в следующие сутки
+		if limit.Total == 0 || usage.Total+count <= limit.Total {
+			message = fmt.Sprintf("daily quota exceeded: %d links per day", limit.Daily)
+			quota.RetryAfter = quota.ResetAt.Sub(u.Quotas.now())
+		}
+		reportQuota(ctx, quota)
+		return nil, newError(ErrQuotaExceeded, "", err, "%s", message)
+	}
+	if err != nil {
+		return nil, storageError(err, "error reserve quota")
+	}
+	reportQuota(ctx, quota)
+
+	return func() {
+		// квота возвращается и при отмене запроса
+		if err := u.Storage.ReleaseQuota(context.WithoutCancel(ctx), userID, day, count); err != nil {
+			logger.WarnCtx(ctx, "Can't release quota:", err)
+			return
+		}
+		quota.DailyUsed = max(quota.DailyUsed-count, 0)
+		quota.TotalUsed = max(quota.TotalUsed-count, 0)
+		reportQuota(ctx, quota)
+	}, nil
+}
+
+// GetQuota - метод получения сведений о квоте пользователя
+func (u *Usecase) GetQuota(ctx context.Context, userID string) (Quota, error) {
+	if !u.Quotas.Enabled() {
+		return Quota{}, nil
+	}
+	limit := u.Quotas.Limit(userID)
+	day := u.Quotas.day()
+	usage, err := u.Storage.GetQuotaUsage(ctx, userID, day)
+	if err != nil {
+		return Quota{}, storageError(err, "error get quota usage")
+	}
+	return newQuota(limit, usage, day), nil
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/denmor86/go-url-shortener/internal/config"
+	"github.com/denmor86/go-url-shortener/internal/storage"
+)
+
+func newTestQuotaUsecase(daily int, total int, overrides string) (*Usecase, *time.Time) {
+	now := time.Date(2025, 10, 19, 22, 0, 0, 0, time.UTC)
+	cfg := config.NewDefaultConfig()
+	cfg.QuotaDaily = daily
+	cfg.QuotaTotal = total
+	cfg.QuotaOverrides = overrides
+	use := NewUsecase(cfg, storage.NewMemStorage(), nil)
+	use.Quotas.now = func() time.Time { return now }
+	return use, &now
+}
+
+func TestUsecase_EncodeURL_Quota(t *testing.T) {
+	use, now := newTestQuotaUsecase(2, 3, "")
+	ctx := context.Background()
+
+	for i := 1; i <= 2; i++ {
+		reportCtx, quota := WithQuotaReport(ctx)
+		_, err := use.EncodeURL(reportCtx, "https://ya.ru/"+strconv.Itoa(i), testUserID)
+		require.NoError(t, err)
+		assert.Equal(t, i, quota.DailyUsed)
+		assert.Equal(t, strconv.Itoa(2-i), quota.Headers()[QuotaDailyRemainingHeader])
+	}
+
+	// суточная квота исчерпана, повтор возможен в следующие сутки
+	reportCtx, quota := WithQuotaReport(ctx)
+	_, err := use.EncodeURL(reportCtx, "https://ya.ru/3", testUserID)
+	require.True(t, errors.Is(err, ErrQuotaExceeded))
+	assert.Equal(t, 2*time.Hour, quota.RetryAfter)
+	assert.Equal(t, "7200", quota.Headers()[RetryAfterHeader])
+
+	// анонимные ссылки квотой не учитываются
+	_, err = use.EncodeURL(ctx, "https://ya.ru/anonymous", "")
+	require.NoError(t, err)
+
+	// в следующие сутки суточный счетчик обнуляется, общая квота сохраняется
+	*now = now.Add(3 * time.Hour)
+	_, err = use.EncodeURL(ctx, "https://ya.ru/4", testUserID)
+	require.NoError(t, err)
+	reportCtx, quota = WithQuotaReport(ctx)
+	_, err = use.EncodeURL(reportCtx, "https://ya.ru/5", testUserID)
+	require.True(t, errors.Is(err, ErrQuotaExceeded))
+	assert.Contains(t, err.Error(), "total quota exceeded")
+	assert.Zero(t, quota.RetryAfter)
+
+	got, err := use.GetQuota(ctx, testUserID)
+	require.NoError(t, err)
+	assert.Equal(t, Quota{DailyLimit: 2, DailyUsed: 1, TotalLimit: 3, TotalUsed: 3,
+		ResetAt: time.Date(2025, 10, 21, 0, 0, 0, 0, time.UTC)}, got)
+}
+
+func TestUsecase_EncodeURLBatch_Quota(t *testing.T) {
+	use, _ := newTestQuotaUsecase(0, 2, testUserID+"=0:3")
+	ctx := context.Background()
+	items := []RequestItem{{ID: "1", URL: "https://ya.ru/1"}, {ID: "2", URL: "https://ya.ru/2"}}
+
+	// индивидуальная квота пользователя
+	_, err := use.EncodeURLBatch(ctx, items, testUserID)
+	require.NoError(t, err)
+	// пакет, превышающий остаток квоты, отклоняется целиком
+	_, err = use.EncodeURLBatch(ctx, items, testUserID)
+	require.True(t, errors.Is(err, ErrQuotaExceeded))
+
+	got, err := use.GetQuota(ctx, testUserID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.TotalUsed)
+	assert.Equal(t, 3, got.TotalLimit)
+
+	// квота по-умолчанию
+	_, err = use.EncodeURLBatch(ctx, items, "other-user")
+	require.NoError(t, err)
+}
+
+func TestParseQuotaOverrides(t *testing.T) {
+	testCases := []struct {
+		name      string
+		spec      string
+		overrides map[string]storage.QuotaLimit
+		wantErr   bool
+	}{
+		{"Several users #1 (good)", "user1=10:100, user2=0:5", map[string]storage.QuotaLimit{
+			"user1": {Daily: 10, Total: 100},
+			"user2": {Daily: 0, Total: 5},
+		}, false},
+		{"Empty spec #2 (good)", "", map[string]storage.QuotaLimit{}, false},
+		{"Without total #3 (bad)", "user1=10", nil, true},
+		{"Negative quota #4 (bad)", "user1=-1:10", nil, true},
+		{"Without user #5 (bad)", "=1:1", nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			overrides, err := ParseQuotaOverrides(tc.spec)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.overrides, overrides)
+		})
+	}
+}
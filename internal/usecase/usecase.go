@@ -4,12 +4,15 @@ package usecase
 import (
 	"context"
 	"errors"
-	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/denmor86/go-url-shortener/internal/config"
 	"github.com/denmor86/go-url-shortener/internal/helpers"
 	"github.com/denmor86/go-url-shortener/internal/logger"
 	"github.com/denmor86/go-url-shortener/internal/storage"
+	"github.com/denmor86/go-url-shortener/internal/tracing"
 	"github.com/denmor86/go-url-shortener/internal/workerpool"
 )
 
@@ -18,6 +21,7 @@ type Usecase struct {
 	Config     *config.Config         // конфигурация
 	Storage    storage.IStorage       // хранилище
 	WorkerPool *workerpool.WorkerPool // пул потоков
+	Quotas     *Quotas                // квоты пользователей (nil - без ограничения)
 }
 
 // URLDeleteJob - модель задачи на удаление записей
@@ -25,72 +29,96 @@ type URLDeleteJob struct {
 	Storage   storage.IStorage // хранилище
 	UserID    string           // UUID пользователя
 	ShortURLs []string         // массив  коротких URL
+	Origin    trace.Link       // связь с трассой запроса, поставившего задачу
+	LogFields []any            // поля журнала запроса, поставившего задачу (идентификатор запроса)
 }
 
 // Do - удаляет записи пользователя.
+// Задача выполняется асинхронно, поэтому формирует собственную трассу, связанную с трассой запроса
 func (j *URLDeleteJob) Do(ctx context.Context) {
+	ctx = logger.WithFields(ctx, j.LogFields...)
+	ctx, span := tracing.Start(ctx, "URLDeleteJob.Do", trace.WithNewRoot(), trace.WithLinks(j.Origin),
+		trace.WithAttributes(attribute.Int("shortener.records", len(j.ShortURLs))))
 	err := j.Storage.DeleteURLs(ctx, j.UserID, j.ShortURLs)
+	tracing.End(span, err)
 	if err != nil {
-		logger.Error("error delete URLs", err.Error())
+		logger.FromContext(ctx).Errorw("error delete URLs", "user_id", j.UserID, "count", len(j.ShortURLs), "error", err.Error())
 		return
 	}
-	logger.Info("URLs is deleted")
+	logger.FromContext(ctx).Infow("URLs is deleted", "user_id", j.UserID, "count", len(j.ShortURLs))
 }
 
-// ErrUniqueViolation - пользовательская ошибка "URL уже существует"
-var ErrUniqueViolation = errors.New("URL already exist")
-
-// ErrDeletedViolation - пользовательская ошибка "URL удален"
-var ErrDeletedViolation = errors.New("URL is deleted")
+// Параметры постраничного чтения записей
+const (
+	// DefaultPageSize - размер страницы по-умолчанию
+	DefaultPageSize = 100
+	// MaxPageSize - максимальный размер страницы
+	MaxPageSize = 1000
+)
 
 // NewUsecase - метод создания объекта бизнес логики
 func NewUsecase(cfg *config.Config, storage storage.IStorage, workerpool *workerpool.WorkerPool) *Usecase {
-	return &Usecase{Config: cfg, Storage: storage, WorkerPool: workerpool}
+	return &Usecase{Config: cfg, Storage: storage, WorkerPool: workerpool, Quotas: mustQuotas(cfg)}
 }
 
 // EncodeURL - метод формирования короткой ссылки на основе URL
 func (u *Usecase) EncodeURL(ctx context.Context, url string, userID string) (string, error) {
 
 	if len(url) == 0 {
-		return "", fmt.Errorf("URL is empty")
+		return "", newError(ErrInvalidArgument, "", nil, "URL is empty")
 	}
 
 	shortURL, err := helpers.MakeShortURL(url, u.Config.ShortURLLen)
 	if err != nil {
-		return "", fmt.Errorf("error make short URL: %w", err)
+		return "", newError(ErrInternal, "", err, "error make short URL: %s", err.Error())
+	}
+	release, err := u.reserveQuota(ctx, userID, 1)
+	if err != nil {
+		return "", err
 	}
 	err = u.Storage.AddRecord(ctx, storage.TableRecord{OriginalURL: url, ShortURL: shortURL, UserID: userID})
 	// нет ошибок
 	if err == nil {
 		return helpers.MakeURL(u.Config.BaseURL, shortURL), nil
 	}
-	var storageError *storage.UniqueViolation
+	// ссылка не создана, квота не расходуется
+	release()
+	var uniqueError *storage.UniqueViolation
 	// ошибка наличия не уникального URL
-	if errors.As(err, &storageError) {
-		return helpers.MakeURL(u.Config.BaseURL, storageError.ShortURL), ErrUniqueViolation
+	if errors.As(err, &uniqueError) {
+		existURL := helpers.MakeURL(u.Config.BaseURL, uniqueError.ShortURL)
+		return existURL, newError(ErrUniqueViolation, existURL, err, "%s", ErrUniqueViolation.Error())
 	}
-	return "", fmt.Errorf("error storage URL: %w", err)
+	return "", storageError(err, "error storage URL")
 }
 
 // EncodeURLBatch - метод формирования массива коротких ссылок
 func (u *Usecase) EncodeURLBatch(ctx context.Context, requestItems []RequestItem, userID string) ([]ResponseItem, error) {
+	if u.Config.MaxBatchSize > 0 && len(requestItems) > u.Config.MaxBatchSize {
+		return nil, newError(ErrTooLarge, "", nil, "too many items in batch: %d, limit %d", len(requestItems), u.Config.MaxBatchSize)
+	}
 
 	items := make([]storage.TableRecord, 0, len(requestItems))
 	responseItems := make([]ResponseItem, 0, len(requestItems))
 	for _, item := range requestItems {
 		if item.ID == "" || item.URL == "" {
-			return nil, fmt.Errorf("invalid request item: (ID: %s, URL: %s", item.ID, item.URL)
+			return nil, newError(ErrInvalidArgument, "", nil, "invalid request item: (ID: %s, URL: %s", item.ID, item.URL)
 		}
 		shortURL, makeError := helpers.MakeShortURL(item.URL, u.Config.ShortURLLen)
 		if makeError != nil {
-			return nil, fmt.Errorf("error make short URL: %w", makeError)
+			return nil, newError(ErrInternal, "", makeError, "error make short URL: %s", makeError.Error())
 		}
 		items = append(items, storage.TableRecord{ShortURL: shortURL, OriginalURL: item.URL, UserID: userID})
 		responseItems = append(responseItems, ResponseItem{ID: item.ID, URL: helpers.MakeURL(u.Config.BaseURL, shortURL)})
 	}
 
+	release, err := u.reserveQuota(ctx, userID, len(items))
+	if err != nil {
+		return nil, err
+	}
 	if err := u.Storage.AddRecords(ctx, items); err != nil {
-		return nil, fmt.Errorf("error storage urls: %w", err)
+		release()
+		return nil, storageError(err, "error storage urls")
 	}
 
 	return responseItems, nil
@@ -99,19 +127,23 @@ func (u *Usecase) EncodeURLBatch(ctx context.Context, requestItems []RequestItem
 // DecodeURL - метод получения оригинального URL по короткой ссылке
 func (u *Usecase) DecodeURL(ctx context.Context, shortURL string) (string, error) {
 	if shortURL == "" {
-		return "", fmt.Errorf("URL is empty")
+		return "", newError(ErrInvalidArgument, "", nil, "URL is empty")
 	}
 	url, err := u.Storage.GetRecord(ctx, shortURL)
 	// нет ошибок
 	if err == nil {
 		return url, nil
 	}
-	var storageError *storage.DeletedViolation
+	var deletedError *storage.DeletedViolation
 	// ошибка: URL помечен на удаление
-	if errors.As(err, &storageError) {
-		return "", ErrDeletedViolation
+	if errors.As(err, &deletedError) {
+		return "", newError(ErrDeletedViolation, shortURL, err, "%s", ErrDeletedViolation.Error())
 	}
-	return "", fmt.Errorf("error read from storage: %w", err)
+	// ошибка: URL не найден
+	if errors.Is(err, storage.ErrNotFound) {
+		return "", newError(ErrNotFound, shortURL, err, "error read from storage: %s", err.Error())
+	}
+	return "", storageError(err, "error read from storage")
 }
 
 // PingStorage - метод определения состояния соединения с хранилищем (БД, файл, ОП)
@@ -123,7 +155,7 @@ func (u *Usecase) PingStorage(ctx context.Context) error {
 func (u *Usecase) GetURLs(ctx context.Context, userID string) ([]ResponseURL, error) {
 	records, err := u.Storage.GetUserRecords(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("error get user records: %w", err)
+		return nil, storageError(err, "error get user records")
 	}
 	if len(records) == 0 {
 		return nil, nil
@@ -135,10 +167,34 @@ func (u *Usecase) GetURLs(ctx context.Context, userID string) ([]ResponseURL, er
 	return responseItems, nil
 }
 
+// ListURLs - метод получения страницы записей URL по пользователю.
+// Возвращает курсор для запроса следующей страницы (пустой, если страница последняя)
+func (u *Usecase) ListURLs(ctx context.Context, userID string, cursor string, pageSize int) ([]ResponseURL, string, error) {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	pageSize = min(pageSize, MaxPageSize)
+
+	records, err := u.Storage.GetUserRecordsPage(ctx, userID, cursor, pageSize)
+	if err != nil {
+		return nil, "", storageError(err, "error get user records page")
+	}
+	responseItems := make([]ResponseURL, 0, len(records))
+	for _, item := range records {
+		responseItems = append(responseItems, ResponseURL{OriginalURL: item.OriginalURL, ShortURL: helpers.MakeURL(u.Config.BaseURL, item.ShortURL)})
+	}
+	var next string
+	if len(records) == pageSize {
+		next = records[len(records)-1].ShortURL
+	}
+	return responseItems, next, nil
+}
+
 // DeleteURLs - метод запроса на удаление информации об имеющихся записях URL по пользователю
 func (u *Usecase) DeleteURLs(ctx context.Context, shortURLS []string, userID string) error {
 	// добавляем задачу на удаление
-	u.WorkerPool.AddJob(&URLDeleteJob{Storage: u.Storage, UserID: userID, ShortURLs: shortURLS})
+	u.WorkerPool.AddJob(&URLDeleteJob{Storage: u.Storage, UserID: userID, ShortURLs: shortURLS,
+		Origin: tracing.Link(ctx), LogFields: logger.Fields(ctx)})
 	return nil
 }
 
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/denmor86/go-url-shortener/internal/logger"
+	"github.com/denmor86/go-url-shortener/internal/storage"
+	"github.com/denmor86/go-url-shortener/internal/tracing"
+)
+
+func TestURLDeleteJob_Tracing(t *testing.T) {
+	require.NoError(t, logger.Initialize("info"))
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	store := storage.NewMemStorage()
+	require.NoError(t, store.AddRecord(context.Background(), storage.TableRecord{OriginalURL: "https://ya.ru", ShortURL: testShortURL, UserID: testUserID}))
+
+	// задача ставится в очередь в рамках запроса
+	reqCtx, reqSpan := tracing.Start(context.Background(), "request")
+	job := &URLDeleteJob{
+		Storage:   storage.NewTracingStorage(store),
+		UserID:    testUserID,
+		ShortURLs: []string{testShortURL},
+		Origin:    tracing.Link(reqCtx),
+	}
+	reqSpan.End()
+
+	job.Do(context.Background())
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	require.Contains(t, spans, "URLDeleteJob.Do")
+	require.Contains(t, spans, "storage.DeleteURLs")
+
+	jobSpan := spans["URLDeleteJob.Do"]
+	// задача формирует собственную трассу, связанную с трассой запроса
+	assert.NotEqual(t, reqSpan.SpanContext().TraceID(), jobSpan.SpanContext().TraceID())
+	require.Len(t, jobSpan.Links(), 1)
+	assert.Equal(t, reqSpan.SpanContext().SpanID(), jobSpan.Links()[0].SpanContext.SpanID())
+	// операция с хранилищем - дочерний span задачи
+	assert.Equal(t, jobSpan.SpanContext().SpanID(), spans["storage.DeleteURLs"].Parent().SpanID())
+
+	records, err := store.GetUserRecordsPage(context.Background(), testUserID, "", DefaultPageSize)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
@@ -25,6 +25,7 @@ type WorkerPool struct {
 	jobsChan     chan Job      // канал с задачами
 	doneChan     chan struct{} // канала управления
 	closed       atomic.Bool   // признак остановки пула
+	processed    atomic.Uint64 // количество обработанных задач
 	processOnce  sync.Once
 	wg           sync.WaitGroup
 }
@@ -49,6 +50,16 @@ func (wp *WorkerPool) AddJob(job Job) error {
 	return nil
 }
 
+// QueueLen - метод получения количества задач, ожидающих обработки
+func (wp *WorkerPool) QueueLen() int {
+	return len(wp.jobsChan)
+}
+
+// Processed - метод получения количества обработанных задач
+func (wp *WorkerPool) Processed() uint64 {
+	return wp.processed.Load()
+}
+
 // worker- метод управления воркером
 func (wp *WorkerPool) worker(ctx context.Context) {
 	defer wp.wg.Done()
@@ -63,6 +74,7 @@ func (wp *WorkerPool) worker(ctx context.Context) {
 			}
 
 			job.Do(ctx)
+			wp.processed.Add(1)
 		}
 	}
 }
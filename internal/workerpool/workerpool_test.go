@@ -116,3 +116,22 @@ func TestWorkerPool_Run(t *testing.T) {
 		}
 	})
 }
+
+func TestWorkerPool_Stat(t *testing.T) {
+	wp := NewWorkerPool(1)
+
+	// до запуска задачи остаются в очереди
+	require.NoError(t, wp.AddJob(&MockJob{}))
+	require.NoError(t, wp.AddJob(&MockJob{}))
+	assert.Equal(t, 2, wp.QueueLen())
+	assert.Equal(t, uint64(0), wp.Processed())
+
+	wp.Run()
+	require.Eventually(t, func() bool {
+		return wp.Processed() == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, wp.QueueLen())
+
+	require.NoError(t, wp.Close())
+	wp.Wait()
+}
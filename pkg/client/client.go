@@ -0,0 +1,98 @@
+// Package client предоставляет клиент API сервиса сокращения ссылок для других Go сервисов.
+// Клиент имеет одинаковый набор методов для HTTP и GRPC транспорта, хранит учетные данные пользователя
+// (JWT токен для HTTP, идентификатор пользователя для GRPC), повторяет идемпотентные вызовы
+// с экспоненциальной задержкой и возвращает типизированные ошибки (ErrUniqueViolation, ErrDeletedViolation и т.д.)
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+// Транспорты клиента
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+)
+
+// Client - клиент API сервиса сокращения ссылок (HTTP или GRPC)
+type Client interface {
+	// Shorten - метод сокращения ссылки. Для уже сокращенной ссылки возвращается существующая короткая ссылка
+	// и ошибка ErrUniqueViolation
+	Shorten(ctx context.Context, url string) (string, error)
+	// ShortenBatch - метод пакетного сокращения ссылок
+	ShortenBatch(ctx context.Context, items []BatchItem) ([]BatchResult, error)
+	// List - метод получения ссылок пользователя
+	List(ctx context.Context) ([]URL, error)
+	// Delete - метод удаления ссылок пользователя (удаление выполняется асинхронно)
+	Delete(ctx context.Context, ids []string) error
+	// Expand - метод получения оригинального URL по короткой ссылке
+	Expand(ctx context.Context, id string) (string, error)
+	// Stats - метод получения статистики сервиса (доступна из доверенных подсетей)
+	Stats(ctx context.Context) (Stats, error)
+	// Close - метод закрытия соединения
+	Close() error
+}
+
+// URL - сокращенная ссылка пользователя
+type URL struct {
+	ShortURL    string `json:"short_url"`    // короткий URL
+	OriginalURL string `json:"original_url"` // оригинальный URL
+}
+
+// BatchItem - элемент пакетного запроса на сокращение
+type BatchItem struct {
+	ID  string `json:"correlation_id"` // идентификатор элемента в запросе
+	URL string `json:"original_url"`   // оригинальный URL
+}
+
+// BatchResult - результат сокращения элемента пакетного запроса
+type BatchResult struct {
+	ID       string `json:"correlation_id"` // идентификатор элемента в запросе
+	ShortURL string `json:"short_url"`      // короткий URL
+}
+
+// Stats - статистика сервиса
+type Stats struct {
+	URLs  int `json:"urls"`  // количество сокращенных URL
+	Users int `json:"users"` // количество пользователей
+}
+
+// Options - параметры клиента
+type Options struct {
+	TLSConfig *tls.Config     // настройки TLS (nil - настройки по-умолчанию для https, без TLS для GRPC)
+	Store     CredentialStore // хранилище учетных данных пользователя (nil - в памяти)
+	Retry     RetryPolicy     // политика повторов (нулевые значения заменяются значениями по-умолчанию)
+}
+
+// New - метод создания клиента с транспортом transport (http или grpc) по адресу сервиса:
+// базовый URL для HTTP, host:port для GRPC
+func New(transport, addr string, opts Options) (Client, error) {
+	switch transport {
+	case TransportHTTP:
+		return NewHTTPClient(addr, opts)
+	case TransportGRPC:
+		return NewGRPCClient(addr, opts)
+	default:
+		return nil, fmt.Errorf("unknown transport %q (http, grpc)", transport)
+	}
+}
+
+// store - метод получения хранилища учетных данных
+func (o Options) store() CredentialStore {
+	if o.Store == nil {
+		return &MemoryStore{}
+	}
+	return o.Store
+}
+
+// ShortID - метод получения идентификатора короткой ссылки (допускается полный короткий URL)
+func ShortID(value string) string {
+	value = strings.TrimRight(strings.TrimSpace(value), "/")
+	if i := strings.LastIndex(value, "/"); i >= 0 {
+		return value[i+1:]
+	}
+	return value
+}
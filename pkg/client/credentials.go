@@ -0,0 +1,63 @@
+package client
+
+import (
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Credentials - учетные данные пользователя сервиса
+type Credentials struct {
+	Token  string // JWT токен пользователя (cookie user-token), используется HTTP клиентом
+	UserID string // идентификатор пользователя, используется GRPC клиентом
+}
+
+// CredentialStore - хранилище учетных данных пользователя. Клиент читает учетные данные перед каждым запросом
+// и сохраняет выданные сервисом (новый токен или сформированный идентификатор пользователя)
+type CredentialStore interface {
+	// Credentials - метод получения учетных данных
+	Credentials() Credentials
+	// SetCredentials - метод сохранения учетных данных
+	SetCredentials(creds Credentials)
+}
+
+// MemoryStore - хранилище учетных данных в памяти, безопасно для одновременного использования
+type MemoryStore struct {
+	mu    sync.Mutex
+	creds Credentials
+}
+
+// NewMemoryStore - метод создания хранилища учетных данных в памяти с начальными значениями creds
+func NewMemoryStore(creds Credentials) *MemoryStore {
+	return &MemoryStore{creds: creds}
+}
+
+// Credentials - метод получения учетных данных
+func (s *MemoryStore) Credentials() Credentials {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.creds
+}
+
+// SetCredentials - метод сохранения учетных данных
+func (s *MemoryStore) SetCredentials(creds Credentials) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.creds = creds
+}
+
+// tokenClaims - записи JWT токена пользователя
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	UserID string
+}
+
+// userIDFromToken - метод получения идентификатора пользователя из токена.
+// Подпись токена проверяется сервисом, клиенту нужен только идентификатор
+func userIDFromToken(token string) string {
+	claims := &tokenClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return ""
+	}
+	return claims.UserID
+}
@@ -0,0 +1,195 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ErrUniqueViolation - ошибка "URL уже существует", короткая ссылка передается в Error.ShortURL
+var ErrUniqueViolation = errors.New("URL already exist")
+
+// ErrDeletedViolation - ошибка "URL удален"
+var ErrDeletedViolation = errors.New("URL is deleted")
+
+// ErrNotFound - ошибка "URL не найден"
+var ErrNotFound = errors.New("URL not found")
+
+// ErrInvalidArgument - ошибка "некорректные данные запроса"
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// ErrTooLarge - ошибка "слишком большой запрос"
+var ErrTooLarge = errors.New("request too large")
+
+// ErrQuotaExceeded - ошибка "превышена квота пользователя"
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// ErrRateLimited - ошибка "превышена частота запросов", рекомендуемая задержка в Error.RetryAfter
+var ErrRateLimited = errors.New("too many requests")
+
+// ErrInProgress - ошибка "запрос с тем же ключом идемпотентности еще выполняется"
+var ErrInProgress = errors.New("request in progress")
+
+// ErrForbidden - ошибка "доступ запрещен" (например, статистика вне доверенной подсети)
+var ErrForbidden = errors.New("forbidden")
+
+// ErrUnavailable - ошибка "сервис недоступен"
+var ErrUnavailable = errors.New("service unavailable")
+
+// ErrInternal - ошибка "внутренняя ошибка" (в т.ч. неожиданный ответ сервиса)
+var ErrInternal = errors.New("internal error")
+
+// Error - модель ошибки сервиса. Вид ошибки определяется через errors.Is(err, ErrNotFound) и т.д.
+type Error struct {
+	Kind       error         // вид ошибки (ErrNotFound, ErrUniqueViolation и т.д.)
+	Message    string        // сообщение с ошибкой
+	ShortURL   string        // короткая ссылка, к которой относится ошибка
+	RetryAfter time.Duration // рекомендуемая сервисом задержка повтора запроса
+}
+
+// Error - метод получения текста ошибки
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is - метод сравнения вида ошибки
+func (e *Error) Is(target error) bool {
+	return e.Kind == target
+}
+
+// Причины ошибок GRPC (errdetails.ErrorInfo), ключи метаданных и заголовки ответов сервиса
+const (
+	reasonInvalidArgument = "INVALID_ARGUMENT"
+	reasonNotFound        = "URL_NOT_FOUND"
+	reasonDeleted         = "URL_DELETED"
+	reasonAlreadyExists   = "URL_ALREADY_EXISTS"
+	reasonQuotaExceeded   = "QUOTA_EXCEEDED"
+	reasonTooLarge        = "REQUEST_TOO_LARGE"
+	reasonUnavailable     = "STORAGE_UNAVAILABLE"
+
+	shortURLMetadataKey   = "short_url"
+	retryAfterMetadataKey = "retry-after"
+
+	quotaDailyLimitHeader = "X-Quota-Daily-Limit"
+	quotaTotalLimitHeader = "X-Quota-Total-Limit"
+)
+
+// httpStatusKinds - виды ошибок по HTTP статусу ответа
+var httpStatusKinds = map[int]error{
+	http.StatusBadRequest:            ErrInvalidArgument,
+	http.StatusForbidden:             ErrForbidden,
+	http.StatusNotFound:              ErrNotFound,
+	http.StatusConflict:              ErrInProgress,
+	http.StatusGone:                  ErrDeletedViolation,
+	http.StatusRequestEntityTooLarge: ErrTooLarge,
+	http.StatusUnprocessableEntity:   ErrInvalidArgument,
+	http.StatusBadGateway:            ErrUnavailable,
+	http.StatusServiceUnavailable:    ErrUnavailable,
+	http.StatusGatewayTimeout:        ErrUnavailable,
+}
+
+// httpError - метод формирования ошибки по ответу с неожиданным статусом
+func httpError(resp *http.Response) *Error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	message := fmt.Sprintf("%s %s: %s", resp.Request.Method, resp.Request.URL.Path, resp.Status)
+	if text := strings.TrimSpace(string(body)); len(text) != 0 {
+		message += ": " + text
+	}
+	err := &Error{Kind: ErrInternal, Message: message, RetryAfter: retryAfter(resp.Header.Get("Retry-After"))}
+	if kind, ok := httpStatusKinds[resp.StatusCode]; ok {
+		err.Kind = kind
+	}
+	// 429 отправляется при превышении частоты запросов (с заголовком Retry-After) и квоты пользователя
+	// (с заголовками X-Quota-*, а для суточной квоты и с Retry-After до ее обнуления)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		err.Kind = ErrRateLimited
+		if err.RetryAfter == 0 || quotaExceeded(resp.Header, body) {
+			err.Kind = ErrQuotaExceeded
+		}
+	}
+	return err
+}
+
+// quotaExceeded - метод проверки превышения квоты пользователя по заголовкам X-Quota-* или тексту ответа
+func quotaExceeded(header http.Header, body []byte) bool {
+	if len(header.Get(quotaDailyLimitHeader)) != 0 || len(header.Get(quotaTotalLimitHeader)) != 0 {
+		return true
+	}
+	return bytes.Contains(bytes.ToLower(body), []byte("quota exceeded"))
+}
+
+// grpcReasonKinds - виды ошибок по причине ошибки GRPC (errdetails.ErrorInfo)
+var grpcReasonKinds = map[string]error{
+	reasonInvalidArgument: ErrInvalidArgument,
+	reasonNotFound:        ErrNotFound,
+	reasonDeleted:         ErrDeletedViolation,
+	reasonAlreadyExists:   ErrUniqueViolation,
+	reasonQuotaExceeded:   ErrQuotaExceeded,
+	reasonTooLarge:        ErrTooLarge,
+	reasonUnavailable:     ErrUnavailable,
+}
+
+// grpcCodeKinds - виды ошибок по коду GRPC статуса (если причина ошибки не передана)
+var grpcCodeKinds = map[codes.Code]error{
+	codes.InvalidArgument:    ErrInvalidArgument,
+	codes.NotFound:           ErrNotFound,
+	codes.AlreadyExists:      ErrUniqueViolation,
+	codes.FailedPrecondition: ErrInvalidArgument,
+	codes.Aborted:            ErrInProgress,
+	codes.PermissionDenied:   ErrForbidden,
+	codes.Unauthenticated:    ErrForbidden,
+	codes.Unavailable:        ErrUnavailable,
+}
+
+// grpcError - метод преобразования ошибки GRPC вызова в ошибку сервиса с учетом детализации и заголовков ответа.
+// Ошибки отмены и истечения времени вызова возвращаются без изменений
+func grpcError(err error, header metadata.MD) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() == codes.Canceled || st.Code() == codes.DeadlineExceeded {
+		return err
+	}
+	result := &Error{Kind: ErrInternal, Message: st.Message()}
+	if kind, ok := grpcCodeKinds[st.Code()]; ok {
+		result.Kind = kind
+	}
+	if st.Code() == codes.ResourceExhausted {
+		result.Kind = ErrQuotaExceeded
+		if values := header.Get(retryAfterMetadataKey); len(values) != 0 {
+			result.Kind = ErrRateLimited
+			result.RetryAfter = retryAfter(values[0])
+		}
+	}
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if kind, ok := grpcReasonKinds[detail.GetReason()]; ok {
+				result.Kind = kind
+			}
+			result.ShortURL = detail.GetMetadata()[shortURLMetadataKey]
+		case *errdetails.QuotaFailure:
+			result.Kind = ErrQuotaExceeded
+		case *errdetails.RetryInfo:
+			result.RetryAfter = detail.GetRetryDelay().AsDuration()
+		}
+	}
+	return result
+}
+
+// retryAfter - метод разбора задержки повтора запроса (целое число секунд)
+func retryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,174 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/denmor86/go-url-shortener/internal/gen"
+)
+
+// idempotencyMetadataKey - ключ идемпотентности в метаданных GRPC запроса
+const idempotencyMetadataKey = "idempotency-key"
+
+// GRPCClient - клиент GRPC API сервиса. Идентификатор пользователя берется из хранилища учетных данных
+// (если он не задан, формируется новый и сохраняется в хранилище)
+type GRPCClient struct {
+	conn   *grpc.ClientConn
+	client pb.ShortenerClient
+	store  CredentialStore
+	retry  RetryPolicy
+	mu     sync.Mutex // формирование идентификатора пользователя
+}
+
+// NewGRPCClient - метод создания клиента GRPC API по адресу host:port (без TLS, если TLSConfig не задан)
+func NewGRPCClient(addr string, opts Options) (*GRPCClient, error) {
+	creds := insecure.NewCredentials()
+	if opts.TLSConfig != nil {
+		creds = credentials.NewTLS(opts.TLSConfig)
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	return &GRPCClient{conn: conn, client: pb.NewShortenerClient(conn), store: opts.store(), retry: opts.Retry}, nil
+}
+
+// Shorten - метод сокращения ссылки
+func (c *GRPCClient) Shorten(ctx context.Context, longURL string) (string, error) {
+	var response *pb.EncodeURLResponse
+	err := c.call(withIdempotencyKey(ctx), func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		response, err = c.client.EncodeURL(ctx, &pb.EncodeURLRequest{UserId: c.userID(), Url: longURL}, opts...)
+		return err
+	})
+	if err != nil {
+		// ссылка уже сокращена, существующая короткая ссылка передается в детализации ошибки
+		var serviceErr *Error
+		if errors.As(err, &serviceErr) && errors.Is(err, ErrUniqueViolation) {
+			return serviceErr.ShortURL, err
+		}
+		return "", err
+	}
+	return response.GetResult(), nil
+}
+
+// ShortenBatch - метод пакетного сокращения ссылок
+func (c *GRPCClient) ShortenBatch(ctx context.Context, items []BatchItem) ([]BatchResult, error) {
+	request := &pb.EncodeURLsRequest{UserId: c.userID()}
+	for _, item := range items {
+		request.Urls = append(request.Urls, item.URL)
+	}
+	var response *pb.EncodeURLsResponse
+	err := c.call(withIdempotencyKey(ctx), func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		response, err = c.client.EncodeURLs(ctx, request, opts...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	// идентификаторы элементов не передаются по GRPC, результаты следуют в порядке запроса
+	results := make([]BatchResult, 0, len(response.GetResults()))
+	for i, item := range response.GetResults() {
+		result := BatchResult{ID: item.GetId(), ShortURL: item.GetUrl()}
+		if i < len(items) {
+			result.ID = items[i].ID
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// List - метод получения ссылок пользователя
+func (c *GRPCClient) List(ctx context.Context) ([]URL, error) {
+	var response *pb.GetURLsResponse
+	err := c.call(ctx, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		response, err = c.client.GetURLs(ctx, &pb.GetURLsRequest{UserId: c.userID()}, opts...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]URL, 0, len(response.GetResults()))
+	for _, item := range response.GetResults() {
+		results = append(results, URL{ShortURL: item.GetShorten(), OriginalURL: item.GetOriginal()})
+	}
+	return results, nil
+}
+
+// Delete - метод удаления ссылок пользователя
+func (c *GRPCClient) Delete(ctx context.Context, ids []string) error {
+	request := &pb.DeleteURLsRequest{UserId: c.userID()}
+	for _, id := range ids {
+		request.Urls = append(request.Urls, ShortID(id))
+	}
+	return c.call(ctx, func(ctx context.Context, opts ...grpc.CallOption) error {
+		_, err := c.client.DeleteURLs(ctx, request, opts...)
+		return err
+	})
+}
+
+// Expand - метод получения оригинального URL по короткой ссылке
+func (c *GRPCClient) Expand(ctx context.Context, id string) (string, error) {
+	var response *pb.DecodeURLResponse
+	err := c.call(ctx, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		response, err = c.client.DecodeURL(ctx, &pb.DecodeURLRequest{Url: ShortID(id)}, opts...)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return response.GetResult(), nil
+}
+
+// Stats - метод получения статистики сервиса
+func (c *GRPCClient) Stats(ctx context.Context) (Stats, error) {
+	var response *pb.StatisticResponse
+	err := c.call(ctx, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		response, err = c.client.GetStatistic(ctx, &pb.StatisticRequest{UserId: c.userID()}, opts...)
+		return err
+	})
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{URLs: int(response.GetUrls()), Users: int(response.GetUsers())}, nil
+}
+
+// Close - метод закрытия соединения
+func (c *GRPCClient) Close() error {
+	return c.conn.Close()
+}
+
+// call - метод выполнения вызова с повторами, ошибка вызова преобразуется в ошибку сервиса
+// с учетом заголовков ответа (retry-after)
+func (c *GRPCClient) call(ctx context.Context, invoke func(ctx context.Context, opts ...grpc.CallOption) error) error {
+	return c.retry.do(ctx, func() error {
+		var header metadata.MD
+		if err := invoke(ctx, grpc.Header(&header)); err != nil {
+			return grpcError(err, header)
+		}
+		return nil
+	})
+}
+
+// userID - метод получения идентификатора пользователя из хранилища учетных данных
+func (c *GRPCClient) userID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	creds := c.store.Credentials()
+	if len(creds.UserID) == 0 {
+		creds.UserID = uuid.New().String()
+		c.store.SetCredentials(creds)
+	}
+	return creds.UserID
+}
+
+// withIdempotencyKey - метод добавления в метаданные запроса ключа идемпотентности, одинакового для всех попыток
+func withIdempotencyKey(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, idempotencyMetadataKey, uuid.New().String())
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	pb "github.com/denmor86/go-url-shortener/internal/gen"
+)
+
+// testShortener - тестовый GRPC сервис с ошибками в формате сервиса сокращения ссылок
+type testShortener struct {
+	pb.UnimplementedShortenerServer
+	decodeCalls int    // количество вызовов DecodeURL
+	userID      string // идентификатор пользователя последнего вызова
+}
+
+func (s *testShortener) EncodeURL(ctx context.Context, in *pb.EncodeURLRequest) (*pb.EncodeURLResponse, error) {
+	s.userID = in.GetUserId()
+	st, _ := status.New(codes.AlreadyExists, "URL already exist").WithDetails(&errdetails.ErrorInfo{
+		Reason:   reasonAlreadyExists,
+		Metadata: map[string]string{shortURLMetadataKey: "http://short/abc"},
+	})
+	return nil, st.Err()
+}
+
+func (s *testShortener) DecodeURL(ctx context.Context, in *pb.DecodeURLRequest) (*pb.DecodeURLResponse, error) {
+	s.decodeCalls++
+	switch in.GetUrl() {
+	case "abc":
+		return &pb.DecodeURLResponse{Result: "https://example.com/a"}, nil
+	case "deleted":
+		st, _ := status.New(codes.FailedPrecondition, "URL is deleted").WithDetails(&errdetails.ErrorInfo{Reason: reasonDeleted})
+		return nil, st.Err()
+	case "quota":
+		// превышение суточной квоты передается без причины ошибки, с задержкой до обнуления квоты
+		grpc.SetHeader(ctx, metadata.Pairs(retryAfterMetadataKey, "3600"))
+		st, _ := status.New(codes.ResourceExhausted, "daily quota exceeded").WithDetails(
+			&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{Subject: "user"}}},
+			&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Hour)})
+		return nil, st.Err()
+	default:
+		st, _ := status.New(codes.Unavailable, "storage unavailable").WithDetails(
+			&errdetails.ErrorInfo{Reason: reasonUnavailable}, &errdetails.RetryInfo{RetryDelay: durationpb.New(time.Millisecond)})
+		return nil, st.Err()
+	}
+}
+
+func newTestShortener(t *testing.T) (*testShortener, *GRPCClient, *MemoryStore) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	service := &testShortener{}
+	server := grpc.NewServer()
+	pb.RegisterShortenerServer(server, service)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	store := NewMemoryStore(Credentials{})
+	client, err := NewGRPCClient(listener.Addr().String(), Options{Store: store, Retry: RetryPolicy{MinWait: time.Millisecond, MaxWait: time.Millisecond}})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return service, client, store
+}
+
+func TestGRPCClient_Shorten(t *testing.T) {
+	service, client, store := newTestShortener(t)
+
+	shortURL, err := client.Shorten(context.Background(), "https://example.com/a")
+	require.ErrorIs(t, err, ErrUniqueViolation)
+	assert.Equal(t, "http://short/abc", shortURL)
+	// идентификатор пользователя сформирован и сохранен в хранилище
+	assert.NotEmpty(t, store.Credentials().UserID)
+	assert.Equal(t, store.Credentials().UserID, service.userID)
+}
+
+func TestGRPCClient_Expand(t *testing.T) {
+	service, client, _ := newTestShortener(t)
+
+	testCases := []struct {
+		name     string
+		id       string
+		expected string
+		kind     error
+		calls    int
+	}{
+		{"Expand #1 (good)", "http://short/abc", "https://example.com/a", nil, 1},
+		{"Deleted #2 (bad)", "deleted", "", ErrDeletedViolation, 1},
+		{"Unavailable with retries #3 (bad)", "other", "", ErrUnavailable, DefaultMaxAttempts},
+		{"Quota exceeded without retries #4 (bad)", "quota", "", ErrQuotaExceeded, 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service.decodeCalls = 0
+			result, err := client.Expand(context.Background(), tc.id)
+			assert.Equal(t, tc.calls, service.decodeCalls)
+			if tc.kind != nil {
+				require.ErrorIs(t, err, tc.kind)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+)
+
+// Параметры HTTP API сервиса
+const (
+	// tokenCookie - имя cookie с JWT токеном пользователя
+	tokenCookie = "user-token"
+	// idempotencyHeader - HTTP заголовок с ключом идемпотентности
+	idempotencyHeader = "Idempotency-Key"
+)
+
+// HTTPClient - клиент HTTP API сервиса. Токен пользователя (cookie user-token) берется из хранилища
+// учетных данных и сохраняется в него при выдаче сервисом нового токена
+type HTTPClient struct {
+	base   *url.URL
+	client *http.Client
+	store  CredentialStore
+	retry  RetryPolicy
+}
+
+// NewHTTPClient - метод создания клиента HTTP API по базовому URL сервиса
+func NewHTTPClient(baseURL string, opts Options) (*HTTPClient, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil || len(base.Host) == 0 || (base.Scheme != "http" && base.Scheme != "https") {
+		return nil, fmt.Errorf("invalid server URL %q", baseURL)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = opts.TLSConfig
+	return &HTTPClient{
+		base: base,
+		client: &http.Client{
+			Transport: transport,
+			// перенаправление на оригинальный URL не выполняется (Expand)
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		store: opts.store(),
+		retry: opts.Retry,
+	}, nil
+}
+
+// Shorten - метод сокращения ссылки (POST /api/shorten)
+func (c *HTTPClient) Shorten(ctx context.Context, longURL string) (string, error) {
+	var response struct {
+		Result string `json:"result"`
+	}
+	request := struct {
+		URL string `json:"url"`
+	}{URL: longURL}
+	status, err := c.do(ctx, http.MethodPost, "/api/shorten", request, &response, http.StatusCreated, http.StatusConflict)
+	if err != nil {
+		return "", err
+	}
+	// 409 Conflict - ссылка уже сокращена, в ответе существующая короткая ссылка
+	if status == http.StatusConflict {
+		return response.Result, &Error{Kind: ErrUniqueViolation, Message: ErrUniqueViolation.Error(), ShortURL: response.Result}
+	}
+	return response.Result, nil
+}
+
+// ShortenBatch - метод пакетного сокращения ссылок (POST /api/shorten/batch)
+func (c *HTTPClient) ShortenBatch(ctx context.Context, items []BatchItem) ([]BatchResult, error) {
+	var response []BatchResult
+	if _, err := c.do(ctx, http.MethodPost, "/api/shorten/batch", items, &response, http.StatusCreated); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// List - метод получения ссылок пользователя (GET /api/user/urls)
+func (c *HTTPClient) List(ctx context.Context) ([]URL, error) {
+	var response []URL
+	if _, err := c.do(ctx, http.MethodGet, "/api/user/urls", nil, &response, http.StatusOK, http.StatusNoContent); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Delete - метод удаления ссылок пользователя (DELETE /api/user/urls)
+func (c *HTTPClient) Delete(ctx context.Context, ids []string) error {
+	request := make([]string, 0, len(ids))
+	for _, id := range ids {
+		request = append(request, ShortID(id))
+	}
+	_, err := c.do(ctx, http.MethodDelete, "/api/user/urls", request, nil, http.StatusAccepted)
+	return err
+}
+
+// Expand - метод получения оригинального URL по короткой ссылке (GET /{id}, адрес в заголовке Location)
+func (c *HTTPClient) Expand(ctx context.Context, id string) (string, error) {
+	var location string
+	err := c.retry.do(ctx, func() error {
+		resp, err := c.send(ctx, http.MethodGet, "/"+url.PathEscape(ShortID(id)), nil, "")
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusTemporaryRedirect {
+			serviceErr := httpError(resp)
+			// сервис отвечает 400 Bad Request на неизвестную короткую ссылку
+			if resp.StatusCode == http.StatusBadRequest {
+				serviceErr.Kind = ErrNotFound
+			}
+			return serviceErr
+		}
+		location = resp.Header.Get("Location")
+		return nil
+	})
+	return location, err
+}
+
+// Stats - метод получения статистики сервиса (GET /api/internal/stats)
+func (c *HTTPClient) Stats(ctx context.Context) (Stats, error) {
+	var response Stats
+	_, err := c.do(ctx, http.MethodGet, "/api/internal/stats", nil, &response, http.StatusOK)
+	return response, err
+}
+
+// Close - метод закрытия неиспользуемых соединений
+func (c *HTTPClient) Close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+// do - метод выполнения запроса с телом request в формате JSON (с повторами) и разбором ответа в response,
+// если ответ с одним из ожидаемых статусов содержит тело. Запросы POST отправляются с ключом идемпотентности,
+// одинаковым для всех попыток. Возвращается статус ответа
+func (c *HTTPClient) do(ctx context.Context, method, path string, request, response any, statuses ...int) (int, error) {
+	var buf []byte
+	if request != nil {
+		var err error
+		if buf, err = json.Marshal(request); err != nil {
+			return 0, fmt.Errorf("can't encode request: %w", err)
+		}
+	}
+	var key string
+	if method == http.MethodPost {
+		key = uuid.New().String()
+	}
+
+	var status int
+	err := c.retry.do(ctx, func() error {
+		var body io.Reader
+		if buf != nil {
+			body = bytes.NewReader(buf)
+		}
+		resp, err := c.send(ctx, method, path, body, key)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		for _, expected := range statuses {
+			if resp.StatusCode != expected {
+				continue
+			}
+			status = expected
+			if response == nil || expected == http.StatusNoContent {
+				return nil
+			}
+			if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+				return &Error{Kind: ErrInternal, Message: fmt.Sprintf("can't decode response: %s", err)}
+			}
+			return nil
+		}
+		return httpError(resp)
+	})
+	return status, err
+}
+
+// send - метод отправки запроса с токеном пользователя, новый токен из ответа сохраняется в хранилище
+func (c *HTTPClient) send(ctx context.Context, method, path string, body io.Reader, key string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.base.JoinPath(path).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if len(key) != 0 {
+		req.Header.Set(idempotencyHeader, key)
+	}
+	creds := c.store.Credentials()
+	if len(creds.Token) != 0 {
+		req.AddCookie(&http.Cookie{Name: tokenCookie, Value: creds.Token})
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == tokenCookie && len(cookie.Value) != 0 && cookie.Value != creds.Token {
+			creds.Token = cookie.Value
+			if userID := userIDFromToken(cookie.Value); len(userID) != 0 {
+				creds.UserID = userID
+			}
+			c.store.SetCredentials(creds)
+		}
+	}
+	return resp, nil
+}
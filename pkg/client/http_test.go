@@ -0,0 +1,169 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testService - тестовый HTTP сервис: первая попытка каждого ключа идемпотентности завершается 503
+type testService struct {
+	mu       sync.Mutex
+	attempts map[string]int // количество попыток по ключу идемпотентности
+	token    string         // токен, выдаваемый сервисом
+}
+
+func (s *testService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/shorten":
+		s.mu.Lock()
+		key := r.Header.Get(idempotencyHeader)
+		s.attempts[key]++
+		attempts := s.attempts[key]
+		s.mu.Unlock()
+		if len(key) == 0 || attempts == 1 {
+			http.Error(w, "storage unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var request struct {
+			URL string `json:"url"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		http.SetCookie(w, &http.Cookie{Name: tokenCookie, Value: s.token})
+		w.Header().Set("Content-Type", "application/json")
+		if request.URL == "https://example.com/exists" {
+			w.WriteHeader(http.StatusConflict)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"result": "http://short/abc"})
+	case r.Method == http.MethodPost && r.URL.Path == "/api/shorten/batch":
+		// превышение суточной квоты: Retry-After до обнуления квоты
+		s.mu.Lock()
+		s.attempts[r.Header.Get(idempotencyHeader)]++
+		s.mu.Unlock()
+		w.Header().Set("X-Quota-Daily-Limit", "10")
+		w.Header().Set("X-Quota-Daily-Remaining", "1")
+		w.Header().Set("Retry-After", "3600")
+		http.Error(w, "daily quota exceeded: 10 links per day", http.StatusTooManyRequests)
+	case r.URL.Path == "/api/internal/stats":
+		http.Error(w, "forbidden", http.StatusForbidden)
+	case r.URL.Path == "/api/user/urls":
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+	case r.URL.Path == "/abc":
+		w.Header().Set("Location", "https://example.com/a")
+		w.WriteHeader(http.StatusTemporaryRedirect)
+	case r.URL.Path == "/deleted":
+		http.Error(w, "URL is deleted", http.StatusGone)
+	default:
+		http.Error(w, "URL not found", http.StatusBadRequest)
+	}
+}
+
+func newTestService(t *testing.T) (*testService, *HTTPClient, *MemoryStore) {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, tokenClaims{UserID: "user"}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	service := &testService{attempts: map[string]int{}, token: token}
+	server := httptest.NewServer(service)
+	t.Cleanup(server.Close)
+
+	store := NewMemoryStore(Credentials{})
+	client, err := NewHTTPClient(server.URL, Options{Store: store, Retry: RetryPolicy{MinWait: time.Millisecond, MaxWait: time.Millisecond}})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return service, client, store
+}
+
+func TestHTTPClient_Shorten(t *testing.T) {
+	service, client, store := newTestService(t)
+
+	testCases := []struct {
+		name     string
+		url      string
+		shortURL string
+		kind     error
+	}{
+		{"Retry with idempotency key #1 (good)", "https://example.com/new", "http://short/abc", nil},
+		{"Already shortened #2 (bad)", "https://example.com/exists", "http://short/abc", ErrUniqueViolation},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shortURL, err := client.Shorten(context.Background(), tc.url)
+			if tc.kind != nil {
+				require.ErrorIs(t, err, tc.kind)
+				var serviceErr *Error
+				require.ErrorAs(t, err, &serviceErr)
+				assert.Equal(t, tc.shortURL, serviceErr.ShortURL)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.shortURL, shortURL)
+		})
+	}
+	// каждый вызов выполнен за две попытки с одним ключом идемпотентности
+	assert.Len(t, service.attempts, 2)
+	for _, attempts := range service.attempts {
+		assert.Equal(t, 2, attempts)
+	}
+	assert.Equal(t, Credentials{Token: service.token, UserID: "user"}, store.Credentials())
+}
+
+func TestHTTPClient_QuotaExceeded(t *testing.T) {
+	service, client, _ := newTestService(t)
+
+	start := time.Now()
+	_, err := client.ShortenBatch(context.Background(), []BatchItem{{ID: "1", URL: "https://example.com/a"}, {ID: "2", URL: "https://example.com/b"}})
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+	var serviceErr *Error
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, time.Hour, serviceErr.RetryAfter)
+	// превышение квоты не повторяется и не ожидает обнуления квоты
+	assert.Less(t, time.Since(start), time.Second)
+	require.Len(t, service.attempts, 1)
+	for _, attempts := range service.attempts {
+		assert.Equal(t, 1, attempts)
+	}
+}
+
+func TestHTTPClient_Errors(t *testing.T) {
+	_, client, _ := newTestService(t)
+	ctx := context.Background()
+
+	testCases := []struct {
+		name     string
+		call     func() (string, error)
+		expected string
+		kind     error
+	}{
+		{"Expand #1 (good)", func() (string, error) { return client.Expand(ctx, "http://short/abc") }, "https://example.com/a", nil},
+		{"Expand deleted #2 (bad)", func() (string, error) { return client.Expand(ctx, "deleted") }, "", ErrDeletedViolation},
+		{"Expand unknown #3 (bad)", func() (string, error) { return client.Expand(ctx, "unknown") }, "", ErrNotFound},
+		{"Stats forbidden #4 (bad)", func() (string, error) { _, err := client.Stats(ctx); return "", err }, "", ErrForbidden},
+		{"List rate limited #5 (bad)", func() (string, error) {
+			ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+			defer cancel()
+			_, err := client.List(ctx)
+			return "", err
+		}, "", ErrRateLimited},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tc.call()
+			if tc.kind != nil {
+				require.ErrorIs(t, err, tc.kind)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Параметры повторов по-умолчанию
+const (
+	DefaultMaxAttempts = 3
+	DefaultMinWait     = 100 * time.Millisecond
+	DefaultMaxWait     = 2 * time.Second
+)
+
+// RetryPolicy - политика повторов идемпотентных вызовов (List, Expand, Stats, Delete, а также Shorten и ShortenBatch,
+// которые отправляются с ключом идемпотентности). Повторяются вызовы, завершившиеся ошибкой соединения,
+// недоступностью сервиса, превышением частоты запросов или конфликтом ключа идемпотентности.
+// Задержка удваивается с каждой попыткой (со случайным разбросом), но не меньше рекомендуемой сервисом;
+// если рекомендуемая задержка больше MaxWait, вызов не повторяется и возвращается ошибка
+type RetryPolicy struct {
+	MaxAttempts int           // максимальное количество попыток, 1 - без повторов
+	MinWait     time.Duration // задержка перед первым повтором
+	MaxWait     time.Duration // максимальная задержка между попытками
+}
+
+// withDefaults - метод замены нулевых параметров значениями по-умолчанию
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultMaxAttempts
+	}
+	if p.MinWait <= 0 {
+		p.MinWait = DefaultMinWait
+	}
+	if p.MaxWait < p.MinWait {
+		p.MaxWait = max(DefaultMaxWait, p.MinWait)
+	}
+	return p
+}
+
+// do - метод выполнения вызова call с повторами, пока не истечет контекст или количество попыток
+func (p RetryPolicy) do(ctx context.Context, call func() error) error {
+	p = p.withDefaults()
+	for attempt := 1; ; attempt++ {
+		err := call()
+		if err == nil || attempt >= p.MaxAttempts || ctx.Err() != nil || !retryable(err) {
+			return err
+		}
+		wait, ok := p.backoff(attempt, err)
+		if !ok {
+			return err
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff - метод расчета задержки перед повтором после попытки attempt, завершившейся ошибкой err.
+// Возвращает false, если рекомендуемая сервисом задержка больше MaxWait
+func (p RetryPolicy) backoff(attempt int, err error) (time.Duration, bool) {
+	wait := p.MaxWait
+	if shift := attempt - 1; shift < 32 && p.MinWait<<shift < p.MaxWait {
+		wait = p.MinWait << shift
+	}
+	// случайный разброс в пределах [wait/2, wait] разводит повторы одновременных клиентов
+	wait = wait/2 + rand.N(wait/2+1)
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) && serviceErr.RetryAfter > wait {
+		if serviceErr.RetryAfter > p.MaxWait {
+			return 0, false
+		}
+		wait = serviceErr.RetryAfter
+	}
+	return wait, true
+}
+
+// retryable - метод проверки возможности повтора вызова после ошибки err.
+// Ошибки сервиса повторяются только для временных видов, ошибки соединения - всегда
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		status.Code(err) == codes.Canceled || status.Code(err) == codes.DeadlineExceeded {
+		return false
+	}
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return errors.Is(err, ErrUnavailable) || errors.Is(err, ErrRateLimited) || errors.Is(err, ErrInProgress)
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MinWait: 100 * time.Millisecond, MaxWait: time.Second}.withDefaults()
+
+	testCases := []struct {
+		name    string
+		attempt int
+		err     error
+		minWait time.Duration
+		maxWait time.Duration
+		ok      bool
+	}{
+		{"First retry #1 (good)", 1, ErrUnavailable, 50 * time.Millisecond, 100 * time.Millisecond, true},
+		{"Exponential #2 (good)", 3, ErrUnavailable, 200 * time.Millisecond, 400 * time.Millisecond, true},
+		{"Max wait #3 (good)", 40, ErrUnavailable, 500 * time.Millisecond, time.Second, true},
+		{"Service retry delay #4 (good)", 1, &Error{Kind: ErrRateLimited, RetryAfter: 800 * time.Millisecond}, 800 * time.Millisecond, 800 * time.Millisecond, true},
+		{"Service retry delay over max wait #5 (bad)", 1, &Error{Kind: ErrRateLimited, RetryAfter: 3 * time.Second}, 0, 0, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wait, ok := policy.backoff(tc.attempt, tc.err)
+			assert.Equal(t, tc.ok, ok)
+			assert.GreaterOrEqual(t, wait, tc.minWait)
+			assert.LessOrEqual(t, wait, tc.maxWait)
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Connection error #1 (good)", errors.New("connection refused"), true},
+		{"Unavailable #2 (good)", &Error{Kind: ErrUnavailable}, true},
+		{"Rate limited wrapped #3 (good)", fmt.Errorf("list: %w", &Error{Kind: ErrRateLimited}), true},
+		{"Not found #4 (bad)", &Error{Kind: ErrNotFound}, false},
+		{"Canceled #5 (bad)", context.Canceled, false},
+		{"Quota exceeded #6 (bad)", &Error{Kind: ErrQuotaExceeded, RetryAfter: time.Hour}, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, retryable(tc.err))
+		})
+	}
+}